@@ -0,0 +1,220 @@
+// Package alert checks tracked game prices against watch rules
+// and notifies configured sinks when rules fire
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+)
+
+// Config is the configuration struct of alerts
+type Config struct {
+	Rules         []Rule
+	Sinks         []SinkConfig
+	RetryInterval time.Duration
+	RetryCount    int
+}
+
+// Rule watches a list of apps, or every app with price history if Apps is empty.
+// It fires when the latest price in Region, or any region if empty, matches When.
+type Rule struct {
+	Name   string
+	Apps   []int
+	Region string
+	When   string
+}
+
+// Alert is a fired rule for one game in one region
+type Alert struct {
+	Rule     string
+	Platform string
+	ID       int
+	Name     string
+	Region   string
+	Price    store.PricePoint
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("[%s] %s %d %s: %.2f %s (-%d%%) in %s", a.Rule, a.Platform, a.ID, a.Name,
+		float64(a.Price.Final)/100, a.Price.Currency, a.Price.Discount, a.Region)
+}
+
+// state identifies the price that fired, same state is only delivered once
+func (a Alert) state() string {
+	return fmt.Sprintf("%d/%d/%s", a.Price.Final, a.Price.Discount, a.Price.Currency)
+}
+
+func (a Alert) key(sink Sink) string {
+	return strings.Join([]string{a.Rule, a.Platform, strconv.Itoa(a.ID), a.Region, sink.Name()}, "|")
+}
+
+// Notifier evaluates rules and delivers alerts to sinks
+type Notifier struct {
+	config   Config
+	store    store.GameStore
+	rules    []Rule
+	exprs    []Expr
+	sinks    []Sink
+	debugLog *log.Logger
+	infoLog  *log.Logger
+}
+
+// New creates a notifier, rule expressions and sinks are validated here
+func New(cfg Config, db store.GameStore) (*Notifier, error) {
+	n := &Notifier{
+		config:   cfg,
+		store:    db,
+		rules:    cfg.Rules,
+		debugLog: log.New(os.Stdout, "Alert DEBUG:", log.LstdFlags|log.Lshortfile),
+		infoLog:  log.New(os.Stdout, "Alert INFO:", log.LstdFlags|log.Lshortfile),
+	}
+	for _, r := range cfg.Rules {
+		e, err := ParseExpr(r.When)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %v", r.Name, err)
+		}
+		n.exprs = append(n.exprs, e)
+	}
+	for _, sc := range cfg.Sinks {
+		s, err := NewSink(sc)
+		if err != nil {
+			return nil, err
+		}
+		n.sinks = append(n.sinks, s)
+	}
+	return n, nil
+}
+
+// Check evaluates all rules on a platform and delivers newly fired alerts
+func (n *Notifier) Check(ctx context.Context, platform string) error {
+	fired, err := n.evaluate(platform)
+	if err != nil {
+		return err
+	}
+	n.infoLog.Printf("%d alerts matched on %s", len(fired), platform)
+	for _, s := range n.sinks {
+		if err := n.deliver(ctx, s, fired); err != nil {
+			n.infoLog.Printf("failed to deliver alerts to %s: %v", s.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) evaluate(platform string) ([]Alert, error) {
+	var fired []Alert
+	var all []int
+	for i, r := range n.rules {
+		ids := r.Apps
+		if len(ids) == 0 {
+			if all == nil {
+				saved, err := n.store.GetSavedGameList(platform)
+				if err != nil {
+					return nil, err
+				}
+				for id := range saved {
+					all = append(all, id)
+				}
+				sort.Ints(all)
+			}
+			ids = all
+		}
+		for _, id := range ids {
+			history, err := n.store.GetPriceHistory(platform, id)
+			if err != nil {
+				return nil, err
+			}
+			if len(history) == 0 {
+				continue
+			}
+			rec, err := n.store.GetGameRecord(platform, strconv.Itoa(id))
+			if err != nil {
+				return nil, err
+			}
+			for region, points := range history {
+				if len(points) == 0 || (r.Region != "" && r.Region != region) {
+					continue
+				}
+				a := Alert{
+					Rule:     r.Name,
+					Platform: platform,
+					ID:       id,
+					Name:     rec.Name,
+					Region:   region,
+					Price:    points[len(points)-1],
+				}
+				if n.exprs[i].Match(*rec, a.Price) {
+					fired = append(fired, a)
+				} else if err := n.reset(a); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return fired, nil
+}
+
+// reset forgets delivered state of an alert that no longer matches,
+// so it will be delivered again once it matches
+func (n *Notifier) reset(a Alert) error {
+	for _, s := range n.sinks {
+		state, err := n.store.GetAlertState(a.key(s))
+		if err != nil {
+			return err
+		}
+		if state == "" {
+			continue
+		}
+		if err := n.store.SaveAlertState(a.key(s), ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver sends alerts not yet delivered to a sink, retrying according to config
+func (n *Notifier) deliver(ctx context.Context, s Sink, fired []Alert) error {
+	var pending []Alert
+	for _, a := range fired {
+		state, err := n.store.GetAlertState(a.key(s))
+		if err != nil {
+			return err
+		}
+		if state != a.state() {
+			pending = append(pending, a)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	rc := n.config.RetryCount
+	for i := 0; ; i++ {
+		err := s.Send(ctx, pending)
+		if err == nil {
+			break
+		}
+		if i >= rc {
+			return err
+		}
+		n.debugLog.Printf("send to %s err: %v count: %d", s.Name(), err, i)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(n.config.RetryInterval):
+		}
+	}
+	n.infoLog.Printf("delivered %d alerts to %s", len(pending), s.Name())
+	for _, a := range pending {
+		if err := n.store.SaveAlertState(a.key(s), a.state()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
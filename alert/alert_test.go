@@ -0,0 +1,118 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+// hookStandIn is a local webhook receiver failing the first fails requests
+type hookStandIn struct {
+	sync.Mutex
+	fails    int
+	requests int
+	payloads []map[string]json.RawMessage
+}
+
+func (h *hookStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Lock()
+	defer h.Unlock()
+	h.requests++
+	if h.fails > 0 {
+		h.fails--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var p map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	h.payloads = append(h.payloads, p)
+}
+
+func TestNotifierCheck(t *testing.T) {
+	db := storetest.New(t,
+		store.GameRecord{Name: "Counter-Strike", ID: 10},
+		store.GameRecord{Name: "Team Fortress Classic", ID: 20},
+	)
+	savePrices := func(p10, p20 store.PricePoint) {
+		if err := db.SavePricePoints("steam", "cn", map[int]store.PricePoint{10: p10, 20: p20}); err != nil {
+			t.Fatalf("SavePricePoints err: %v", err)
+		}
+	}
+	full := store.PricePoint{Currency: "CNY", Initial: 3700, Final: 3700}
+	sale := store.PricePoint{Currency: "CNY", Initial: 3700, Final: 1850, Discount: 50}
+	savePrices(sale, full)
+
+	webhook := &hookStandIn{fails: 1}
+	slack := &hookStandIn{}
+	ws := httptest.NewServer(webhook)
+	defer ws.Close()
+	ss := httptest.NewServer(slack)
+	defer ss.Close()
+
+	n, err := New(Config{
+		Rules: []Rule{
+			{Name: "half-price", Region: "cn", When: "discount >= 50"},
+			{Name: "cheap-cs", Apps: []int{10}, When: "price < 20 and currency = CNY"},
+		},
+		Sinks: []SinkConfig{
+			{Type: "webhook", URL: ws.URL},
+			{Type: "slack", URL: ss.URL},
+		},
+		RetryCount:    2,
+		RetryInterval: time.Millisecond,
+	}, db)
+	if err != nil {
+		t.Fatalf("New err: %v", err)
+	}
+
+	var tests = []struct {
+		p10, p20 store.PricePoint
+		webhook  int
+		slack    int
+	}{
+		// both rules fire for app 10, webhook fails once and is retried
+		{sale, full, 1, 1},
+		// nothing changed, already delivered alerts are not sent again
+		{sale, full, 1, 1},
+		// app 20 goes on sale
+		{sale, sale, 2, 2},
+		// sale ends for app 10, and starts again
+		{full, sale, 2, 2},
+		{sale, sale, 3, 3},
+	}
+	for caseid, c := range tests {
+		if caseid > 0 {
+			savePrices(c.p10, c.p20)
+		}
+		if err := n.Check(context.Background(), "steam"); err != nil {
+			t.Errorf("case #%d, Check err: %v", caseid+1, err)
+		}
+		if len(webhook.payloads) != c.webhook || len(slack.payloads) != c.slack {
+			t.Errorf("case #%d, got webhook: %d slack: %d deliveries, expected: %d %d",
+				caseid+1, len(webhook.payloads), len(slack.payloads), c.webhook, c.slack)
+		}
+	}
+	if webhook.requests != 4 {
+		t.Errorf("webhook got %d requests, expected 4", webhook.requests)
+	}
+	var alerts []Alert
+	if err := json.Unmarshal(webhook.payloads[0]["alerts"], &alerts); err != nil {
+		t.Errorf("webhook payload err: %v", err)
+	}
+	if len(alerts) != 2 || alerts[0].ID != 10 || alerts[1].ID != 10 {
+		t.Errorf("webhook got alerts: %v", alerts)
+	}
+	if _, ok := slack.payloads[0]["text"]; !ok {
+		t.Errorf("slack payload without text: %v", slack.payloads[0])
+	}
+}
@@ -0,0 +1,179 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ksang/gamecha/store"
+)
+
+var (
+	// ErrBadExpression indicates a rule expression that can't be parsed
+	ErrBadExpression = errors.New("bad rule expression")
+)
+
+// numeric fields are compared as numbers, prices are in major currency units
+var numericFields = map[string]func(store.GameRecord, store.PricePoint) float64{
+	"discount": func(r store.GameRecord, p store.PricePoint) float64 { return float64(p.Discount) },
+	"price":    func(r store.GameRecord, p store.PricePoint) float64 { return float64(p.Final) / 100 },
+	"initial":  func(r store.GameRecord, p store.PricePoint) float64 { return float64(p.Initial) / 100 },
+}
+
+// text fields may hold several values, a condition matches if any of them does
+var textFields = map[string]func(store.GameRecord, store.PricePoint) []string{
+	"name":      func(r store.GameRecord, p store.PricePoint) []string { return []string{r.Name} },
+	"developer": func(r store.GameRecord, p store.PricePoint) []string { return r.Developers },
+	"publisher": func(r store.GameRecord, p store.PricePoint) []string { return r.Publishers },
+	"currency":  func(r store.GameRecord, p store.PricePoint) []string { return []string{p.Currency} },
+}
+
+// condition is a single "field op value" comparison
+type condition struct {
+	field string
+	op    string
+	text  string
+	num   float64
+}
+
+// Expr is a parsed rule expression, a list of conditions joined by "and".
+// e.g. discount >= 50 and name ~ "witcher"
+type Expr []condition
+
+// ParseExpr parses a rule expression
+func ParseExpr(s string) (Expr, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	var expr Expr
+	for len(tokens) > 0 {
+		if len(tokens) < 3 {
+			return nil, fmt.Errorf("%v: incomplete condition in %q", ErrBadExpression, s)
+		}
+		c := condition{
+			field: strings.ToLower(tokens[0]),
+			op:    tokens[1],
+			text:  tokens[2],
+		}
+		if _, ok := numericFields[c.field]; ok {
+			switch c.op {
+			case "<", "<=", ">", ">=", "=", "!=":
+			default:
+				return nil, fmt.Errorf("%v: operator %s not allowed on %s", ErrBadExpression, c.op, c.field)
+			}
+			if c.num, err = strconv.ParseFloat(c.text, 64); err != nil {
+				return nil, fmt.Errorf("%v: %s is not a number", ErrBadExpression, c.text)
+			}
+		} else if _, ok := textFields[c.field]; ok {
+			switch c.op {
+			case "=", "!=", "~":
+			default:
+				return nil, fmt.Errorf("%v: operator %s not allowed on %s", ErrBadExpression, c.op, c.field)
+			}
+		} else {
+			return nil, fmt.Errorf("%v: unknown field %s", ErrBadExpression, c.field)
+		}
+		expr = append(expr, c)
+		tokens = tokens[3:]
+		if len(tokens) > 0 {
+			if strings.ToLower(tokens[0]) != "and" {
+				return nil, fmt.Errorf("%v: expected and, got %s", ErrBadExpression, tokens[0])
+			}
+			tokens = tokens[1:]
+			if len(tokens) == 0 {
+				return nil, fmt.Errorf("%v: dangling and in %q", ErrBadExpression, s)
+			}
+		}
+	}
+	return expr, nil
+}
+
+// Match reports if a game record and its price satisfy all conditions
+func (e Expr) Match(r store.GameRecord, p store.PricePoint) bool {
+	for _, c := range e {
+		if f, ok := numericFields[c.field]; ok {
+			if !compare(f(r, p), c.op, c.num) {
+				return false
+			}
+			continue
+		}
+		if !matchText(textFields[c.field](r, p), c.op, c.text) {
+			return false
+		}
+	}
+	return true
+}
+
+func compare(v float64, op string, n float64) bool {
+	switch op {
+	case "<":
+		return v < n
+	case "<=":
+		return v <= n
+	case ">":
+		return v > n
+	case ">=":
+		return v >= n
+	case "=":
+		return v == n
+	case "!=":
+		return v != n
+	}
+	return false
+}
+
+func matchText(values []string, op string, s string) bool {
+	s = strings.ToLower(s)
+	found := false
+	for _, v := range values {
+		v = strings.ToLower(v)
+		if (op == "~" && strings.Contains(v, s)) || (op != "~" && v == s) {
+			found = true
+			break
+		}
+	}
+	if op == "!=" {
+		return !found
+	}
+	return found
+}
+
+// tokenize splits expression into words, operators and quoted strings
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		switch r := rs[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			j := i + 1
+			for j < len(rs) && rs[j] != r {
+				j++
+			}
+			if j == len(rs) {
+				return nil, fmt.Errorf("%v: unterminated string in %q", ErrBadExpression, s)
+			}
+			tokens = append(tokens, string(rs[i+1:j]))
+			i = j + 1
+		case strings.ContainsRune("<>=!~", r):
+			j := i + 1
+			if j < len(rs) && rs[j] == '=' {
+				j++
+			}
+			tokens = append(tokens, string(rs[i:j]))
+			i = j
+		default:
+			j := i
+			for j < len(rs) && !unicode.IsSpace(rs[j]) && !strings.ContainsRune("<>=!~\"'", rs[j]) {
+				j++
+			}
+			tokens = append(tokens, string(rs[i:j]))
+			i = j
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,51 @@
+package alert
+
+import (
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+)
+
+func TestParseExprMatch(t *testing.T) {
+	rec := store.GameRecord{
+		Name:       "The Witcher 3: Wild Hunt",
+		Developers: []string{"CD PROJEKT RED"},
+		Publishers: []string{"CD PROJEKT RED"},
+	}
+	price := store.PricePoint{Currency: "CNY", Initial: 12700, Final: 3810, Discount: 70}
+	var tests = []struct {
+		s     string
+		err   bool
+		match bool
+	}{
+		{"discount >= 50", false, true},
+		{"discount>70", false, false},
+		{"price < 50 and currency = cny", false, true},
+		{"price < 50 and currency = USD", false, false},
+		{"initial = 127", false, true},
+		{`name ~ "wild hunt" and developer = 'CD PROJEKT RED'`, false, true},
+		{"publisher != Valve", false, true},
+		{"name ~ portal", false, false},
+		{"", false, true},
+		{"discount ~ 50", true, false},
+		{"price < cheap", true, false},
+		{"rating > 3", true, false},
+		{"discount >= 50 and", true, false},
+		{"discount >= 50 or price < 1", true, false},
+		{`name ~ "witcher`, true, false},
+	}
+
+	for caseid, c := range tests {
+		e, err := ParseExpr(c.s)
+		if (err != nil) != c.err {
+			t.Errorf("case #%d, %q err: %v", caseid+1, c.s, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if m := e.Match(rec, price); m != c.match {
+			t.Errorf("case #%d, %q got: %v, expected: %v", caseid+1, c.s, m, c.match)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrUnknownSink indicates a sink type that is not supported
+	ErrUnknownSink = errors.New("unknown alert sink")
+)
+
+// SinkConfig is the configuration of one alert delivery sink.
+// Type is one of webhook, slack or file. File sinks write to Path,
+// an empty path or "-" means stdout.
+type SinkConfig struct {
+	Type string
+	URL  string
+	Path string
+}
+
+// Sink delivers fired alerts somewhere
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, alerts []Alert) error
+}
+
+// NewSink creates a sink according to configuration
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case "webhook":
+		return &webhookSink{url: cfg.URL}, nil
+	case "slack":
+		return &slackSink{webhookSink{url: cfg.URL}}, nil
+	case "file":
+		return &fileSink{path: cfg.Path}, nil
+	}
+	return nil, fmt.Errorf("%v: %s", ErrUnknownSink, cfg.Type)
+}
+
+// webhookSink posts alerts as json to a generic http endpoint
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (ws *webhookSink) Name() string {
+	return "webhook:" + ws.url
+}
+
+func (ws *webhookSink) Send(ctx context.Context, alerts []Alert) error {
+	return ws.post(ctx, map[string]interface{}{"alerts": alerts})
+}
+
+func (ws *webhookSink) post(ctx context.Context, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", ws.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := ws.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s responded %s", ws.url, resp.Status)
+	}
+	return nil
+}
+
+// slackSink posts alerts as a slack compatible incoming webhook message
+type slackSink struct {
+	webhookSink
+}
+
+func (ss *slackSink) Name() string {
+	return "slack:" + ss.url
+}
+
+func (ss *slackSink) Send(ctx context.Context, alerts []Alert) error {
+	lines := make([]string, len(alerts))
+	for i, a := range alerts {
+		lines[i] = a.String()
+	}
+	return ss.post(ctx, map[string]string{"text": strings.Join(lines, "\n")})
+}
+
+// fileSink appends alerts to a local file, one line each
+type fileSink struct {
+	path string
+}
+
+func (fs *fileSink) Name() string {
+	if fs.path == "" || fs.path == "-" {
+		return "file:stdout"
+	}
+	return "file:" + fs.path
+}
+
+func (fs *fileSink) Send(ctx context.Context, alerts []Alert) error {
+	var w io.Writer = os.Stdout
+	if fs.path != "" && fs.path != "-" {
+		f, err := os.OpenFile(fs.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	for _, a := range alerts {
+		if _, err := fmt.Fprintln(w, a.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+// Package cluster groups similar games by k-means over their feature vectors
+// and labels groups by terms telling them apart, to explore sub-genres
+package cluster
+
+import (
+	"errors"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ksang/gamecha/recommend"
+	"github.com/ksang/gamecha/store"
+)
+
+var (
+	// ErrTooFewRecords indicates there are fewer records than clusters asked
+	ErrTooFewRecords = errors.New("fewer records than clusters")
+	// ErrIterations indicates k-means is given no iteration to assign records
+	ErrIterations = errors.New("k-means needs at least one iteration")
+)
+
+const (
+	// labelTerms is the terms kept of a cluster
+	labelTerms = 8
+	// labelWords is the terms of a cluster joined as its label
+	labelWords = 3
+)
+
+// Config is the configuration struct of clustering
+type Config struct {
+	// Platform to cluster records of, e.g. steam
+	Platform string
+	// Types of apps to cluster, e.g. game, all types if empty
+	Types []string
+	// K is the number of clusters
+	K int
+	// Iterations caps k-means iterations
+	Iterations int
+	// Seed of initial centroids, same seed and records give same clusters
+	Seed int64
+}
+
+// Clusterer clusters records of a platform
+type Clusterer struct {
+	config   Config
+	store    store.GameStore
+	debugLog *log.Logger
+	infoLog  *log.Logger
+}
+
+// New creates a clusterer
+func New(cfg Config, db store.GameStore) *Clusterer {
+	return &Clusterer{
+		config:   cfg,
+		store:    db,
+		debugLog: log.New(os.Stdout, "Cluster DEBUG:", log.LstdFlags|log.Lshortfile),
+		infoLog:  log.New(os.Stdout, "Cluster INFO:", log.LstdFlags|log.Lshortfile),
+	}
+}
+
+// sparse is a feature vector with terms numbered
+type sparse struct {
+	terms   []int
+	weights []float64
+}
+
+func (v sparse) dot(dense []float64) float64 {
+	ret := 0.0
+	for i, t := range v.terms {
+		ret += v.weights[i] * dense[t]
+	}
+	return ret
+}
+
+// Run clusters records by spherical k-means over their feature vectors,
+// saves the clusters and sets them on records. Clusters are numbered from 1
+// by size descending.
+func (c *Clusterer) Run() ([]store.Cluster, error) {
+	vectors, err := recommend.Vectors(c.store, c.config.Platform, func(r store.GameRecord) bool {
+		if len(c.config.Types) == 0 {
+			return true
+		}
+		for _, t := range c.config.Types {
+			if strings.EqualFold(t, r.Type) {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) < c.config.K || c.config.K < 1 {
+		return nil, ErrTooFewRecords
+	}
+	if c.config.Iterations < 1 {
+		return nil, ErrIterations
+	}
+	ids, terms, vs := numbered(vectors)
+	assign := c.kmeans(vs, len(terms))
+	clusters, byOld := label(assign, vs, terms, c.config.K)
+	assignments := make(map[int]int, len(ids))
+	for i, id := range ids {
+		assignments[id] = byOld[assign[i]]
+	}
+	c.infoLog.Printf("clustered %d %s records into %d clusters", len(ids), c.config.Platform, len(clusters))
+	return clusters, c.store.SaveClusters(c.config.Platform, clusters, assignments)
+}
+
+// numbered orders records by id and numbers terms by name, so runs are repeatable
+func numbered(vectors map[int]map[string]float64) ([]int, []string, []sparse) {
+	ids := make([]int, 0, len(vectors))
+	names := make(map[string]bool)
+	for id, v := range vectors {
+		ids = append(ids, id)
+		for t := range v {
+			names[t] = true
+		}
+	}
+	sort.Ints(ids)
+	terms := make([]string, 0, len(names))
+	for t := range names {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+	index := make(map[string]int, len(terms))
+	for i, t := range terms {
+		index[t] = i
+	}
+	vs := make([]sparse, len(ids))
+	for i, id := range ids {
+		for _, t := range sortedTerms(vectors[id]) {
+			vs[i].terms = append(vs[i].terms, index[t])
+			vs[i].weights = append(vs[i].weights, vectors[id][t])
+		}
+	}
+	return ids, terms, vs
+}
+
+func sortedTerms(v map[string]float64) []string {
+	ret := make([]string, 0, len(v))
+	for t := range v {
+		ret = append(ret, t)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// kmeans assigns unit vectors to K clusters by cosine similarity to centroids,
+// seeded by k-means++. A cluster left empty keeps its centroid.
+func (c *Clusterer) kmeans(vs []sparse, dim int) []int {
+	rnd := rand.New(rand.NewSource(c.config.Seed))
+	centroids := make([][]float64, 0, c.config.K)
+	addCentroid := func(v sparse) {
+		dense := make([]float64, dim)
+		for i, t := range v.terms {
+			dense[t] = v.weights[i]
+		}
+		centroids = append(centroids, dense)
+	}
+	addCentroid(vs[rnd.Intn(len(vs))])
+	// distance of vectors to their nearest centroid, 1 - cosine similarity
+	dist := make([]float64, len(vs))
+	for i := range dist {
+		dist[i] = math.Inf(1)
+	}
+	for len(centroids) < c.config.K {
+		total := 0.0
+		for i, v := range vs {
+			if d := 1 - v.dot(centroids[len(centroids)-1]); d < dist[i] {
+				dist[i] = math.Max(d, 0)
+			}
+			total += dist[i] * dist[i]
+		}
+		next := 0
+		if total > 0 {
+			x := rnd.Float64() * total
+			for i := range vs {
+				if x -= dist[i] * dist[i]; x <= 0 {
+					next = i
+					break
+				}
+			}
+		} else {
+			next = rnd.Intn(len(vs))
+		}
+		addCentroid(vs[next])
+	}
+	assign := make([]int, len(vs))
+	for i := range assign {
+		assign[i] = -1
+	}
+	for it := 0; it < c.config.Iterations; it++ {
+		changed := 0
+		for i, v := range vs {
+			best, bestSim := 0, math.Inf(-1)
+			for k, cen := range centroids {
+				if s := v.dot(cen); s > bestSim {
+					best, bestSim = k, s
+				}
+			}
+			if assign[i] != best {
+				assign[i] = best
+				changed++
+			}
+		}
+		c.debugLog.Printf("iteration %d, %d records changed cluster", it+1, changed)
+		if changed == 0 {
+			break
+		}
+		sums := make([][]float64, len(centroids))
+		for i, v := range vs {
+			k := assign[i]
+			if sums[k] == nil {
+				sums[k] = make([]float64, dim)
+			}
+			for j, t := range v.terms {
+				sums[k][t] += v.weights[j]
+			}
+		}
+		for k, sum := range sums {
+			if sum == nil {
+				continue
+			}
+			norm := 0.0
+			for _, w := range sum {
+				norm += w * w
+			}
+			for t := range sum {
+				sum[t] /= math.Sqrt(norm)
+			}
+			centroids[k] = sum
+		}
+	}
+	return assign
+}
+
+// label names clusters by terms weighing more in the cluster than in all
+// records, and numbers non empty clusters by size. The numbers of clusters
+// are returned keyed by their k-means index.
+func label(assign []int, vs []sparse, terms []string, k int) ([]store.Cluster, map[int]int) {
+	global := make([]float64, len(terms))
+	means := make([][]float64, k)
+	sizes := make([]int, k)
+	for i, v := range vs {
+		c := assign[i]
+		if means[c] == nil {
+			means[c] = make([]float64, len(terms))
+		}
+		sizes[c]++
+		for j, t := range v.terms {
+			global[t] += v.weights[j] / float64(len(vs))
+			means[c][t] += v.weights[j]
+		}
+	}
+	var ret []store.Cluster
+	var order []int
+	for c := range means {
+		if sizes[c] == 0 {
+			continue
+		}
+		score := make(map[int]float64)
+		for t, w := range means[c] {
+			if d := w/float64(sizes[c]) - global[t]; d > 0 {
+				score[t] = d
+			}
+		}
+		top := make([]int, 0, len(score))
+		for t := range score {
+			top = append(top, t)
+		}
+		sort.Slice(top, func(i, j int) bool {
+			if score[top[i]] != score[top[j]] {
+				return score[top[i]] > score[top[j]]
+			}
+			return top[i] < top[j]
+		})
+		if len(top) > labelTerms {
+			top = top[:labelTerms]
+		}
+		cl := store.Cluster{Size: sizes[c]}
+		var words []string
+		for _, t := range top {
+			cl.Terms = append(cl.Terms, terms[t])
+			if len(words) < labelWords {
+				words = append(words, terms[t][strings.Index(terms[t], ":")+1:])
+			}
+		}
+		cl.Label = strings.Join(words, ", ")
+		ret = append(ret, cl)
+		order = append(order, c)
+	}
+	idx := make([]int, len(ret))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return ret[idx[i]].Size > ret[idx[j]].Size })
+	sorted := make([]store.Cluster, len(ret))
+	byOld := make(map[int]int, len(ret))
+	for n, i := range idx {
+		sorted[n] = ret[i]
+		sorted[n].ID = n + 1
+		byOld[order[i]] = n + 1
+	}
+	return sorted, byOld
+}
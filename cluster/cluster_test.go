@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestRun(t *testing.T) {
+	racing := []string{"Racing"}
+	farming := []string{"Simulation"}
+	records := []store.GameRecord{
+		{ID: 1, Type: "game", Genres: racing, DescriptionText: "Drift your car around the track and race rivals at top speed."},
+		{ID: 2, Type: "game", Genres: racing, DescriptionText: "Race street cars, tune every car and win the track championship."},
+		{ID: 3, Type: "game", Genres: racing, DescriptionText: "Rally car racing on gravel, drift through every stage at speed."},
+		{ID: 4, Type: "game", Genres: racing, DescriptionText: "Kart race with friends, drift to boost your speed on the track."},
+		{ID: 5, Type: "game", Genres: farming, DescriptionText: "Grow crops, raise animals and harvest your farm every season."},
+		{ID: 6, Type: "game", Genres: farming, DescriptionText: "Inherit an old farm, plant crops and befriend the village animals."},
+		{ID: 7, Type: "game", Genres: farming, DescriptionText: "Run a modern farm with tractors, harvest crops and sell them."},
+		{ID: 8, Type: "game", Genres: farming, DescriptionText: "Tend animals on a mountain farm, harvest season after season."},
+		{ID: 9, Type: "dlc", Genres: racing, DescriptionText: "New car pack for the race track, drift at speed."},
+	}
+	db := storetest.New(t, records...)
+	if _, err := New(Config{Platform: "steam", Types: []string{"game"}, K: 9, Iterations: 10}, db).Run(); err != ErrTooFewRecords {
+		t.Errorf("got: %v, expected: %v", err, ErrTooFewRecords)
+	}
+	if _, err := New(Config{Platform: "steam", Types: []string{"game"}, K: 2}, db).Run(); err != ErrIterations {
+		t.Errorf("got: %v, expected: %v", err, ErrIterations)
+	}
+	clusters, err := New(Config{Platform: "steam", Types: []string{"game"}, K: 2, Iterations: 20, Seed: 1}, db).Run()
+	if err != nil {
+		t.Fatalf("Run err: %v", err)
+	}
+	if len(clusters) != 2 || clusters[0].ID != 1 || clusters[1].ID != 2 ||
+		clusters[0].Size != 4 || clusters[1].Size != 4 {
+		t.Fatalf("got clusters: %+v, expected 2 clusters of 4", clusters)
+	}
+	labels := make(map[string]int)
+	for _, c := range clusters {
+		for _, term := range c.Terms {
+			labels[term] = c.ID
+		}
+	}
+	if labels["genre:racing"] == 0 || labels["word:farm"] == 0 || labels["genre:racing"] == labels["word:farm"] {
+		t.Errorf("got clusters: %+v, expected racing and farm told apart", clusters)
+	}
+	saved, err := db.GetClusters("steam")
+	if err != nil || len(saved) != 2 {
+		t.Errorf("got saved clusters: %v %v", saved, err)
+	}
+	var tests = []struct {
+		id      int
+		cluster int
+	}{
+		{1, labels["genre:racing"]},
+		{2, labels["genre:racing"]},
+		{3, labels["genre:racing"]},
+		{4, labels["genre:racing"]},
+		{5, labels["word:farm"]},
+		{6, labels["word:farm"]},
+		{7, labels["word:farm"]},
+		{8, labels["word:farm"]},
+		{9, 0},
+	}
+	for caseid, c := range tests {
+		r, err := db.GetGameRecord("steam", strconv.Itoa(c.id))
+		if err != nil || r.Cluster != c.cluster {
+			t.Errorf("case #%d, got: %d %v, expected: %d", caseid+1, r.Cluster, err, c.cluster)
+		}
+	}
+}
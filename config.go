@@ -1,11 +1,15 @@
 package main
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ksang/gamecha/alert"
 	"github.com/ksang/gamecha/seeker"
 	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/studio"
 	"github.com/olebedev/config"
 )
 
@@ -13,8 +17,19 @@ var (
 	defaultWorkerNum     = 10
 	defaultRetryInterval = "30s"
 	defaultRetryCount    = 5
-	defaultStoreType     = "bolt"
-	defaultStorePath     = "gamecha.db"
+	defaultReviewCap     = 1000
+	defaultNewsCap       = 100
+	// stored records are fetched again monthly
+	defaultRecheckInterval = "720h"
+	// records without a settled release date are fetched again daily
+	defaultReleaseRecheckInterval = "24h"
+	// concurrent player sampling and rollup, zero max retention keeps daily samples forever
+	defaultPlayerInterval        = "10m"
+	defaultPlayerRawRetention    = "48h"
+	defaultPlayerHourlyRetention = "720h"
+	defaultPlayerMaxRetention    = "0s"
+	defaultStoreType             = "bolt"
+	defaultStorePath             = "gamecha.db"
 )
 
 // ParseSeekerConfig parse seeker configurations from string to struct
@@ -48,14 +63,73 @@ func ParseSeekerConfig(confStr string) (*seeker.Config, error) {
 	if !ok {
 		rc = defaultRetryCount
 	}
+	var countries, localizations, types []string
+	if cc, ok := steamConf["countries"]; ok {
+		countries = splitList(cc)
+	}
+	if l, ok := steamConf["localizations"]; ok {
+		localizations = splitList(l)
+	}
+	if t, ok := steamConf["types"]; ok {
+		types = splitList(t)
+	}
+	var tracked []int
+	if t, ok := steamConf["tracked"]; ok {
+		if tracked, err = splitIntList(t); err != nil {
+			return nil, err
+		}
+	}
+	reviewCap, ok := steamConf["review_cap"]
+	if !ok {
+		reviewCap = defaultReviewCap
+	}
+	newsCap, ok := steamConf["news_cap"]
+	if !ok {
+		newsCap = defaultNewsCap
+	}
+	pi, err := durationValue(steamConf, "player_interval", defaultPlayerInterval)
+	if err != nil {
+		return nil, err
+	}
+	if pi <= 0 {
+		return nil, fmt.Errorf("bad player interval: %v", pi)
+	}
+	recheck, err := durationValue(steamConf, "recheck_interval", defaultRecheckInterval)
+	if err != nil {
+		return nil, err
+	}
+	releaseRecheck, err := durationValue(steamConf, "release_recheck_interval", defaultReleaseRecheckInterval)
+	if err != nil {
+		return nil, err
+	}
+	var retention seeker.RetentionPolicy
+	if retention.Raw, err = durationValue(steamConf, "player_raw_retention", defaultPlayerRawRetention); err != nil {
+		return nil, err
+	}
+	if retention.Hourly, err = durationValue(steamConf, "player_hourly_retention", defaultPlayerHourlyRetention); err != nil {
+		return nil, err
+	}
+	if retention.Max, err = durationValue(steamConf, "player_max_retention", defaultPlayerMaxRetention); err != nil {
+		return nil, err
+	}
 
 	return &seeker.Config{
 		SteamConfig: seeker.SteamConfig{
-			Portal:        steamConf["portal"].(string),
-			Key:           steamConf["key"].(string),
-			WorkerNum:     wn.(int),
-			RetryInterval: ri,
-			RetryCount:    rc.(int),
+			Portal:                 steamConf["portal"].(string),
+			Key:                    steamConf["key"].(string),
+			WorkerNum:              wn.(int),
+			RetryInterval:          ri,
+			RetryCount:             rc.(int),
+			Countries:              countries,
+			Localizations:          localizations,
+			Types:                  types,
+			Tracked:                tracked,
+			RecheckInterval:        recheck,
+			ReleaseRecheckInterval: releaseRecheck,
+			ReviewCap:              reviewCap.(int),
+			NewsCap:                newsCap.(int),
+			PlayerInterval:         pi,
+			PlayerRetention:        retention,
 		},
 	}, nil
 }
@@ -91,3 +165,130 @@ func ParseStoreConfig(confStr string) (*store.Config, error) {
 		Buckets:   b,
 	}, nil
 }
+
+// ParseAlertConfig parse alert configurations from string to struct,
+// alert section is optional and missing one means no rules
+func ParseAlertConfig(confStr string) (*alert.Config, error) {
+	cfg, err := config.ParseYaml(confStr)
+	if err != nil {
+		return nil, err
+	}
+	alertConf, err := cfg.Map("alert")
+	if err != nil {
+		return &alert.Config{}, nil
+	}
+	ris, ok := alertConf["retry_interval"]
+	if !ok {
+		ris = defaultRetryInterval
+	}
+	ri, err := time.ParseDuration(ris.(string))
+	if err != nil {
+		return nil, err
+	}
+	rc, ok := alertConf["retry_count"]
+	if !ok {
+		rc = defaultRetryCount
+	}
+	ret := &alert.Config{
+		RetryInterval: ri,
+		RetryCount:    rc.(int),
+	}
+	rules, _ := alertConf["rules"].([]interface{})
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("bad alert rule: %v", r)
+		}
+		ar := alert.Rule{
+			Name:   stringValue(rule["name"]),
+			Region: stringValue(rule["region"]),
+			When:   stringValue(rule["when"]),
+		}
+		if apps, ok := rule["apps"]; ok {
+			if ar.Apps, err = splitIntList(apps); err != nil {
+				return nil, err
+			}
+		}
+		ret.Rules = append(ret.Rules, ar)
+	}
+	sinks, _ := alertConf["sinks"].([]interface{})
+	for _, s := range sinks {
+		sink, ok := s.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("bad alert sink: %v", s)
+		}
+		ret.Sinks = append(ret.Sinks, alert.SinkConfig{
+			Type: stringValue(sink["type"]),
+			URL:  stringValue(sink["url"]),
+			Path: stringValue(sink["path"]),
+		})
+	}
+	return ret, nil
+}
+
+// ParseStudioConfig parse studio configurations from string to struct,
+// studios section is optional and missing one means no aliases
+func ParseStudioConfig(confStr string) (*studio.Config, error) {
+	cfg, err := config.ParseYaml(confStr)
+	if err != nil {
+		return nil, err
+	}
+	studioConf, err := cfg.Map("studios")
+	if err != nil {
+		return &studio.Config{}, nil
+	}
+	ret := &studio.Config{}
+	aliases, _ := studioConf["aliases"].([]interface{})
+	for _, a := range aliases {
+		alias, ok := a.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("bad studio alias: %v", a)
+		}
+		sa := studio.Alias{Name: stringValue(alias["name"])}
+		for _, aka := range strings.Split(stringValue(alias["aka"]), ",") {
+			if aka = strings.TrimSpace(aka); aka != "" {
+				sa.AKA = append(sa.AKA, aka)
+			}
+		}
+		if sa.Name == "" || len(sa.AKA) == 0 {
+			return nil, fmt.Errorf("bad studio alias: %v", a)
+		}
+		ret.Aliases = append(ret.Aliases, sa)
+	}
+	return ret, nil
+}
+
+// stringValue of an optional config value
+func stringValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// durationValue of an optional config duration, def is used if missing
+func durationValue(m map[string]interface{}, key string, def string) (time.Duration, error) {
+	v, ok := m[key]
+	if !ok {
+		v = def
+	}
+	return time.ParseDuration(stringValue(v))
+}
+
+// splitList splits a space separated config value into a list
+func splitList(v interface{}) []string {
+	return strings.Fields(fmt.Sprint(v))
+}
+
+// splitIntList splits a space separated config value into a list of ints
+func splitIntList(v interface{}) ([]int, error) {
+	var ret []int
+	for _, s := range splitList(v) {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, i)
+	}
+	return ret, nil
+}
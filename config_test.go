@@ -5,8 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ksang/gamecha/alert"
 	"github.com/ksang/gamecha/seeker"
 	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/studio"
 )
 
 func TestParseSeekerConfig(t *testing.T) {
@@ -26,10 +28,16 @@ func TestParseSeekerConfig(t *testing.T) {
 `,
 			seeker.Config{
 				SteamConfig: seeker.SteamConfig{
-					Portal:     "http://api.steampowered.com/",
-					Key:        "16A02FCADCE5D2C8A90CBD9F8A16E63C",
-					WorkerNum:  10,
-					RetryCount: 5,
+					Portal:                 "http://api.steampowered.com/",
+					Key:                    "16A02FCADCE5D2C8A90CBD9F8A16E63C",
+					WorkerNum:              10,
+					RetryCount:             5,
+					ReviewCap:              1000,
+					NewsCap:                100,
+					RecheckInterval:        720 * time.Hour,
+					ReleaseRecheckInterval: 24 * time.Hour,
+					PlayerInterval:         10 * time.Minute,
+					PlayerRetention:        seeker.RetentionPolicy{Raw: 48 * time.Hour, Hourly: 720 * time.Hour},
 				},
 			},
 		},
@@ -46,10 +54,56 @@ func TestParseSeekerConfig(t *testing.T) {
                 type: bolt`,
 			seeker.Config{
 				SteamConfig: seeker.SteamConfig{
-					Portal:        "http://api.steampowered.com/",
-					Key:           "16A02FCADCE5D2C8A90CBD9F8A16E63C",
-					WorkerNum:     10,
-					RetryInterval: time.Duration(30000000000),
+					Portal:                 "http://api.steampowered.com/",
+					Key:                    "16A02FCADCE5D2C8A90CBD9F8A16E63C",
+					WorkerNum:              10,
+					RetryInterval:          time.Duration(30000000000),
+					ReviewCap:              1000,
+					NewsCap:                100,
+					RecheckInterval:        720 * time.Hour,
+					ReleaseRecheckInterval: 24 * time.Hour,
+					PlayerInterval:         10 * time.Minute,
+					PlayerRetention:        seeker.RetentionPolicy{Raw: 48 * time.Hour, Hourly: 720 * time.Hour},
+				},
+			},
+		},
+		{
+			`
+            seeker:
+                steam:
+                    portal:  http://api.steampowered.com/
+                    key: 16A02FCADCE5D2C8A90CBD9F8A16E63C
+                    countries: us cn
+                    localizations: schinese japanese
+                    types: game dlc
+                    tracked: 10 570
+                    recheck_interval: 24h
+                    release_recheck_interval: 12h
+                    review_cap: 200
+                    news_cap: 50
+                    player_interval: 5m
+                    player_raw_retention: 24h
+                    player_hourly_retention: 168h
+                    player_max_retention: 8760h
+            store:
+                type: bolt`,
+			seeker.Config{
+				SteamConfig: seeker.SteamConfig{
+					Portal:                 "http://api.steampowered.com/",
+					Key:                    "16A02FCADCE5D2C8A90CBD9F8A16E63C",
+					WorkerNum:              10,
+					RetryInterval:          time.Duration(30000000000),
+					RetryCount:             5,
+					Countries:              []string{"us", "cn"},
+					Localizations:          []string{"schinese", "japanese"},
+					Types:                  []string{"game", "dlc"},
+					Tracked:                []int{10, 570},
+					RecheckInterval:        24 * time.Hour,
+					ReleaseRecheckInterval: 12 * time.Hour,
+					ReviewCap:              200,
+					NewsCap:                50,
+					PlayerInterval:         5 * time.Minute,
+					PlayerRetention:        seeker.RetentionPolicy{Raw: 24 * time.Hour, Hourly: 168 * time.Hour, Max: 8760 * time.Hour},
 				},
 			},
 		},
@@ -67,6 +121,20 @@ func TestParseSeekerConfig(t *testing.T) {
 	}
 }
 
+func TestParseSeekerConfigPlayerInterval(t *testing.T) {
+	for caseid, pi := range []string{"0s", "-5m"} {
+		s := `
+            seeker:
+                steam:
+                    portal:  http://api.steampowered.com/
+                    key: 16A02FCADCE5D2C8A90CBD9F8A16E63C
+                    player_interval: ` + pi
+		if res, err := ParseSeekerConfig(s); err == nil {
+			t.Errorf("case #%d, got: %v, expected error", caseid+1, res)
+		}
+	}
+}
+
 func TestParseStoreConfig(t *testing.T) {
 	var tests = []struct {
 		s string
@@ -104,3 +172,101 @@ func TestParseStoreConfig(t *testing.T) {
 		t.Logf("Result: %v", res)
 	}
 }
+
+func TestParseAlertConfig(t *testing.T) {
+	var tests = []struct {
+		s string
+		d alert.Config
+	}{
+		{
+			`
+            seeker:
+                steam:
+                    portal:  http://api.steampowered.com/
+            store:
+                type: bolt`,
+			alert.Config{},
+		},
+		{
+			`
+            alert:
+                retry_interval: 10s
+                retry_count: 2
+                rules:
+                    - name: half-price
+                      apps: 10 570
+                      region: cn
+                      when: discount >= 50
+                    - name: cheap
+                      apps: 10
+                      when: price < 20 and currency = CNY
+                sinks:
+                    - type: webhook
+                      url: http://localhost:8080/hook
+                    - type: file
+                      path: "-"`,
+			alert.Config{
+				Rules: []alert.Rule{
+					{Name: "half-price", Apps: []int{10, 570}, Region: "cn", When: "discount >= 50"},
+					{Name: "cheap", Apps: []int{10}, When: "price < 20 and currency = CNY"},
+				},
+				Sinks: []alert.SinkConfig{
+					{Type: "webhook", URL: "http://localhost:8080/hook"},
+					{Type: "file", Path: "-"},
+				},
+				RetryInterval: 10 * time.Second,
+				RetryCount:    2,
+			},
+		},
+	}
+
+	for caseid, c := range tests {
+		res, err := ParseAlertConfig(c.s)
+		if err != nil {
+			t.Errorf("case #%d, err: %v", caseid+1, err)
+		}
+		if !reflect.DeepEqual(res, &c.d) {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, res, &c.d)
+		}
+		t.Logf("Result: %v", res)
+	}
+}
+
+func TestParseStudioConfig(t *testing.T) {
+	var tests = []struct {
+		s string
+		d studio.Config
+	}{
+		{
+			`
+            store:
+                type: bolt`,
+			studio.Config{},
+		},
+		{
+			`
+            studios:
+                aliases:
+                    - name: Bandai Namco Entertainment
+                      aka: NAMCO BANDAI Games, Namco Bandai Partners
+                    - name: Square Enix
+                      aka: SQUARE ENIX CO.`,
+			studio.Config{
+				Aliases: []studio.Alias{
+					{Name: "Bandai Namco Entertainment", AKA: []string{"NAMCO BANDAI Games", "Namco Bandai Partners"}},
+					{Name: "Square Enix", AKA: []string{"SQUARE ENIX CO."}},
+				},
+			},
+		},
+	}
+
+	for caseid, c := range tests {
+		res, err := ParseStudioConfig(c.s)
+		if err != nil {
+			t.Errorf("case #%d, err: %v", caseid+1, err)
+		}
+		if !reflect.DeepEqual(res, &c.d) {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, res, &c.d)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// Package dupes detects duplicate and near-duplicate records within a platform,
+// such as reissues of a game and its test apps or soundtrack companions
+package dupes
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ksang/gamecha/match"
+	"github.com/ksang/gamecha/sanitize"
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/studio"
+	"github.com/ksang/gamecha/title"
+)
+
+// Kinds of duplicate pairs and groups
+const (
+	// KindDuplicate is the same game listed more than once
+	KindDuplicate = "duplicate"
+	// KindReissue is another edition of a game, e.g. GOTY or remastered
+	KindReissue = "reissue"
+	// KindTest is a test, playtest or beta app of a game
+	KindTest = "test"
+	// KindSoundtrack is a soundtrack of a game
+	KindSoundtrack = "soundtrack"
+)
+
+// kindOrder ranks kinds of a group, the first found in its pairs names it
+var kindOrder = []string{KindDuplicate, KindReissue, KindTest, KindSoundtrack}
+
+// Config is the configuration struct of duplicate detection
+type Config struct {
+	// Platform to detect duplicate records of, e.g. steam
+	Platform string
+	// MinScore is the similarity a pair of records needs to be grouped
+	MinScore float64
+}
+
+// Member is a record in a duplicate group
+type Member struct {
+	ID         int      `json:"id"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type,omitempty"`
+	Developers []string `json:"developers,omitempty"`
+}
+
+// Pair is a scored pair of records in a duplicate group with its signals,
+// a signal of -1 is unknown, e.g. a description too short to compare
+type Pair struct {
+	A           int     `json:"a"`
+	B           int     `json:"b"`
+	Kind        string  `json:"kind"`
+	Score       float64 `json:"score"`
+	Name        float64 `json:"name"`
+	Description float64 `json:"description"`
+	Developers  float64 `json:"developers"`
+}
+
+// Group is records detected as duplicates of each other
+type Group struct {
+	Kind    string   `json:"kind"`
+	Records []Member `json:"records"`
+	Pairs   []Pair   `json:"pairs"`
+}
+
+// companions are trailing words naming apps that accompany a game,
+// longer phrases come first
+var companions = []struct {
+	words []string
+	kind  string
+}{
+	{[]string{"original", "soundtrack"}, KindSoundtrack},
+	{[]string{"official", "soundtrack"}, KindSoundtrack},
+	{[]string{"original", "score"}, KindSoundtrack},
+	{[]string{"sound", "track"}, KindSoundtrack},
+	{[]string{"soundtrack"}, KindSoundtrack},
+	{[]string{"ost"}, KindSoundtrack},
+	{[]string{"public", "test"}, KindTest},
+	{[]string{"test", "server"}, KindTest},
+	{[]string{"closed", "beta"}, KindTest},
+	{[]string{"open", "beta"}, KindTest},
+	{[]string{"public", "beta"}, KindTest},
+	{[]string{"playtest"}, KindTest},
+	{[]string{"test"}, KindTest},
+	{[]string{"beta"}, KindTest},
+}
+
+// companion strips companion words off a title key, returning the key of
+// the accompanied game and the companion kind, empty for games
+func companion(key, appType string) (string, string) {
+	if strings.HasPrefix(key, "valvetestapp") {
+		return key, KindTest
+	}
+	words := strings.Fields(key)
+	kind := ""
+	for stripped := true; stripped; {
+		stripped = false
+		for _, c := range companions {
+			n := len(words) - len(c.words)
+			if n > 0 && strings.Join(words[n:], " ") == strings.Join(c.words, " ") {
+				words, kind, stripped = words[:n], c.kind, true
+				break
+			}
+		}
+	}
+	if kind == "" && strings.EqualFold(appType, "music") {
+		kind = KindSoundtrack
+	}
+	return strings.Join(words, " "), kind
+}
+
+// record is the features of a game record used in detection
+type record struct {
+	member    Member
+	key       string
+	kind      string
+	editions  []string
+	tokens    map[string]bool
+	companies map[string]bool
+	sig       []uint32
+}
+
+func newRecord(r store.GameRecord) *record {
+	t := title.Normalize(r.Name)
+	rec := &record{
+		member:    Member{ID: r.ID, Name: r.Name, Type: r.Type, Developers: r.Developers},
+		editions:  t.Editions,
+		tokens:    make(map[string]bool),
+		companies: make(map[string]bool),
+	}
+	rec.key, rec.kind = companion(t.Key, r.Type)
+	for _, w := range strings.Fields(rec.key) {
+		rec.tokens[w] = true
+	}
+	for _, c := range r.Developers {
+		if k := studio.Key(c); k != "" {
+			rec.companies[k] = true
+		}
+	}
+	text := r.DescriptionText
+	if text == "" {
+		text = r.AboutText
+	}
+	if text == "" {
+		text = sanitize.Text(r.Description)
+	}
+	rec.sig = signature(text)
+	return rec
+}
+
+// blocks are keys of records which may be duplicates, their title key
+// and bands of their description signature
+func (rec *record) blocks() []string {
+	ret := []string{"t:" + rec.key}
+	for i, b := range bands(rec.sig) {
+		ret = append(ret, "b"+strconv.Itoa(i)+":"+strconv.FormatUint(b, 16))
+	}
+	return ret
+}
+
+// score is the similarity of two records from 0 to 1 with its signals.
+// Name weighs most, description and developer overlap follow, unknown
+// signals count as half match. Descriptions of companions tell about
+// themselves rather than the game, so they are left out of companion pairs.
+func score(a, b *record) Pair {
+	p := Pair{A: a.member.ID, B: b.member.ID, Name: 1, Description: -1, Developers: -1}
+	if a.key != b.key {
+		p.Name = match.Jaccard(a.tokens, b.tokens)
+	}
+	desc, devs := 0.5, 0.5
+	if a.sig != nil && b.sig != nil {
+		p.Description = similarity(a.sig, b.sig)
+		desc = p.Description
+	}
+	if len(a.companies) > 0 && len(b.companies) > 0 {
+		p.Developers = match.Overlap(a.companies, b.companies)
+		devs = p.Developers
+	}
+	p.Kind = pairKind(a, b)
+	if p.Kind == KindTest || p.Kind == KindSoundtrack {
+		p.Score = (0.4*p.Name + 0.2*devs) / 0.6
+	} else {
+		p.Score = 0.4*p.Name + 0.4*desc + 0.2*devs
+	}
+	return p
+}
+
+// pairKind tells a companion from its game, and a reissue from a listing
+// of the same edition
+func pairKind(a, b *record) string {
+	switch {
+	case a.kind != b.kind && a.kind != "":
+		return a.kind
+	case a.kind != b.kind:
+		return b.kind
+	case a.key == b.key && strings.Join(a.editions, ",") != strings.Join(b.editions, ","):
+		return KindReissue
+	}
+	return KindDuplicate
+}
+
+// Detector finds duplicate records of a platform
+type Detector struct {
+	config   Config
+	store    store.GameStore
+	debugLog *log.Logger
+	infoLog  *log.Logger
+}
+
+// New creates a detector
+func New(cfg Config, db store.GameStore) *Detector {
+	return &Detector{
+		config:   cfg,
+		store:    db,
+		debugLog: log.New(os.Stderr, "Dupes DEBUG:", log.LstdFlags|log.Lshortfile),
+		infoLog:  log.New(os.Stderr, "Dupes INFO:", log.LstdFlags|log.Lshortfile),
+	}
+}
+
+// Detect pairs records sharing a title key or a description band, and groups
+// records connected by pairs of at least MinScore. Groups are ordered by kind
+// and their smallest record id.
+func (d *Detector) Detect() ([]Group, error) {
+	var records []*record
+	if err := d.store.ForEachGameRecord(d.config.Platform, func(subid string, gr store.GameRecord) error {
+		records = append(records, newRecord(gr))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	pairs := d.pair(records)
+	groups := group(records, pairs)
+	d.infoLog.Printf("detected %d duplicate groups in %d records of %s", len(groups), len(records), d.config.Platform)
+	return groups, nil
+}
+
+// pair scores records sharing a block, pairs below MinScore are dropped
+func (d *Detector) pair(records []*record) []Pair {
+	blocks := make(map[string][]int)
+	for i, rec := range records {
+		for _, b := range rec.blocks() {
+			blocks[b] = append(blocks[b], i)
+		}
+	}
+	seen := make(map[[2]int]bool)
+	var ret []Pair
+	for name, block := range blocks {
+		if len(block) > match.MaxBlock {
+			d.debugLog.Printf("skipped block %q of %d records", name, len(block))
+			continue
+		}
+		for i := range block {
+			for j := i + 1; j < len(block); j++ {
+				k := [2]int{block[i], block[j]}
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+				if p := score(records[k[0]], records[k[1]]); p.Score >= d.config.MinScore {
+					ret = append(ret, p)
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// group connects records by pairs, a group is named by the first kind
+// of kindOrder among its pairs
+func group(records []*record, pairs []Pair) []Group {
+	byID := make(map[int]*record, len(records))
+	for _, rec := range records {
+		byID[rec.member.ID] = rec
+	}
+	connected := match.NewGroups()
+	for _, p := range pairs {
+		connected.Union(strconv.Itoa(p.A), strconv.Itoa(p.B))
+	}
+	groups := make(map[string]*Group)
+	for _, p := range pairs {
+		if p.B < p.A {
+			p.A, p.B = p.B, p.A
+		}
+		root := connected.Find(strconv.Itoa(p.A))
+		g, ok := groups[root]
+		if !ok {
+			g = &Group{}
+			groups[root] = g
+		}
+		g.Pairs = append(g.Pairs, p)
+	}
+	ret := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		ids := make(map[int]bool)
+		for _, p := range g.Pairs {
+			ids[p.A], ids[p.B] = true, true
+		}
+		for id := range ids {
+			g.Records = append(g.Records, byID[id].member)
+		}
+		sort.Slice(g.Records, func(i, j int) bool { return g.Records[i].ID < g.Records[j].ID })
+		sort.Slice(g.Pairs, func(i, j int) bool {
+			if g.Pairs[i].A != g.Pairs[j].A {
+				return g.Pairs[i].A < g.Pairs[j].A
+			}
+			return g.Pairs[i].B < g.Pairs[j].B
+		})
+	kinds:
+		for _, k := range kindOrder {
+			for _, p := range g.Pairs {
+				if p.Kind == k {
+					g.Kind = k
+					break kinds
+				}
+			}
+		}
+		ret = append(ret, *g)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Kind != ret[j].Kind {
+			return kindRank(ret[i].Kind) < kindRank(ret[j].Kind)
+		}
+		return ret[i].Records[0].ID < ret[j].Records[0].ID
+	})
+	return ret
+}
+
+func kindRank(kind string) int {
+	for i, k := range kindOrder {
+		if k == kind {
+			return i
+		}
+	}
+	return len(kindOrder)
+}
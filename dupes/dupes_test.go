@@ -0,0 +1,114 @@
+package dupes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestCompanion(t *testing.T) {
+	var tests = []struct {
+		key     string
+		appType string
+		base    string
+		kind    string
+	}{
+		{"portal 2", "game", "portal 2", ""},
+		{"portal 2 soundtrack", "music", "portal 2", KindSoundtrack},
+		{"hades original soundtrack", "", "hades", KindSoundtrack},
+		{"celeste ost", "", "celeste", KindSoundtrack},
+		{"hollow knight", "music", "hollow knight", KindSoundtrack},
+		{"naraka bladepoint playtest", "game", "naraka bladepoint", KindTest},
+		{"pubg test server", "game", "pubg", KindTest},
+		{"dota 2 public test", "game", "dota 2", KindTest},
+		{"valvetestapp260", "", "valvetestapp260", KindTest},
+		{"beta", "game", "beta", ""},
+		{"turing test", "game", "turing", KindTest},
+	}
+	for caseid, c := range tests {
+		base, kind := companion(c.key, c.appType)
+		if base != c.base || kind != c.kind {
+			t.Errorf("case #%d, got: %q %q, expected: %q %q", caseid+1, base, kind, c.base, c.kind)
+		}
+	}
+}
+
+const description = `Portal 2 draws from the award-winning formula of innovative gameplay,
+story, and music that earned the original Portal over 70 industry accolades and created
+a cult following. The single-player portion of Portal 2 introduces a cast of dynamic
+new characters, a host of fresh puzzle elements, and a much larger set of devious test
+chambers.`
+
+func TestSignature(t *testing.T) {
+	var tests = []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{description, description, 1, 1},
+		{description, strings.Replace(description, "70", "seventy", 1), 0.7, 0.99},
+		{description, `Celeste is a platformer about climbing a mountain, made by a small team
+in Vancouver with hundreds of handcrafted challenges and secrets to find on the way up.`, 0, 0.1},
+	}
+	for caseid, c := range tests {
+		if s := similarity(signature(c.a), signature(c.b)); s < c.min || s > c.max {
+			t.Errorf("case #%d, got: %v, expected: %v to %v", caseid+1, s, c.min, c.max)
+		}
+	}
+	if sig := signature("too short to compare"); sig != nil {
+		t.Errorf("got signature of short text: %v", sig)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	valve := []string{"Valve"}
+	records := []store.GameRecord{
+		{ID: 620, Name: "Portal 2", Type: "game", Developers: valve, DescriptionText: description},
+		{ID: 323180, Name: "Portal 2 Soundtrack", Type: "music", Developers: valve},
+		{ID: 630, Name: "Portal 2 - Playtest", Type: "game", Developers: []string{"Valve Corporation"}},
+		{ID: 400, Name: "Portal", Type: "game", Developers: valve},
+		// reissue of a game with the same description
+		{ID: 1000, Name: "PORTAL™ 2: Game of the Year Edition", Type: "game", Developers: valve, DescriptionText: description},
+		// relisted by another developer with a copied description
+		{ID: 2000, Name: "Portal II", Type: "game", Developers: []string{"Asset Flipper"}, DescriptionText: description},
+		// same title of another game
+		{ID: 3000, Name: "Celeste", Type: "game", Developers: []string{"Maddy Makes Games"}},
+		{ID: 3001, Name: "Celeste", Type: "game", Developers: []string{"Someone Else"}},
+	}
+	db := storetest.New(t, records...)
+	groups, err := New(Config{Platform: "steam", MinScore: 0.7}, db).Detect()
+	if err != nil {
+		t.Fatalf("Detect err: %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("got groups: %+v, expected 1 group", groups)
+	}
+	var ids []int
+	for _, m := range groups[0].Records {
+		ids = append(ids, m.ID)
+	}
+	if g := groups[0]; g.Kind != KindDuplicate || !reflect.DeepEqual(ids, []int{620, 630, 1000, 2000, 323180}) {
+		t.Errorf("got: %s %v, expected: duplicate [620 630 1000 2000 323180]", g.Kind, ids)
+	}
+	kinds := make(map[[2]int]string)
+	for _, p := range groups[0].Pairs {
+		kinds[[2]int{p.A, p.B}] = p.Kind
+	}
+	var tests = []struct {
+		a, b int
+		kind string
+	}{
+		{620, 323180, KindSoundtrack},
+		{620, 630, KindTest},
+		{620, 1000, KindReissue},
+		{620, 2000, KindDuplicate},
+	}
+	for caseid, c := range tests {
+		if k := kinds[[2]int{c.a, c.b}]; k != c.kind {
+			t.Errorf("case #%d, got: %q, expected: %q", caseid+1, k, c.kind)
+		}
+	}
+}
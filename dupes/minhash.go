@@ -0,0 +1,96 @@
+package dupes
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// numHashes is the length of description signatures
+	numHashes = 64
+	// bandRows is the rows of a locality sensitive hashing band, descriptions
+	// sharing any band are compared, about 0.5 similarity is found half the time
+	bandRows = 4
+	// shingleSize is the words of a description shingle
+	shingleSize = 3
+	// minShingles leaves out descriptions too short to compare
+	minShingles = 8
+)
+
+// seeds of the signature hash functions, fixed so signatures are stable
+var seeds = func() [numHashes]uint64 {
+	var ret [numHashes]uint64
+	x := uint64(0x9e3779b97f4a7c15)
+	for i := range ret {
+		x = mix(x)
+		ret[i] = x
+	}
+	return ret
+}()
+
+// mix is the splitmix64 finalizer
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// shingles hashes overlapping word sequences of a text
+func shingles(text string) map[uint64]bool {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	ret := make(map[uint64]bool)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+shingleSize], " ")))
+		ret[h.Sum64()] = true
+	}
+	return ret
+}
+
+// signature is the MinHash signature of a text, nil if the text is too short
+func signature(text string) []uint32 {
+	sh := shingles(text)
+	if len(sh) < minShingles {
+		return nil
+	}
+	sig := make([]uint32, numHashes)
+	for i := range sig {
+		sig[i] = ^uint32(0)
+	}
+	for s := range sh {
+		for i, seed := range seeds {
+			if h := uint32(mix(s^seed) >> 32); h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// similarity estimates the jaccard similarity of texts by their signatures
+func similarity(a, b []uint32) float64 {
+	n := 0
+	for i := range a {
+		if a[i] == b[i] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(a))
+}
+
+// bands hashes each band of a signature
+func bands(sig []uint32) []uint64 {
+	ret := make([]uint64, 0, len(sig)/bandRows)
+	for i := 0; i+bandRows <= len(sig); i += bandRows {
+		h := uint64(i)
+		for _, v := range sig[i : i+bandRows] {
+			h = mix(h ^ uint64(v))
+		}
+		ret = append(ret, h)
+	}
+	return ret
+}
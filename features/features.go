@@ -0,0 +1,526 @@
+// Package features turns stored game records into a numeric feature table
+// for training models outside of gamecha. Feature definitions are versioned,
+// a table exported with the schema of an earlier export has the same columns.
+package features
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ksang/gamecha/release"
+	"github.com/ksang/gamecha/store"
+)
+
+// Version is the latest version of feature definitions
+const Version = 2
+
+var (
+	// ErrVersion indicates a feature version newer than this build knows
+	ErrVersion = errors.New("unknown feature version")
+	// ErrSchema indicates a schema of another platform or region than exported
+	ErrSchema = errors.New("schema is of another platform or region")
+)
+
+// Column types of schema
+const (
+	TypeInt   = "int"
+	TypeFloat = "float"
+	TypeBool  = "bool"
+)
+
+// Column is a column of the feature table. Empty cells are unknown values.
+type Column struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// Schema describes an exported table, written as sidecar of the table.
+// Vocabularies of one-hot columns are kept so later exports can reuse them.
+type Schema struct {
+	Version    int       `json:"version"`
+	Platform   string    `json:"platform"`
+	Region     string    `json:"region"`
+	Exported   time.Time `json:"exported"`
+	Rows       int       `json:"rows"`
+	Genres     []string  `json:"genres"`
+	Categories []string  `json:"categories"`
+	// PriceTiers are upper bounds of price tiers by currency, see priceTiers
+	PriceTiers map[string][]float64 `json:"price_tiers,omitempty"`
+	Columns    []Column             `json:"columns"`
+}
+
+// priceBuckets are upper bounds of price buckets of version 1 in currency
+// units of any currency, the last bucket has no bound
+var priceBuckets = []struct {
+	name  string
+	upper float64
+}{
+	{"price_lt_5", 5},
+	{"price_5_10", 10},
+	{"price_10_20", 20},
+	{"price_20_40", 40},
+	{"price_ge_40", 0},
+}
+
+// priceTiers are upper bounds of price tiers in currency units by currency,
+// priced alike to USD tiers on steam, the last tier has no bound. Prices
+// of other currencies have no tier.
+var priceTiers = map[string][]float64{
+	"USD": {5, 10, 20, 40},
+	"EUR": {5, 10, 20, 40},
+	"GBP": {4, 8, 16, 32},
+	"CAD": {6, 12, 25, 50},
+	"AUD": {7, 14, 28, 56},
+	"CNY": {20, 40, 80, 160},
+	"JPY": {500, 1000, 2000, 4000},
+	"KRW": {5000, 10000, 20000, 40000},
+	"RUB": {200, 400, 800, 1600},
+	"BRL": {15, 30, 60, 120},
+}
+
+// row is the data of a record to compute features of
+type row struct {
+	record store.GameRecord
+	// price is the latest price in region, nil if not collected
+	price *store.PricePoint
+}
+
+// group is columns computed together, added to feature definitions at version
+// since and dropped at version until, 0 if it is current
+type group struct {
+	since   int
+	until   int
+	columns func(s *Schema) []Column
+	values  func(s *Schema, r row) []string
+}
+
+// in reports if group is part of feature definitions of version
+func (g group) in(version int) bool {
+	return g.since <= version && (g.until == 0 || version < g.until)
+}
+
+// latestPrice is the latest price of row in region with its discount, false
+// if price was not collected in region
+func (r row) latestPrice() (float64, int, bool) {
+	switch {
+	case r.price != nil:
+		return float64(r.price.Final) / 100, r.price.Discount, true
+	case r.record.IsFree:
+		return 0, 0, true
+	}
+	return 0, 0, false
+}
+
+// groups are feature definitions in column order, groups are only appended
+// so columns of earlier versions keep their order
+var groups = []group{
+	{
+		since: 1,
+		columns: func(s *Schema) []Column {
+			return []Column{{"id", TypeInt, "app id on the platform, not a feature"}}
+		},
+		values: func(s *Schema, r row) []string {
+			return []string{strconv.Itoa(r.record.ID)}
+		},
+	},
+	{
+		since: 1,
+		columns: func(s *Schema) []Column {
+			return oneHotColumns("genre_", s.Genres, "genre")
+		},
+		values: func(s *Schema, r row) []string {
+			return oneHot(s.Genres, r.record.Genres)
+		},
+	},
+	{
+		since: 1,
+		columns: func(s *Schema) []Column {
+			return oneHotColumns("category_", s.Categories, "category")
+		},
+		values: func(s *Schema, r row) []string {
+			return oneHot(s.Categories, r.record.Categories)
+		},
+	},
+	{
+		// buckets of fixed bounds misplace prices of currencies other than USD
+		since: 1,
+		until: 2,
+		columns: func(s *Schema) []Column {
+			ret := []Column{
+				{"is_free", TypeBool, "free to play"},
+				{"price", TypeFloat, "latest final price in region currency"},
+				{"discount", TypeInt, "latest discount percent"},
+			}
+			lower := 0.0
+			for _, b := range priceBuckets {
+				desc := fmt.Sprintf("paid, priced from %g to below %g", lower, b.upper)
+				if b.upper == 0 {
+					desc = fmt.Sprintf("paid, priced from %g", lower)
+				}
+				ret = append(ret, Column{b.name, TypeBool, desc})
+				lower = b.upper
+			}
+			return ret
+		},
+		values: func(s *Schema, r row) []string {
+			ret := []string{boolValue(r.record.IsFree)}
+			price, discount, ok := r.latestPrice()
+			if !ok {
+				// price not collected in region
+				for i := 0; i < 2+len(priceBuckets); i++ {
+					ret = append(ret, "")
+				}
+				return ret
+			}
+			ret = append(ret, floatValue(price), strconv.Itoa(discount))
+			lower := 0.0
+			for _, b := range priceBuckets {
+				ret = append(ret, boolValue(price > 0 && price >= lower && (b.upper == 0 || price < b.upper)))
+				lower = b.upper
+			}
+			return ret
+		},
+	},
+	{
+		since: 1,
+		columns: func(s *Schema) []Column {
+			return []Column{
+				{"platform_windows", TypeBool, "runs on windows"},
+				{"platform_mac", TypeBool, "runs on mac"},
+				{"platform_linux", TypeBool, "runs on linux"},
+			}
+		},
+		values: func(s *Schema, r row) []string {
+			return oneHot([]string{"windows", "mac", "linux"}, r.record.Platforms)
+		},
+	},
+	{
+		since: 1,
+		columns: func(s *Schema) []Column {
+			return []Column{
+				{"languages_interface", TypeInt, "languages with interface"},
+				{"languages_audio", TypeInt, "languages with full audio"},
+				{"languages_subtitles", TypeInt, "languages with subtitles"},
+			}
+		},
+		values: func(s *Schema, r row) []string {
+			var iface, audio, subs int
+			for _, l := range r.record.LanguageSupport {
+				if l.Interface {
+					iface++
+				}
+				if l.FullAudio {
+					audio++
+				}
+				if l.Subtitles {
+					subs++
+				}
+			}
+			return []string{strconv.Itoa(iface), strconv.Itoa(audio), strconv.Itoa(subs)}
+		},
+	},
+	{
+		since: 1,
+		columns: func(s *Schema) []Column {
+			return []Column{
+				{"description_chars", TypeInt, "characters of description text"},
+				{"description_words", TypeInt, "words of description text"},
+				{"description_word_length", TypeFloat, "average word length of description text"},
+				{"short_description_words", TypeInt, "words of short description"},
+				{"media_count", TypeInt, "images and videos embedded in description"},
+			}
+		},
+		values: func(s *Schema, r row) []string {
+			words := strings.Fields(r.record.DescriptionText)
+			letters := 0
+			for _, w := range words {
+				letters += len([]rune(w))
+			}
+			avg := ""
+			if len(words) > 0 {
+				avg = floatValue(float64(letters) / float64(len(words)))
+			}
+			return []string{
+				strconv.Itoa(len([]rune(r.record.DescriptionText))),
+				strconv.Itoa(len(words)),
+				avg,
+				strconv.Itoa(len(strings.Fields(r.record.ShortDescription))),
+				strconv.Itoa(len(r.record.Media)),
+			}
+		},
+	},
+	{
+		since: 1,
+		columns: func(s *Schema) []Column {
+			return []Column{
+				{"release_year", TypeInt, "year of release"},
+				{"metacritic", TypeInt, "metacritic score"},
+			}
+		},
+		values: func(s *Schema, r row) []string {
+			return []string{intValue(release.Year(release.Of(r.record))), intValue(r.record.Metacritic)}
+		},
+	},
+	{
+		since: 2,
+		columns: func(s *Schema) []Column {
+			ret := []Column{
+				{"is_free", TypeBool, "free to play"},
+				{"price", TypeFloat, "latest final price in region currency"},
+				{"discount", TypeInt, "latest discount percent"},
+			}
+			for i := 0; i <= len(priceTiers["USD"]); i++ {
+				ret = append(ret, Column{fmt.Sprintf("price_tier_%d", i+1), TypeBool,
+					fmt.Sprintf("paid, in price tier %d of the currency in price_tiers of schema", i+1)})
+			}
+			return ret
+		},
+		values: func(s *Schema, r row) []string {
+			ret := []string{boolValue(r.record.IsFree)}
+			tiers := len(priceTiers["USD"]) + 1
+			price, discount, ok := r.latestPrice()
+			if !ok {
+				for i := 0; i < 2+tiers; i++ {
+					ret = append(ret, "")
+				}
+				return ret
+			}
+			ret = append(ret, floatValue(price), strconv.Itoa(discount))
+			var bounds []float64
+			if r.price != nil {
+				bounds = s.PriceTiers[r.price.Currency]
+			}
+			if price > 0 && bounds == nil {
+				// tiers of currency unknown
+				for i := 0; i < tiers; i++ {
+					ret = append(ret, "")
+				}
+				return ret
+			}
+			for i := 0; i < tiers; i++ {
+				in := price > 0 && (i == 0 || price >= bounds[i-1]) && (i == tiers-1 || price < bounds[i])
+				ret = append(ret, boolValue(in))
+			}
+			return ret
+		},
+	},
+}
+
+// slug forms a column name part from a genre or category, e.g. Single-player is single_player
+func slug(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), "_")
+}
+
+func oneHotColumns(prefix string, vocab []string, what string) []Column {
+	ret := make([]Column, 0, len(vocab))
+	for _, v := range vocab {
+		ret = append(ret, Column{prefix + v, TypeBool, "has " + what + " " + v})
+	}
+	return ret
+}
+
+// oneHot flags items of vocabulary in values, compared by slug
+func oneHot(vocab []string, values []string) []string {
+	has := make(map[string]bool)
+	for _, v := range values {
+		has[slug(v)] = true
+	}
+	ret := make([]string, 0, len(vocab))
+	for _, v := range vocab {
+		ret = append(ret, boolValue(has[v]))
+	}
+	return ret
+}
+
+func boolValue(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func floatValue(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// intValue formats an int, 0 is unknown and left empty
+func intValue(i int) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.Itoa(i)
+}
+
+// Config is the configuration struct of feature export
+type Config struct {
+	// Platform to export records of, e.g. steam
+	Platform string
+	// Region of prices, e.g. us
+	Region string
+	// Types of apps to export, e.g. game, all types if empty
+	Types []string
+	// Version of feature definitions, the latest if 0
+	Version int
+}
+
+// Exporter writes feature tables of records in store
+type Exporter struct {
+	config   Config
+	store    store.GameStore
+	debugLog *log.Logger
+	infoLog  *log.Logger
+}
+
+// New creates an exporter
+func New(cfg Config, db store.GameStore) *Exporter {
+	if cfg.Version == 0 {
+		cfg.Version = Version
+	}
+	return &Exporter{
+		config:   cfg,
+		store:    db,
+		debugLog: log.New(os.Stdout, "Features DEBUG:", log.LstdFlags|log.Lshortfile),
+		infoLog:  log.New(os.Stdout, "Features INFO:", log.LstdFlags|log.Lshortfile),
+	}
+}
+
+// Export writes the feature table of records as csv with a header row.
+// If schema is nil a schema is built with vocabularies of records in store,
+// otherwise the given schema of an earlier export is followed. The schema
+// of the table written is returned.
+func (e *Exporter) Export(w io.Writer, schema *Schema) (*Schema, error) {
+	if schema == nil {
+		schema = &Schema{Version: e.config.Version, Platform: e.config.Platform, Region: e.config.Region}
+		if schema.Version >= 2 {
+			schema.PriceTiers = priceTiers
+		}
+	} else if schema.Platform != e.config.Platform || schema.Region != e.config.Region {
+		return nil, ErrSchema
+	}
+	if schema.Version < 1 || schema.Version > Version {
+		return nil, ErrVersion
+	}
+	ids, genres, categories, err := e.scan()
+	if err != nil {
+		return nil, err
+	}
+	if schema.Columns == nil {
+		schema.Genres, schema.Categories = genres, categories
+		for _, g := range groups {
+			if g.in(schema.Version) {
+				schema.Columns = append(schema.Columns, g.columns(schema)...)
+			}
+		}
+	}
+	cw := csv.NewWriter(w)
+	header := make([]string, 0, len(schema.Columns))
+	for _, c := range schema.Columns {
+		header = append(header, c.Name)
+	}
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	schema.Rows = 0
+	for _, id := range ids {
+		r, err := e.row(id)
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		for _, g := range groups {
+			if g.in(schema.Version) {
+				values = append(values, g.values(schema, r)...)
+			}
+		}
+		if err := cw.Write(values); err != nil {
+			return nil, err
+		}
+		schema.Rows++
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return nil, err
+	}
+	schema.Exported = time.Now().UTC()
+	e.infoLog.Printf("exported %d %s records with %d features of version %d", schema.Rows, e.config.Platform,
+		len(schema.Columns), schema.Version)
+	return schema, nil
+}
+
+// scan finds ids of records to export in ascending order, with sorted
+// vocabularies of their genres and categories
+func (e *Exporter) scan() ([]int, []string, []string, error) {
+	var ids []int
+	genres, categories := make(map[string]bool), make(map[string]bool)
+	if err := e.store.ForEachGameRecord(e.config.Platform, func(subid string, r store.GameRecord) error {
+		if !e.exported(r.Type) {
+			return nil
+		}
+		ids = append(ids, r.ID)
+		for _, g := range r.Genres {
+			genres[slug(g)] = true
+		}
+		for _, c := range r.Categories {
+			categories[slug(c)] = true
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, nil, err
+	}
+	sort.Ints(ids)
+	return ids, sortedKeys(genres), sortedKeys(categories), nil
+}
+
+func (e *Exporter) exported(appType string) bool {
+	if len(e.config.Types) == 0 {
+		return true
+	}
+	for _, t := range e.config.Types {
+		if strings.EqualFold(t, appType) {
+			return true
+		}
+	}
+	return false
+}
+
+// row reads a record and its latest price in region
+func (e *Exporter) row(id int) (row, error) {
+	r, err := e.store.GetGameRecord(e.config.Platform, strconv.Itoa(id))
+	if err != nil {
+		return row{}, err
+	}
+	history, err := e.store.GetPriceHistory(e.config.Platform, id)
+	if err != nil {
+		return row{}, err
+	}
+	ret := row{record: *r}
+	for i, p := range history[e.config.Region] {
+		if ret.price == nil || p.Time.After(ret.price.Time) {
+			ret.price = &history[e.config.Region][i]
+		}
+	}
+	return ret, nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		if k != "" {
+			ret = append(ret, k)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
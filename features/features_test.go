@@ -0,0 +1,148 @@
+package features
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksang/gamecha/release"
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestExport(t *testing.T) {
+	records := []store.GameRecord{
+		{ID: 292030, Type: "game", Genres: []string{"RPG"}, Categories: []string{"Single-player"},
+			Platforms: []string{"windows"}, Release: release.Parse("1 Nov, 2015", false), Metacritic: 93,
+			LanguageSupport: []store.LanguageSupport{
+				{Code: "en", Interface: true, FullAudio: true, Subtitles: true},
+				{Code: "ja", Interface: true, Subtitles: true},
+			},
+			DescriptionText: "Become a monster slayer", ShortDescription: "Open world RPG"},
+		{ID: 570, Type: "game", IsFree: true, Genres: []string{"Action", "Free to Play"},
+			Categories: []string{"Multi-player"}, Platforms: []string{"windows", "mac", "linux"}},
+		{ID: 10, Type: "game", Genres: []string{"Action"}},
+		{ID: 378648, Type: "dlc", Genres: []string{"RPG"}},
+	}
+	db := storetest.New(t, records...)
+	now := time.Now()
+	for i, final := range []int{3999, 1999} {
+		if err := db.SavePricePoints("steam", "us", map[int]store.PricePoint{
+			292030: {Time: now.Add(time.Duration(i) * time.Hour), Currency: "USD", Initial: 3999, Final: final, Discount: 50 * i},
+		}); err != nil {
+			t.Fatalf("SavePricePoints err: %v", err)
+		}
+	}
+
+	// version 1 buckets prices by fixed bounds
+	e := New(Config{Platform: "steam", Region: "us", Types: []string{"game"}, Version: 1}, db)
+	var buf bytes.Buffer
+	schema, err := e.Export(&buf, nil)
+	if err != nil {
+		t.Fatalf("Export err: %v", err)
+	}
+	expected := []string{
+		"id,genre_action,genre_free_to_play,genre_rpg,category_multi_player,category_single_player," +
+			"is_free,price,discount,price_lt_5,price_5_10,price_10_20,price_20_40,price_ge_40," +
+			"platform_windows,platform_mac,platform_linux,languages_interface,languages_audio,languages_subtitles," +
+			"description_chars,description_words,description_word_length,short_description_words,media_count," +
+			"release_year,metacritic",
+		"10,1,0,0,0,0,0,,,,,,,,0,0,0,0,0,0,0,0,,0,0,,",
+		"570,1,1,0,1,0,1,0,0,0,0,0,0,0,1,1,1,0,0,0,0,0,,0,0,,",
+		"292030,0,0,1,0,1,0,19.99,50,0,0,1,0,0,1,0,0,2,1,2,23,4,5,3,0,2015,93",
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("got: %q, expected: %q", lines, expected)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line #%d, got: %s, expected: %s", i+1, lines[i], expected[i])
+		}
+	}
+	if schema.Version != 1 || schema.Rows != 3 || len(schema.Columns) != 27 || schema.PriceTiers != nil {
+		t.Errorf("got schema: %+v", schema)
+	}
+
+	// later exports with the schema keep columns, unknown genres are left out
+	if err := db.SaveGameRecord("steam", "1", store.GameRecord{ID: 1, Type: "game", Genres: []string{"Racing", "RPG"}}); err != nil {
+		t.Fatalf("SaveGameRecord err: %v", err)
+	}
+	buf.Reset()
+	again, err := e.Export(&buf, schema)
+	if err != nil {
+		t.Fatalf("Export with schema err: %v", err)
+	}
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if again.Rows != 4 || lines[0] != expected[0] || !strings.HasPrefix(lines[1], "1,0,0,1,0,0,") {
+		t.Errorf("got: %q, expected columns kept", lines[:2])
+	}
+
+	var tests = []struct {
+		cfg    Config
+		schema *Schema
+		err    error
+	}{
+		{Config{Platform: "steam", Region: "us", Version: Version + 1}, nil, ErrVersion},
+		{Config{Platform: "steam", Region: "cn"}, schema, ErrSchema},
+		{Config{Platform: "steam", Region: "us"}, &Schema{Version: 0, Platform: "steam", Region: "us"}, ErrVersion},
+	}
+	for caseid, c := range tests {
+		if _, err := New(c.cfg, db).Export(&buf, c.schema); err != c.err {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, err, c.err)
+		}
+	}
+}
+
+func TestExportPriceTiers(t *testing.T) {
+	db := storetest.New(t,
+		store.GameRecord{ID: 1, Type: "game"},
+		store.GameRecord{ID: 2, Type: "game", IsFree: true},
+		store.GameRecord{ID: 3, Type: "game"},
+		store.GameRecord{ID: 4, Type: "game"},
+	)
+	now := time.Now()
+	for region, points := range map[string]map[int]store.PricePoint{
+		"us": {
+			1: {Time: now, Currency: "USD", Initial: 1999, Final: 1999},
+			3: {Time: now, Currency: "USD", Initial: 5999, Final: 5999},
+		},
+		"cn": {
+			1: {Time: now, Currency: "CNY", Initial: 5800, Final: 5800},
+			3: {Time: now, Currency: "CNY", Initial: 1500, Final: 1500},
+			4: {Time: now, Currency: "XYZ", Initial: 1000, Final: 1000},
+		},
+	} {
+		if err := db.SavePricePoints("steam", region, points); err != nil {
+			t.Fatalf("SavePricePoints err: %v", err)
+		}
+	}
+	var tests = []struct {
+		region string
+		rows   []string
+	}{
+		{"us", []string{"0,19.99,0,0,0,1,0,0", "1,0,0,0,0,0,0,0", "0,59.99,0,0,0,0,0,1", "0,,,,,,,"}},
+		// 58 CNY is in the tier of 10 to 20 USD, unknown currencies have no tier
+		{"cn", []string{"0,58,0,0,0,1,0,0", "1,0,0,0,0,0,0,0", "0,15,0,1,0,0,0,0", "0,10,0,,,,,"}},
+	}
+	for caseid, c := range tests {
+		var buf bytes.Buffer
+		schema, err := New(Config{Platform: "steam", Region: c.region}, db).Export(&buf, nil)
+		if err != nil {
+			t.Fatalf("case #%d, Export err: %v", caseid+1, err)
+		}
+		if schema.Version != Version || len(schema.PriceTiers["CNY"]) != 4 {
+			t.Errorf("case #%d, got schema: %+v", caseid+1, schema)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if !strings.HasSuffix(lines[0], ",is_free,price,discount,price_tier_1,price_tier_2,price_tier_3,price_tier_4,price_tier_5") {
+			t.Errorf("case #%d, got header: %s", caseid+1, lines[0])
+		}
+		for i, expected := range c.rows {
+			if !strings.HasSuffix(lines[i+1], ","+expected) {
+				t.Errorf("case #%d, row #%d got: %s, expected suffix: %s", caseid+1, i+1, lines[i+1], expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Package identity matches records of the same game across platforms
+// and assigns them canonical game ids
+package identity
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ksang/gamecha/match"
+	"github.com/ksang/gamecha/release"
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/studio"
+	"github.com/ksang/gamecha/title"
+)
+
+// ErrBadRef indicates a record reference is not in platform:id form
+var ErrBadRef = errors.New("record reference must be platform:id")
+
+// Config is the configuration struct of identity resolution
+type Config struct {
+	// Platforms to match records of, e.g. steam gog
+	Platforms []string
+	// MinScore is the confidence a match needs to link records
+	MinScore float64
+	// ReviewScore is the confidence a match needs to be kept for review
+	ReviewScore float64
+}
+
+// Ref refers to a record on a platform as platform:id
+func Ref(platform string, id int) string {
+	return platform + ":" + strconv.Itoa(id)
+}
+
+// ParseRef splits a platform:id reference
+func ParseRef(ref string) (string, int, error) {
+	i := strings.LastIndex(ref, ":")
+	if i <= 0 {
+		return "", 0, ErrBadRef
+	}
+	id, err := strconv.Atoi(ref[i+1:])
+	if err != nil {
+		return "", 0, ErrBadRef
+	}
+	return ref[:i], id, nil
+}
+
+// record is the features of a game record used in matching
+type record struct {
+	ref       string
+	platform  string
+	key       string
+	tokens    map[string]bool
+	companies map[string]bool
+	year      int
+}
+
+func newRecord(platform string, r store.GameRecord) *record {
+	rec := &record{
+		ref:       Ref(platform, r.ID),
+		platform:  platform,
+		key:       title.Key(r.Name),
+		tokens:    make(map[string]bool),
+		companies: make(map[string]bool),
+		year:      release.Year(release.Of(r)),
+	}
+	for _, t := range strings.Fields(rec.key) {
+		rec.tokens[t] = true
+	}
+	for _, c := range append(append([]string{}, r.Developers...), r.Publishers...) {
+		if k := studio.Key(c); k != "" {
+			rec.companies[k] = true
+		}
+	}
+	return rec
+}
+
+// blocks are keys of records which may match, full title and its first two words
+func (rec *record) blocks() []string {
+	ret := []string{"t:" + rec.key}
+	if words := strings.Fields(rec.key); len(words) > 2 {
+		ret = append(ret, "p:"+strings.Join(words[:2], " "))
+	}
+	return ret
+}
+
+// score is the confidence of two records being the same game, from 0 to 1.
+// Title weighs most, developer and publisher overlap and release year follow.
+// Unknown companies or year count as half match.
+func score(a, b *record) float64 {
+	title := 1.0
+	if a.key != b.key {
+		title = match.Jaccard(a.tokens, b.tokens)
+	}
+	companies := 0.5
+	if len(a.companies) > 0 && len(b.companies) > 0 {
+		companies = match.Overlap(a.companies, b.companies)
+	}
+	year := 0.5
+	if a.year > 0 && b.year > 0 {
+		switch d := a.year - b.year; {
+		case d == 0:
+			year = 1
+		case d == 1 || d == -1:
+			year = 0.5
+		default:
+			year = 0
+		}
+	}
+	return 0.6*title + 0.25*companies + 0.15*year
+}
+
+// Resolver matches records across platforms
+type Resolver struct {
+	config   Config
+	store    store.GameStore
+	debugLog *log.Logger
+	infoLog  *log.Logger
+}
+
+// New creates a resolver
+func New(cfg Config, db store.GameStore) *Resolver {
+	return &Resolver{
+		config:   cfg,
+		store:    db,
+		debugLog: log.New(os.Stdout, "Identity DEBUG:", log.LstdFlags|log.Lshortfile),
+		infoLog:  log.New(os.Stdout, "Identity INFO:", log.LstdFlags|log.Lshortfile),
+	}
+}
+
+// Resolve scores matches of records across platforms, applies manual overrides
+// and assigns canonical ids to records. Records linked by confirmed matches or
+// matches of at least MinScore share a canonical id, existing canonical ids
+// are kept where possible. Matches of at least ReviewScore are saved for review.
+func (r *Resolver) Resolve() ([]store.Link, error) {
+	records := make(map[string]*record)
+	for _, p := range r.config.Platforms {
+		if err := r.store.ForEachGameRecord(p, func(subid string, gr store.GameRecord) error {
+			rec := newRecord(p, gr)
+			records[rec.ref] = rec
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	overrides, err := r.store.GetLinkOverrides()
+	if err != nil {
+		return nil, err
+	}
+	links := r.match(records, overrides)
+	old, err := r.store.GetCanonicalIDs()
+	if err != nil {
+		return nil, err
+	}
+	canonical := r.assignCanonical(records, links, old)
+	r.infoLog.Printf("resolved %d records of %v, %d links", len(records), r.config.Platforms, len(links))
+	return links, r.store.SaveLinks(links, canonical)
+}
+
+// match pairs records of different platforms sharing a block, and pairs
+// confirmed by overrides, links are ordered by score descending
+func (r *Resolver) match(records map[string]*record, overrides map[string]string) []store.Link {
+	blocks := make(map[string][]*record)
+	for _, rec := range records {
+		for _, b := range rec.blocks() {
+			blocks[b] = append(blocks[b], rec)
+		}
+	}
+	pairs := make(map[string]store.Link)
+	addPair := func(a, b *record) {
+		key := store.LinkKey(a.ref, b.ref)
+		if _, ok := pairs[key]; ok {
+			return
+		}
+		l := store.Link{A: a.ref, B: b.ref, Score: score(a, b), Override: overrides[key]}
+		if b.ref < a.ref {
+			l.A, l.B = b.ref, a.ref
+		}
+		if l.Score >= r.config.ReviewScore || l.Override != "" {
+			pairs[key] = l
+		}
+	}
+	for name, block := range blocks {
+		if len(block) > match.MaxBlock {
+			r.debugLog.Printf("skipped block %q of %d records", name, len(block))
+			continue
+		}
+		for i := range block {
+			for j := i + 1; j < len(block); j++ {
+				if block[i].platform != block[j].platform {
+					addPair(block[i], block[j])
+				}
+			}
+		}
+	}
+	for key, o := range overrides {
+		refs := strings.SplitN(key, "|", 2)
+		a, b := records[refs[0]], records[refs[len(refs)-1]]
+		if o == store.LinkConfirmed && a != nil && b != nil && a != b {
+			addPair(a, b)
+		}
+	}
+	links := make([]store.Link, 0, len(pairs))
+	for _, l := range pairs {
+		links = append(links, l)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Score != links[j].Score {
+			return links[i].Score > links[j].Score
+		}
+		return store.LinkKey(links[i].A, links[i].B) < store.LinkKey(links[j].A, links[j].B)
+	})
+	return links
+}
+
+// assignCanonical groups records by applied links, a group keeps the smallest
+// existing canonical id it owns, other groups take their smallest reference
+func (r *Resolver) assignCanonical(records map[string]*record, links []store.Link, old map[string]string) map[string]string {
+	groups := match.NewGroups()
+	find := groups.Find
+	for _, l := range links {
+		if l.Override == store.LinkRejected {
+			continue
+		}
+		if l.Override == store.LinkConfirmed || l.Score >= r.config.MinScore {
+			groups.Union(l.A, l.B)
+		}
+	}
+	// an existing id split over groups is owned by the group of the record it
+	// refers to, or else the group with most records having it
+	claims := make(map[string]map[string]int)
+	smallest := make(map[string]string)
+	for ref := range records {
+		root := find(ref)
+		if id, ok := old[ref]; ok {
+			if claims[id] == nil {
+				claims[id] = make(map[string]int)
+			}
+			claims[id][root]++
+		}
+		if s, ok := smallest[root]; !ok || ref < s {
+			smallest[root] = ref
+		}
+	}
+	kept := make(map[string]string)
+	for id, roots := range claims {
+		owner := ""
+		if _, ok := records[id]; ok && roots[find(id)] > 0 {
+			owner = find(id)
+		} else {
+			for root, n := range roots {
+				if owner == "" || n > roots[owner] || (n == roots[owner] && root < owner) {
+					owner = root
+				}
+			}
+		}
+		if k, ok := kept[owner]; !ok || id < k {
+			kept[owner] = id
+		}
+	}
+	ret := make(map[string]string, len(records))
+	for ref := range records {
+		root := find(ref)
+		if id, ok := kept[root]; ok {
+			ret[ref] = id
+		} else {
+			ret[ref] = smallest[root]
+		}
+	}
+	return ret
+}
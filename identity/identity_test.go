@@ -0,0 +1,130 @@
+package identity
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ksang/gamecha/release"
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestParseRef(t *testing.T) {
+	var tests = []struct {
+		s        string
+		platform string
+		id       int
+		err      error
+	}{
+		{"steam:292030", "steam", 292030, nil},
+		{"gog:1207664643", "gog", 1207664643, nil},
+		{"292030", "", 0, ErrBadRef},
+		{"steam:abc", "", 0, ErrBadRef},
+	}
+	for caseid, c := range tests {
+		p, id, err := ParseRef(c.s)
+		if p != c.platform || id != c.id || err != c.err {
+			t.Errorf("case #%d, got: %s %d %v, expected: %s %d %v", caseid+1, p, id, err, c.platform, c.id, c.err)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	witcher := store.GameRecord{Name: "The Witcher® 3: Wild Hunt", Developers: []string{"CD PROJEKT RED"},
+		Publishers: []string{"CD PROJEKT RED"}, Release: release.Parse("2015", false)}
+	var tests = []struct {
+		b store.GameRecord
+		s float64
+	}{
+		{store.GameRecord{Name: "The Witcher 3 - Wild Hunt", Developers: []string{"CD Projekt Red"},
+			Publishers: []string{"CD Projekt Red", "Bandai Namco"}, Release: release.Parse("2015", false)}, 1},
+		// unknown companies and year
+		{store.GameRecord{Name: "the witcher 3 wild hunt"}, 0.8},
+		{store.GameRecord{Name: "The Witcher 3: Wild Hunt", Developers: []string{"Someone Else Ltd."}, Release: release.Parse("2012", false)}, 0.6},
+		{store.GameRecord{Name: "The Witcher 2", Release: release.Parse("2011", false)}, 0.6*1/5 + 0.125},
+	}
+	a := newRecord("steam", witcher)
+	for caseid, c := range tests {
+		if s := score(a, newRecord("gog", c.b)); math.Abs(s-c.s) > 1e-9 {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, s, c.s)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	db := storetest.New(t)
+	records := map[string][]store.GameRecord{
+		"steam": {
+			{ID: 292030, Name: "The Witcher® 3: Wild Hunt", Developers: []string{"CD PROJEKT RED"}, Release: release.Parse("2015", false)},
+			{ID: 20900, Name: "The Witcher: Enhanced Edition", Developers: []string{"CD PROJEKT RED"}, Release: release.Parse("2008", false)},
+			{ID: 10, Name: "Counter-Strike", Developers: []string{"Valve"}, Release: release.Parse("2000", false)},
+		},
+		"gog": {
+			{ID: 1207664643, Name: "The Witcher 3: Wild Hunt", Developers: []string{"CD PROJEKT RED"}, Release: release.Parse("2015", false)},
+			{ID: 1207658924, Name: "The Witcher: Enhanced Edition", Developers: []string{"CD PROJEKT RED"}, Release: release.Parse("2008", false)},
+			{ID: 1, Name: "Counter Strike", Developers: []string{"Other"}, Release: release.Parse("2019", false)},
+		},
+	}
+	for p, rs := range records {
+		storetest.Save(t, db, p, rs...)
+	}
+	r := New(Config{Platforms: []string{"steam", "gog"}, MinScore: 0.8, ReviewScore: 0.5}, db)
+	var tests = []struct {
+		override  [3]string
+		canonical map[string]string
+		links     int
+	}{
+		{
+			[3]string{},
+			map[string]string{
+				"steam:292030": "gog:1207664643", "gog:1207664643": "gog:1207664643",
+				"steam:20900": "gog:1207658924", "gog:1207658924": "gog:1207658924",
+				"steam:10": "steam:10", "gog:1": "gog:1",
+			},
+			3,
+		},
+		{
+			// rejected match splits, the record referred by canonical id keeps it
+			[3]string{"steam:292030", "gog:1207664643", store.LinkRejected},
+			map[string]string{
+				"steam:292030": "steam:292030", "gog:1207664643": "gog:1207664643",
+				"steam:20900": "gog:1207658924", "gog:1207658924": "gog:1207658924",
+				"steam:10": "steam:10", "gog:1": "gog:1",
+			},
+			3,
+		},
+		{
+			// low scored match confirmed, existing canonical ids are kept
+			[3]string{"steam:10", "gog:1", store.LinkConfirmed},
+			map[string]string{
+				"steam:292030": "steam:292030", "gog:1207664643": "gog:1207664643",
+				"steam:20900": "gog:1207658924", "gog:1207658924": "gog:1207658924",
+				"steam:10": "gog:1", "gog:1": "gog:1",
+			},
+			3,
+		},
+	}
+	for caseid, c := range tests {
+		if c.override[0] != "" {
+			if err := db.SaveLinkOverride(c.override[0], c.override[1], c.override[2]); err != nil {
+				t.Fatalf("case #%d, SaveLinkOverride err: %v", caseid+1, err)
+			}
+		}
+		links, err := r.Resolve()
+		if err != nil {
+			t.Fatalf("case #%d, Resolve err: %v", caseid+1, err)
+		}
+		canonical, err := db.GetCanonicalIDs()
+		if err != nil {
+			t.Fatalf("case #%d, GetCanonicalIDs err: %v", caseid+1, err)
+		}
+		if len(links) != c.links || !reflect.DeepEqual(canonical, c.canonical) {
+			t.Errorf("case #%d, got: %v %v, expected: %d links %v", caseid+1, links, canonical, c.links, c.canonical)
+		}
+	}
+	saved, _ := db.GetLinks()
+	if len(saved) != 3 {
+		t.Errorf("got saved links: %v", saved)
+	}
+}
@@ -0,0 +1,102 @@
+package learn
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+)
+
+// doc is a record prepared for training or prediction, words are unique
+type doc struct {
+	id     int
+	name   string
+	genres []string
+	words  []string
+}
+
+// Prediction is the probability of a record having a genre
+type Prediction struct {
+	Genre       string
+	Probability float64
+}
+
+// train counts words of documents in all and per genre. Words in fewer than
+// minWordDocs documents and genres of fewer than minDocs documents are left out.
+// Documents are binarized, a word counts once per document.
+func train(docs []doc, minDocs int) store.GenreModel {
+	df := make(map[string]int)
+	for _, d := range docs {
+		for _, w := range d.words {
+			df[w]++
+		}
+	}
+	m := store.GenreModel{
+		Trained: time.Now().UTC(),
+		Docs:    len(docs),
+		Words:   make(map[string]int),
+		Genres:  make(map[string]store.GenreCounts),
+	}
+	genreDocs := make(map[string]int)
+	for _, d := range docs {
+		for _, g := range d.genres {
+			genreDocs[g]++
+		}
+	}
+	for _, d := range docs {
+		for _, w := range d.words {
+			if df[w] < minWordDocs {
+				continue
+			}
+			m.Words[w]++
+			m.Total++
+			for _, g := range d.genres {
+				if genreDocs[g] < minDocs {
+					continue
+				}
+				gc, ok := m.Genres[g]
+				if !ok {
+					gc = store.GenreCounts{Words: make(map[string]int)}
+				}
+				gc.Words[w]++
+				gc.Total++
+				m.Genres[g] = gc
+			}
+		}
+	}
+	for g, gc := range m.Genres {
+		gc.Docs = genreDocs[g]
+		m.Genres[g] = gc
+	}
+	return m
+}
+
+// predict scores each genre of model against the rest, one versus rest,
+// with Laplace smoothing. Naive Bayes probabilities tend to be extreme, they
+// rank genres well but are not calibrated. Predictions are sorted by
+// probability descending.
+func predict(m *store.GenreModel, words []string) []Prediction {
+	vocab := float64(len(m.Words))
+	ret := make([]Prediction, 0, len(m.Genres))
+	for g, gc := range m.Genres {
+		logit := math.Log(float64(gc.Docs+1) / float64(m.Docs-gc.Docs+1))
+		for _, w := range words {
+			all, ok := m.Words[w]
+			if !ok {
+				continue
+			}
+			in := (float64(gc.Words[w]) + 1) / (float64(gc.Total) + vocab)
+			out := (float64(all-gc.Words[w]) + 1) / (float64(m.Total-gc.Total) + vocab)
+			logit += math.Log(in / out)
+		}
+		ret = append(ret, Prediction{Genre: g, Probability: 1 / (1 + math.Exp(-logit))})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Probability != ret[j].Probability {
+			return ret[i].Probability > ret[j].Probability
+		}
+		return ret[i].Genre < ret[j].Genre
+	})
+	return ret
+}
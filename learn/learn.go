@@ -0,0 +1,274 @@
+// Package learn trains genre classifiers on records having genres, to predict
+// genres of records from sources without them
+package learn
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ksang/gamecha/recommend"
+	"github.com/ksang/gamecha/store"
+)
+
+var (
+	// ErrNoModel indicates no model is trained by the name
+	ErrNoModel = errors.New("no genre model trained, run learn train first")
+	// ErrTooFewRecords indicates there are fewer labeled records than folds
+	ErrTooFewRecords = errors.New("fewer labeled records than folds")
+)
+
+// minWordDocs leaves out words of a single document, they tell nothing
+const minWordDocs = 2
+
+// Config is the configuration struct of genre learning
+type Config struct {
+	// Platform to train on or predict records of, e.g. steam
+	Platform string
+	// Types of apps to learn from and predict, e.g. game, all types if empty
+	Types []string
+	// MinDocs is the labeled records a genre needs to be learned
+	MinDocs int
+	// Threshold is the probability a genre needs to be predicted
+	Threshold float64
+}
+
+// Metrics is the cross-validation result of a genre
+type Metrics struct {
+	Genre     string
+	Support   int
+	Precision float64
+	Recall    float64
+	F1        float64
+}
+
+// Report is the cross-validation result of a classifier, micro averages
+// count every prediction, macro averages weigh genres equally
+type Report struct {
+	Folds          int
+	Docs           int
+	Genres         []Metrics
+	MicroPrecision float64
+	MicroRecall    float64
+	MicroF1        float64
+	MacroF1        float64
+}
+
+// RecordPrediction is the genres predicted for a record, with genres it has
+type RecordPrediction struct {
+	ID          int
+	Name        string
+	Genres      []string
+	Predictions []Prediction
+}
+
+// Learner trains, evaluates and applies genre classifiers
+type Learner struct {
+	config   Config
+	store    store.GameStore
+	debugLog *log.Logger
+	infoLog  *log.Logger
+}
+
+// New creates a learner
+func New(cfg Config, db store.GameStore) *Learner {
+	return &Learner{
+		config:   cfg,
+		store:    db,
+		debugLog: log.New(os.Stdout, "Learn DEBUG:", log.LstdFlags|log.Lshortfile),
+		infoLog:  log.New(os.Stdout, "Learn INFO:", log.LstdFlags|log.Lshortfile),
+	}
+}
+
+// newDoc prepares a record, name and descriptions are its text
+func newDoc(r store.GameRecord) doc {
+	text := r.DescriptionText
+	if text == "" {
+		text = r.AboutText
+	}
+	seen := make(map[string]bool)
+	d := doc{id: r.ID, name: r.Name, genres: r.Genres}
+	for _, w := range recommend.Words(r.Name + " " + r.ShortDescription + " " + text) {
+		if !seen[w] {
+			seen[w] = true
+			d.words = append(d.words, w)
+		}
+	}
+	return d
+}
+
+func (l *Learner) learned(appType string) bool {
+	if len(l.config.Types) == 0 {
+		return true
+	}
+	for _, t := range l.config.Types {
+		if strings.EqualFold(t, appType) {
+			return true
+		}
+	}
+	return false
+}
+
+// labeled reads records having genres, ordered by id
+func (l *Learner) labeled() ([]doc, error) {
+	var docs []doc
+	if err := l.store.ForEachGameRecord(l.config.Platform, func(subid string, r store.GameRecord) error {
+		if len(r.Genres) > 0 && l.learned(r.Type) {
+			docs = append(docs, newDoc(r))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].id < docs[j].id })
+	return docs, nil
+}
+
+// Train fits a classifier on records having genres, saved by platform name
+func (l *Learner) Train() (*store.GenreModel, error) {
+	docs, err := l.labeled()
+	if err != nil {
+		return nil, err
+	}
+	m := train(docs, l.config.MinDocs)
+	l.infoLog.Printf("trained on %d %s records, %d genres, %d words", m.Docs, l.config.Platform, len(m.Genres), len(m.Words))
+	return &m, l.store.SaveGenreModel(l.config.Platform, m)
+}
+
+// Eval cross-validates a classifier on records having genres. Records are
+// split into folds by id order, each fold is predicted by a classifier
+// trained on the other folds.
+func (l *Learner) Eval(folds int) (*Report, error) {
+	docs, err := l.labeled()
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) < folds || folds < 2 {
+		return nil, ErrTooFewRecords
+	}
+	type counts struct{ tp, fp, fn, support int }
+	byGenre := make(map[string]*counts)
+	for g := range train(docs, l.config.MinDocs).Genres {
+		byGenre[g] = &counts{}
+	}
+	for _, d := range docs {
+		for _, g := range d.genres {
+			if c, ok := byGenre[g]; ok {
+				c.support++
+			}
+		}
+	}
+	for f := 0; f < folds; f++ {
+		var training, test []doc
+		for i, d := range docs {
+			if i%folds == f {
+				test = append(test, d)
+			} else {
+				training = append(training, d)
+			}
+		}
+		m := train(training, l.config.MinDocs)
+		for _, d := range test {
+			has := make(map[string]bool)
+			for _, g := range d.genres {
+				has[g] = true
+			}
+			predicted := make(map[string]bool)
+			for _, p := range predict(&m, d.words) {
+				if p.Probability >= l.config.Threshold {
+					predicted[p.Genre] = true
+				}
+			}
+			for g, c := range byGenre {
+				switch {
+				case predicted[g] && has[g]:
+					c.tp++
+				case predicted[g]:
+					c.fp++
+				case has[g]:
+					c.fn++
+				}
+			}
+		}
+		l.debugLog.Printf("fold %d of %d, trained on %d, tested on %d records", f+1, folds, len(training), len(test))
+	}
+	report := &Report{Folds: folds, Docs: len(docs)}
+	var total counts
+	for g, c := range byGenre {
+		p, r := ratio(c.tp, c.tp+c.fp), ratio(c.tp, c.tp+c.fn)
+		report.Genres = append(report.Genres, Metrics{Genre: g, Support: c.support, Precision: p, Recall: r, F1: f1(p, r)})
+		report.MacroF1 += f1(p, r) / float64(len(byGenre))
+		total.tp, total.fp, total.fn = total.tp+c.tp, total.fp+c.fp, total.fn+c.fn
+	}
+	sort.Slice(report.Genres, func(i, j int) bool {
+		if report.Genres[i].Support != report.Genres[j].Support {
+			return report.Genres[i].Support > report.Genres[j].Support
+		}
+		return report.Genres[i].Genre < report.Genres[j].Genre
+	})
+	report.MicroPrecision = ratio(total.tp, total.tp+total.fp)
+	report.MicroRecall = ratio(total.tp, total.tp+total.fn)
+	report.MicroF1 = f1(report.MicroPrecision, report.MicroRecall)
+	return report, nil
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+func f1(p, r float64) float64 {
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+// Predict ranks genres of records by the model trained on platform of model.
+// Records of ids are predicted if given, otherwise records without genres.
+// The n most probable genres of at least Threshold are kept of each record.
+func (l *Learner) Predict(model string, ids []int, n int) ([]RecordPrediction, error) {
+	m, err := l.store.GetGenreModel(model)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrNoModel
+	}
+	var docs []doc
+	if len(ids) > 0 {
+		for _, id := range ids {
+			r, err := l.store.GetGameRecord(l.config.Platform, strconv.Itoa(id))
+			if err != nil {
+				return nil, err
+			}
+			if r.ID != 0 {
+				docs = append(docs, newDoc(*r))
+			}
+		}
+	} else if err := l.store.ForEachGameRecord(l.config.Platform, func(subid string, r store.GameRecord) error {
+		if len(r.Genres) == 0 && l.learned(r.Type) {
+			docs = append(docs, newDoc(r))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].id < docs[j].id })
+	ret := make([]RecordPrediction, 0, len(docs))
+	for _, d := range docs {
+		var ps []Prediction
+		for _, p := range predict(m, d.words) {
+			if len(ps) < n && p.Probability >= l.config.Threshold {
+				ps = append(ps, p)
+			}
+		}
+		ret = append(ret, RecordPrediction{ID: d.id, Name: d.name, Genres: d.genres, Predictions: ps})
+	}
+	return ret, nil
+}
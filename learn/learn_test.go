@@ -0,0 +1,98 @@
+package learn
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestLearn(t *testing.T) {
+	db := storetest.New(t)
+	rpg, racing, puzzle := "RPG", "Racing", "Puzzle"
+	texts := []struct {
+		genres []string
+		text   string
+	}{
+		{[]string{rpg}, "Slay the dragon with sword and magic on an epic quest."},
+		{[]string{rpg}, "A quest for the lost sword, learn magic and level your party."},
+		{[]string{rpg}, "Dragon hunters quest across kingdoms, forging a sword of magic."},
+		{[]string{rpg}, "Level up your party, cast magic and loot dungeons on a quest."},
+		{[]string{rpg}, "An epic dragon quest where every sword swing and magic spell counts."},
+		{[]string{racing}, "Drift your car around the track at top speed."},
+		{[]string{racing}, "Tune every car, race rivals on the track and boost speed."},
+		{[]string{racing}, "Rally car racing, drift through gravel track at speed."},
+		{[]string{racing}, "Street race at night, nitro speed boost and car tuning."},
+		{[]string{racing}, "Kart race on a rainbow track, drift and boost your speed."},
+		{[]string{puzzle}, "Match tiles to clear levels in this relaxing puzzle."},
+		{[]string{puzzle}, "Slide tiles and solve each puzzle in fewer moves over levels."},
+		{[]string{puzzle}, "Hundreds of puzzle levels, rotate tiles to connect pipes."},
+		{[]string{puzzle}, "Logic puzzle levels, place tiles to light every room."},
+		{[]string{puzzle, rpg}, "Puzzle quest: match tiles to cast magic and slay the dragon."},
+		{[]string{"Sports"}, "Golf with friends."},
+	}
+	for i, x := range texts {
+		storetest.Save(t, db, "steam", store.GameRecord{ID: i + 1, Name: "Game " + strconv.Itoa(i+1), Type: "game", Genres: x.genres, DescriptionText: x.text})
+	}
+	unlabeled := []store.GameRecord{
+		{ID: 100, Name: "Wyrm Saga", Type: "game", DescriptionText: "A dragon quest with sword and magic."},
+		{ID: 101, Name: "Turbo Rush", Type: "game", DescriptionText: "Drift the car around the track at speed."},
+		{ID: 102, Name: "Lumen", Type: "game", Genres: []string{puzzle}, DescriptionText: "Rotate tiles to solve levels."},
+	}
+	storetest.Save(t, db, "gog", unlabeled...)
+
+	cfg := Config{Platform: "steam", Types: []string{"game"}, MinDocs: 3, Threshold: 0.5}
+	if _, err := New(Config{Platform: "gog", Threshold: 0.5}, db).Predict("steam", nil, 3); err != ErrNoModel {
+		t.Errorf("got: %v, expected: %v", err, ErrNoModel)
+	}
+	m, err := New(cfg, db).Train()
+	if err != nil {
+		t.Fatalf("Train err: %v", err)
+	}
+	if m.Docs != len(texts) || len(m.Genres) != 3 || m.Genres[rpg].Docs != 6 {
+		t.Errorf("got model of %d docs, genres %v", m.Docs, len(m.Genres))
+	}
+
+	report, err := New(cfg, db).Eval(4)
+	if err != nil {
+		t.Fatalf("Eval err: %v", err)
+	}
+	if report.Folds != 4 || report.Docs != len(texts) || len(report.Genres) != 3 ||
+		report.Genres[0].Genre != rpg || report.Genres[0].Support != 6 || report.MicroF1 < 0.8 {
+		t.Errorf("got report: %+v", report)
+	}
+	if _, err := New(cfg, db).Eval(len(texts) + 1); err != ErrTooFewRecords {
+		t.Errorf("got: %v, expected: %v", err, ErrTooFewRecords)
+	}
+
+	var tests = []struct {
+		ids    []int
+		res    []int
+		genres [][]string
+	}{
+		// records without genres
+		{nil, []int{100, 101}, [][]string{{rpg}, {racing}}},
+		{[]int{102, 999}, []int{102}, [][]string{{puzzle}}},
+	}
+	for caseid, c := range tests {
+		preds, err := New(Config{Platform: "gog", Types: []string{"game"}, Threshold: 0.5}, db).Predict("steam", c.ids, 1)
+		if err != nil {
+			t.Fatalf("case #%d, Predict err: %v", caseid+1, err)
+		}
+		var ids []int
+		var genres [][]string
+		for _, p := range preds {
+			ids = append(ids, p.ID)
+			var gs []string
+			for _, g := range p.Predictions {
+				gs = append(gs, g.Genre)
+			}
+			genres = append(genres, gs)
+		}
+		if !reflect.DeepEqual(ids, c.res) || !reflect.DeepEqual(genres, c.genres) {
+			t.Errorf("case #%d, got: %v %v, expected: %v %v", caseid+1, ids, genres, c.res, c.genres)
+		}
+	}
+}
@@ -2,16 +2,31 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/ksang/gamecha/alert"
+	"github.com/ksang/gamecha/cluster"
+	"github.com/ksang/gamecha/dupes"
+	"github.com/ksang/gamecha/features"
+	"github.com/ksang/gamecha/identity"
+	"github.com/ksang/gamecha/learn"
 	"github.com/ksang/gamecha/query"
+	"github.com/ksang/gamecha/recommend"
+	"github.com/ksang/gamecha/release"
+	"github.com/ksang/gamecha/report"
 	"github.com/ksang/gamecha/seeker"
 	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/studio"
 
 	"gopkg.in/alecthomas/kingpin.v2"
 )
@@ -20,11 +35,162 @@ var (
 	app        = kingpin.New("gamecha", "Game metadata toolkits.")
 	cf         = app.Flag("config", "config file path").Default("gamecha.yml").String()
 	sk         = app.Command("seeker", "Start gamecha in seeker mode.")
+	sp         = app.Command("sampler", "Sample concurrent players of tracked games until interrupted.")
 	op         = app.Command("query", "Query gamecha store.")
 	opList     = op.Command("list", "List all games in store.")
 	opPlatform = opList.Flag("platform", "Which platform to query").Default("steam").String()
+	opName     = opList.Flag("name", "Only games with all words in title, e.g. witcher 3").String()
+	opType     = opList.Flag("type", "Only apps of type, e.g. game, dlc, demo").String()
+	opIface    = opList.Flag("interface", "Only games with interface in language, e.g. ja").String()
+	opAudio    = opList.Flag("audio", "Only games with full audio in language, e.g. ja").String()
+	opSubs     = opList.Flag("subtitles", "Only games with subtitles in language, e.g. ja").String()
+	opDelisted = opList.Flag("delisted", "Only games gone from the platform").Bool()
+	opCluster  = opList.Flag("cluster", "Only games of cluster id, see query clusters").Int()
+	opShow     = op.Command("show", "Show detail of a game in store.")
+	opShowID   = opShow.Arg("id", "Game id on the platform").Required().Int()
+	opShowPf   = opShow.Flag("platform", "Which platform to query").Default("steam").String()
+	opShowLang = opShow.Flag("lang", "Language code of texts, e.g. zh-CN for schinese in localizations config").String()
+	opDLC      = op.Command("dlc", "List DLC of a game.")
+	opDLCID    = opDLC.Arg("id", "Game id on the platform").Required().Int()
+	opDLCPf    = opDLC.Flag("platform", "Which platform to query").Default("steam").String()
+	opClusters = op.Command("clusters", "List clusters found by the latest clustering analysis.")
+	opClsPf    = opClusters.Flag("platform", "Which platform to query").Default("steam").String()
+	opPkg      = op.Command("package", "List apps bundled in a package.")
+	opPkgID    = opPkg.Arg("id", "Package id on the platform").Required().Int()
+	opPkgPf    = opPkg.Flag("platform", "Which platform to query").Default("steam").String()
+	pr         = app.Command("prices", "Show price history of a game.")
+	prID       = pr.Arg("id", "Game id on the platform").Required().Int()
+	prPlatform = pr.Flag("platform", "Which platform to query").Default("steam").String()
+	rv         = app.Command("reviews", "Show collected user reviews of a game.")
+	rvID       = rv.Arg("id", "Game id on the platform").Required().Int()
+	rvNum      = rv.Flag("num", "Number of reviews to show").Short('n').Default("10").Int()
+	rvPlatform = rv.Flag("platform", "Which platform to query").Default("steam").String()
+	pl         = app.Command("players", "Show concurrent player samples of a game.")
+	plID       = pl.Arg("id", "Game id on the platform").Required().Int()
+	plSince    = pl.Flag("since", "Only samples within duration, e.g. 24h").Default("0s").Duration()
+	plPlatform = pl.Flag("platform", "Which platform to query").Default("steam").String()
+	ac         = app.Command("achievements", "Show achievements of a game with unlock percentages.")
+	acID       = ac.Arg("id", "Game id on the platform").Required().Int()
+	acPlatform = ac.Flag("platform", "Which platform to query").Default("steam").String()
+	nw         = app.Command("news", "Show collected news and patch notes of a game.")
+	nwID       = nw.Arg("id", "Game id on the platform").Required().Int()
+	nwNum      = nw.Flag("num", "Number of news to show").Short('n').Default("10").Int()
+	nwPlatform = nw.Flag("platform", "Which platform to query").Default("steam").String()
+	lb         = app.Command("library", "Manage game libraries of users.")
+	lbImport   = lb.Command("import", "Import owned and recently played games of a steam user.")
+	lbImportID = lbImport.Flag("steamid", "64 bit steam id of the user").Required().String()
+	lbShow     = lb.Command("show", "Show imported library of a user.")
+	lbShowID   = lbShow.Flag("steamid", "64 bit steam id of the user").Required().String()
+	wl         = app.Command("wishlist", "Manage wishlists of users.")
+	wlSync     = wl.Command("sync", "Sync public wishlist of a steam user.")
+	wlSyncID   = wlSync.Flag("steamid", "64 bit steam id of the user").Required().String()
+	wlShow     = wl.Command("show", "Show synced wishlist of a user.")
+	wlShowID   = wlShow.Flag("steamid", "64 bit steam id of the user").Required().String()
+	wlRemoved  = wlShow.Flag("removed", "Also show removed items").Bool()
+	lk         = app.Command("link", "Match records of the same game across platforms.")
+	lkResolve  = lk.Command("resolve", "Match records and assign canonical game ids.")
+	lkMin      = lkResolve.Flag("min-score", "Score a match needs to link records").Default("0.8").Float64()
+	lkReview   = lkResolve.Flag("review-score", "Score a match needs to be kept for review").Default("0.5").Float64()
+	lkList     = lk.Command("list", "List matches waiting for review.")
+	lkAll      = lkList.Flag("all", "Also list confirmed and rejected matches").Bool()
+	lkConfirm  = lk.Command("confirm", "Confirm two records are the same game.")
+	lkConfirmA = lkConfirm.Arg("a", "Record as platform:id, e.g. steam:292030").Required().String()
+	lkConfirmB = lkConfirm.Arg("b", "Record as platform:id, e.g. gog:1207664643").Required().String()
+	lkReject   = lk.Command("reject", "Reject a match of two records.")
+	lkRejectA  = lkReject.Arg("a", "Record as platform:id, e.g. steam:292030").Required().String()
+	lkRejectB  = lkReject.Arg("b", "Record as platform:id, e.g. gog:1207664643").Required().String()
+	lkShow     = lk.Command("show", "Show canonical game id of a record and its linked records.")
+	lkShowRef  = lkShow.Arg("ref", "Record as platform:id, e.g. steam:292030").Required().String()
+	rc         = app.Command("recommend", "Rank games similar to a game by their records.")
+	rcLike     = rc.Flag("like", "Game id on the platform").Required().Int()
+	rcNum      = rc.Flag("num", "Number of games to show").Short('n').Default("20").Int()
+	rcRebuild  = rc.Flag("rebuild", "Rebuild similarity index of all records first").Bool()
+	rcPlatform = rc.Flag("platform", "Which platform to query").Default("steam").String()
+	ft         = app.Command("features", "Feature tables of records for model training.")
+	ftExport   = ft.Command("export", "Export feature table as csv with a schema sidecar.")
+	ftOut      = ftExport.Flag("out", "Table file path, schema is written next to it").Default("features.csv").String()
+	ftSchema   = ftExport.Flag("schema", "Schema of an earlier export to reproduce its columns").String()
+	ftVersion  = ftExport.Flag("version", "Version of feature definitions, latest if 0").Default("0").Int()
+	ftTypes    = ftExport.Flag("type", "Only apps of type, repeatable").Default("game").Strings()
+	ftRegion   = ftExport.Flag("region", "Region of prices").Default("us").String()
+	ftPlatform = ftExport.Flag("platform", "Which platform to export").Default("steam").String()
+	ln         = app.Command("learn", "Genre classifiers trained on records having genres.")
+	lnTrain    = ln.Command("train", "Train genre classifier on records of a platform.")
+	lnTrainPf  = lnTrain.Flag("platform", "Which platform to train on").Default("steam").String()
+	lnTrainMin = lnTrain.Flag("min-docs", "Records a genre needs to be learned").Default("20").Int()
+	lnTrainTy  = lnTrain.Flag("type", "Only apps of type, repeatable").Default("game").Strings()
+	lnEval     = ln.Command("eval", "Cross-validate genre classifier on records of a platform.")
+	lnEvalPf   = lnEval.Flag("platform", "Which platform to evaluate on").Default("steam").String()
+	lnFolds    = lnEval.Flag("folds", "Number of cross-validation folds").Default("5").Int()
+	lnEvalMin  = lnEval.Flag("min-docs", "Records a genre needs to be learned").Default("20").Int()
+	lnEvalTh   = lnEval.Flag("threshold", "Probability a genre needs to be predicted").Default("0.5").Float64()
+	lnEvalTy   = lnEval.Flag("type", "Only apps of type, repeatable").Default("game").Strings()
+	lnPredict  = ln.Command("predict", "Predict genres of records without genres, or of given ids.")
+	lnPredPf   = lnPredict.Flag("platform", "Which platform to predict records of").Default("steam").String()
+	lnModel    = lnPredict.Flag("model", "Platform the classifier was trained on").Default("steam").String()
+	lnIDs      = lnPredict.Flag("id", "Record id to predict, repeatable").Ints()
+	lnNum      = lnPredict.Flag("num", "Most genres to show of a record").Short('n').Default("3").Int()
+	lnPredTh   = lnPredict.Flag("threshold", "Probability a genre needs to be shown").Default("0.5").Float64()
+	lnPredTy   = lnPredict.Flag("type", "Only apps of type, repeatable").Default("game").Strings()
+	an         = app.Command("analyze", "Analyze records in store.")
+	anDupes    = an.Command("dupes", "Detect duplicate records, such as reissues, test apps and soundtracks.")
+	anPlatform = anDupes.Flag("platform", "Which platform to analyze").Default("steam").String()
+	anMin      = anDupes.Flag("min-score", "Score a pair of records needs to be grouped").Default("0.7").Float64()
+	anJSON     = anDupes.Flag("json", "Print groups with pair scores as json").Bool()
+	anClusters = an.Command("clusters", "Cluster games by their records and label clusters by distinguishing terms.")
+	anK        = anClusters.Flag("k", "Number of clusters").Short('k').Default("20").Int()
+	anIter     = anClusters.Flag("iterations", "Most k-means iterations").Default("50").Int()
+	anSeed     = anClusters.Flag("seed", "Seed of initial clusters").Default("1").Int64()
+	anTypes    = anClusters.Flag("type", "Only apps of type, repeatable").Default("game").Strings()
+	anClsPf    = anClusters.Flag("platform", "Which platform to analyze").Default("steam").String()
+	st         = app.Command("studio", "Graph of developers, publishers and their games.")
+	stShow     = st.Command("show", "Show publishers a developer worked with and developers of a publisher.")
+	stName     = stShow.Arg("name", "Studio name, any spelling").Required().String()
+	stShowPf   = stShow.Flag("platform", "Which platform to query").Default("steam").String()
+	stShowTy   = stShow.Flag("type", "Only apps of type, repeatable").Default("game").Strings()
+	stCentral  = st.Command("central", "Rank studios by centrality in the graph.")
+	stNum      = stCentral.Flag("num", "Number of studios to show, all if negative").Short('n').Default("20").Int()
+	stCentPf   = stCentral.Flag("platform", "Which platform to query").Default("steam").String()
+	stCentTy   = stCentral.Flag("type", "Only apps of type, repeatable").Default("game").Strings()
+	stExport   = st.Command("export", "Export the graph as GraphML or DOT.")
+	stFormat   = stExport.Flag("format", "Graph format").Default("graphml").Enum("graphml", "dot")
+	stOut      = stExport.Flag("out", "Graph file path, - for stdout").Default("-").String()
+	stGames    = stExport.Flag("games", "Link studios to their games instead of each other").Bool()
+	stExpPf    = stExport.Flag("platform", "Which platform to export").Default("steam").String()
+	stExpTy    = stExport.Flag("type", "Only apps of type, repeatable").Default("game").Strings()
+	rp         = app.Command("report", "Market trend report of records, prices and release dates.")
+	rpFormat   = rp.Flag("format", "Report format").Default("md").Enum("md", "html", "json")
+	rpOut      = rp.Flag("out", "Report file path, - for stdout").Default("-").String()
+	rpSince    = rp.Flag("since", "Leave out periods before month, e.g. 2020-01").String()
+	rpTop      = rp.Flag("top", "Number of genres, languages and publishers").Default("10").Int()
+	rpTypes    = rp.Flag("type", "Only apps of type, repeatable").Default("game").Strings()
+	rpRegion   = rp.Flag("region", "Region of prices").Default("us").String()
+	rpPlatform = rp.Flag("platform", "Which platform to report").Default("steam").String()
+	cl         = app.Command("calendar", "Games released or coming in a period.")
+	clFrom     = cl.Flag("from", "First day of period, e.g. 2024-11-01, today if empty").String()
+	clTo       = cl.Flag("to", "Last day of period, 90 days from first day if empty").String()
+	clICal     = cl.Flag("ical", "Also export releases as iCalendar file").String()
+	clTypes    = cl.Flag("type", "Only apps of type, repeatable").Default("game").Strings()
+	clPlatform = cl.Flag("platform", "Which platform to query").Default("steam").String()
+	al         = app.Command("alert", "Check alert rules and deliver fired alerts.")
+	alPlatform = al.Flag("platform", "Which platform to check").Default("steam").String()
 )
 
+// loadConfig reads config file and opens the store it configures
+func loadConfig(cfg string) (string, store.GameStore) {
+	config, err := ioutil.ReadFile(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return string(config), openStore(string(config))
+}
+
+// loadStore opens the store configured in config file
+func loadStore(cfg string) store.GameStore {
+	_, db := loadConfig(cfg)
+	return db
+}
+
 func openStore(confStr string) store.GameStore {
 	storeCfg, err := ParseStoreConfig(confStr)
 	if err != nil {
@@ -38,22 +204,39 @@ func openStore(confStr string) store.GameStore {
 }
 
 func startSeeker(cfg string) {
-	config, err := ioutil.ReadFile(cfg)
+	config, db := loadConfig(cfg)
+	seekerCfg, err := ParseSeekerConfig(config)
 	if err != nil {
 		log.Fatal(err)
 	}
-	db := openStore(string(config))
-	seekerCfg, err := ParseSeekerConfig(string(config))
+	ctx, cancel := interruptContext()
+	defer cancel()
+	if err := seeker.Start(ctx, seekerCfg, db); err != nil {
+		log.Fatal(err)
+	}
+	if err := checkAlerts(ctx, config, db, "steam"); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func startSampler(cfg string) {
+	config, db := loadConfig(cfg)
+	seekerCfg, err := ParseSeekerConfig(config)
 	if err != nil {
 		log.Fatal(err)
 	}
+	ctx, cancel := interruptContext()
+	defer cancel()
+	if err := seeker.StartSampler(ctx, seekerCfg, db); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// interruptContext is canceled on SIGINT
+func interruptContext() (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(context.Background())
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT)
-	defer func() {
-		signal.Stop(sigs)
-		cancel()
-	}()
 	go func() {
 		select {
 		case <-sigs:
@@ -62,16 +245,277 @@ func startSeeker(cfg string) {
 		case <-ctx.Done():
 		}
 	}()
-	log.Fatal(seeker.Start(ctx, seekerCfg, db))
+	return ctx, func() {
+		signal.Stop(sigs)
+		cancel()
+	}
 }
 
-func newQuery(cfg string, platform string) query.Querier {
-	config, err := ioutil.ReadFile(cfg)
+func importLibrary(cfg string, steamid string) {
+	config, db := loadConfig(cfg)
+	seekerCfg, err := ParseSeekerConfig(config)
 	if err != nil {
 		log.Fatal(err)
 	}
-	db := openStore(string(config))
-	return query.New(db, platform)
+	ctx, cancel := interruptContext()
+	defer cancel()
+	lib, err := seeker.ImportLibrary(ctx, seekerCfg, db, steamid)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Imported %d games of user %s.\n", len(lib.Games), steamid)
+}
+
+func syncWishlist(cfg string, steamid string) {
+	config, db := loadConfig(cfg)
+	seekerCfg, err := ParseSeekerConfig(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := interruptContext()
+	defer cancel()
+	wl, err := seeker.SyncWishlist(ctx, seekerCfg, db, steamid)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Synced wishlist of user %s.\n", steamid)
+	query.PrintWishlistChanges(wl.Changes)
+}
+
+func resolveLinks(cfg string, minScore, reviewScore float64) {
+	config, db := loadConfig(cfg)
+	storeCfg, err := ParseStoreConfig(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r := identity.New(identity.Config{
+		Platforms:   storeCfg.Buckets,
+		MinScore:    minScore,
+		ReviewScore: reviewScore,
+	}, db)
+	links, err := r.Resolve()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Resolved %d links.\n", len(links))
+}
+
+func overrideLink(cfg string, a, b string, override string) {
+	for _, ref := range []string{a, b} {
+		if _, _, err := identity.ParseRef(ref); err != nil {
+			log.Fatalf("%s: %v", ref, err)
+		}
+	}
+	if err := loadStore(cfg).SaveLinkOverride(a, b, override); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Match of %s and %s %s, applied on next link resolve.\n", a, b, override)
+}
+
+func recommendLike(cfg string, platform string, id, n int, rebuild bool) {
+	r := recommend.New(platform, loadStore(cfg))
+	if rebuild {
+		if _, err := r.Build(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	results, err := r.Like(id, n)
+	if err == recommend.ErrNotIndexed && !rebuild {
+		// game collected after the index was built, or index never built
+		if _, err := r.Build(); err != nil {
+			log.Fatal(err)
+		}
+		results, err = r.Like(id, n)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	printSimilar(results)
+}
+
+func exportFeatures(cfg string, out string, schemaPath string, fc features.Config) {
+	db := loadStore(cfg)
+	var schema *features.Schema
+	if schemaPath != "" {
+		data, err := ioutil.ReadFile(schemaPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		schema = &features.Schema{}
+		if err := json.Unmarshal(data, schema); err != nil {
+			log.Fatal(err)
+		}
+	}
+	writeOutput(out, func(w io.Writer) (err error) {
+		schema, err = features.New(fc, db).Export(w, schema)
+		return err
+	})
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	sidecar := strings.TrimSuffix(out, filepath.Ext(out)) + ".schema.json"
+	if err := ioutil.WriteFile(sidecar, data, 0644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Exported %d rows of %d features to %s, schema in %s.\n", schema.Rows, len(schema.Columns), out, sidecar)
+}
+
+func clusterGames(cfg string, cc cluster.Config) {
+	clusters, err := cluster.New(cc, loadStore(cfg)).Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	query.PrintClusters(clusters)
+}
+
+func newLearner(cfg string, lc learn.Config) *learn.Learner {
+	return learn.New(lc, loadStore(cfg))
+}
+
+func detectDupes(cfg string, platform string, minScore float64, asJSON bool) {
+	groups, err := dupes.New(dupes.Config{
+		Platform: platform,
+		MinScore: minScore,
+	}, loadStore(cfg)).Detect()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !asJSON {
+		printDupes(groups)
+		return
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(groups); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func studioGraph(cfg string, platform string, types []string) (*studio.Graph, *studio.Normalizer) {
+	config, db := loadConfig(cfg)
+	studioCfg, err := ParseStudioConfig(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	n := studio.NewNormalizer(*studioCfg)
+	g, err := studio.Build(db, platform, n, func(r store.GameRecord) bool {
+		for _, t := range types {
+			if strings.EqualFold(t, r.Type) {
+				return true
+			}
+		}
+		return len(types) == 0
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return g, n
+}
+
+// writeOutput writes to file out, or stdout if out is -. The file is closed
+// before returning, an error of closing is fatal as writes may be lost.
+func writeOutput(out string, write func(w io.Writer) error) {
+	if out == "-" {
+		if err := write(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func exportStudios(g *studio.Graph, format string, out string, games bool) {
+	writeOutput(out, func(w io.Writer) error {
+		if format == "dot" {
+			return g.WriteDOT(w, games)
+		}
+		return g.WriteGraphML(w, games)
+	})
+}
+
+func writeReport(cfg string, format string, out string, since string, rc report.Config) {
+	if rc.Top < 0 {
+		log.Fatalf("bad top: %d", rc.Top)
+	}
+	if since != "" {
+		var err error
+		if rc.Since, err = time.Parse("2006-01", since); err != nil {
+			log.Fatal(err)
+		}
+	}
+	config, db := loadConfig(cfg)
+	studioCfg, err := ParseStudioConfig(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rc.Studios = *studioCfg
+	r, err := report.New(rc, db).Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+	writeOutput(out, func(w io.Writer) error {
+		return r.Write(w, format)
+	})
+}
+
+func releaseCalendar(cfg string, from, to string, ical string, rc release.Config) {
+	var err error
+	rc.From = time.Now().UTC().Truncate(24 * time.Hour)
+	if from != "" {
+		if rc.From, err = time.Parse("2006-01-02", from); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// to is inclusive on command line
+	rc.To = rc.From.AddDate(0, 0, 90)
+	if to != "" {
+		if rc.To, err = time.Parse("2006-01-02", to); err != nil {
+			log.Fatal(err)
+		}
+		rc.To = rc.To.AddDate(0, 0, 1)
+	}
+	entries, err := release.New(rc, loadStore(cfg)).Entries()
+	if err != nil {
+		log.Fatal(err)
+	}
+	printCalendar(entries)
+	if ical == "" {
+		return
+	}
+	writeOutput(ical, func(w io.Writer) error {
+		return release.WriteICal(w, rc.Platform, entries)
+	})
+	fmt.Printf("Exported %d releases to %s.\n", len(entries), ical)
+}
+
+func checkAlerts(ctx context.Context, config string, db store.GameStore, platform string) error {
+	alertCfg, err := ParseAlertConfig(config)
+	if err != nil {
+		return err
+	}
+	if len(alertCfg.Rules) == 0 {
+		return nil
+	}
+	n, err := alert.New(*alertCfg, db)
+	if err != nil {
+		return err
+	}
+	return n.Check(ctx, platform)
+}
+
+func newQuery(cfg string, platform string) query.Querier {
+	return query.New(loadStore(cfg), platform)
 }
 
 func main() {
@@ -80,9 +524,190 @@ func main() {
 	case "seeker":
 		startSeeker(*cf)
 
+	case sp.FullCommand():
+		startSampler(*cf)
+
 		// Post message
 	case opList.FullCommand():
-		if err := newQuery(*cf, *opPlatform).GameList(); err != nil {
+		filter := query.Filter{
+			Name:      *opName,
+			Type:      *opType,
+			Interface: *opIface,
+			Audio:     *opAudio,
+			Subtitles: *opSubs,
+			Delisted:  *opDelisted,
+			Cluster:   *opCluster,
+		}
+		if err := newQuery(*cf, *opPlatform).GameList(filter); err != nil {
+			log.Fatal(err)
+		}
+
+	case opShow.FullCommand():
+		if err := newQuery(*cf, *opShowPf).GameDetail(*opShowID, *opShowLang); err != nil {
+			log.Fatal(err)
+		}
+
+	case opDLC.FullCommand():
+		if err := newQuery(*cf, *opDLCPf).DLCList(*opDLCID); err != nil {
+			log.Fatal(err)
+		}
+
+	case opClusters.FullCommand():
+		if err := newQuery(*cf, *opClsPf).Clusters(); err != nil {
+			log.Fatal(err)
+		}
+
+	case opPkg.FullCommand():
+		if err := newQuery(*cf, *opPkgPf).PackageDetail(*opPkgID); err != nil {
+			log.Fatal(err)
+		}
+
+	case pr.FullCommand():
+		if err := newQuery(*cf, *prPlatform).PriceHistory(*prID); err != nil {
+			log.Fatal(err)
+		}
+
+	case rv.FullCommand():
+		if err := newQuery(*cf, *rvPlatform).Reviews(*rvID, *rvNum); err != nil {
+			log.Fatal(err)
+		}
+
+	case pl.FullCommand():
+		if err := newQuery(*cf, *plPlatform).Players(*plID, *plSince); err != nil {
+			log.Fatal(err)
+		}
+
+	case ac.FullCommand():
+		if err := newQuery(*cf, *acPlatform).Achievements(*acID); err != nil {
+			log.Fatal(err)
+		}
+
+	case nw.FullCommand():
+		if err := newQuery(*cf, *nwPlatform).News(*nwID, *nwNum); err != nil {
+			log.Fatal(err)
+		}
+
+	case lbImport.FullCommand():
+		importLibrary(*cf, *lbImportID)
+
+	case lbShow.FullCommand():
+		if err := newQuery(*cf, "steam").Library(*lbShowID); err != nil {
+			log.Fatal(err)
+		}
+
+	case wlSync.FullCommand():
+		syncWishlist(*cf, *wlSyncID)
+
+	case wlShow.FullCommand():
+		if err := newQuery(*cf, "steam").Wishlist(*wlShowID, *wlRemoved); err != nil {
+			log.Fatal(err)
+		}
+
+	case lkResolve.FullCommand():
+		resolveLinks(*cf, *lkMin, *lkReview)
+
+	case lkList.FullCommand():
+		if err := newQuery(*cf, "").Links(*lkAll); err != nil {
+			log.Fatal(err)
+		}
+
+	case lkConfirm.FullCommand():
+		overrideLink(*cf, *lkConfirmA, *lkConfirmB, store.LinkConfirmed)
+
+	case lkReject.FullCommand():
+		overrideLink(*cf, *lkRejectA, *lkRejectB, store.LinkRejected)
+
+	case lkShow.FullCommand():
+		if err := newQuery(*cf, "").Canonical(*lkShowRef); err != nil {
+			log.Fatal(err)
+		}
+
+	case rc.FullCommand():
+		recommendLike(*cf, *rcPlatform, *rcLike, *rcNum, *rcRebuild)
+
+	case ftExport.FullCommand():
+		exportFeatures(*cf, *ftOut, *ftSchema, features.Config{
+			Platform: *ftPlatform,
+			Region:   *ftRegion,
+			Types:    *ftTypes,
+			Version:  *ftVersion,
+		})
+
+	case lnTrain.FullCommand():
+		m, err := newLearner(*cf, learn.Config{Platform: *lnTrainPf, Types: *lnTrainTy, MinDocs: *lnTrainMin}).Train()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Trained on %d records, %d genres.\n", m.Docs, len(m.Genres))
+
+	case lnEval.FullCommand():
+		report, err := newLearner(*cf, learn.Config{
+			Platform:  *lnEvalPf,
+			Types:     *lnEvalTy,
+			MinDocs:   *lnEvalMin,
+			Threshold: *lnEvalTh,
+		}).Eval(*lnFolds)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printReport(report)
+
+	case lnPredict.FullCommand():
+		preds, err := newLearner(*cf, learn.Config{
+			Platform:  *lnPredPf,
+			Types:     *lnPredTy,
+			Threshold: *lnPredTh,
+		}).Predict(*lnModel, *lnIDs, *lnNum)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printPredictions(preds)
+
+	case anDupes.FullCommand():
+		detectDupes(*cf, *anPlatform, *anMin, *anJSON)
+
+	case anClusters.FullCommand():
+		clusterGames(*cf, cluster.Config{
+			Platform:   *anClsPf,
+			Types:      *anTypes,
+			K:          *anK,
+			Iterations: *anIter,
+			Seed:       *anSeed,
+		})
+
+	case stShow.FullCommand():
+		g, n := studioGraph(*cf, *stShowPf, *stShowTy)
+		s := g.Find(n, *stName)
+		if s == nil {
+			log.Fatalf("studio %q not found", *stName)
+		}
+		printStudio(g, s)
+
+	case stCentral.FullCommand():
+		g, _ := studioGraph(*cf, *stCentPf, *stCentTy)
+		printCentral(g.Central(*stNum))
+
+	case stExport.FullCommand():
+		g, _ := studioGraph(*cf, *stExpPf, *stExpTy)
+		exportStudios(g, *stFormat, *stOut, *stGames)
+
+	case rp.FullCommand():
+		writeReport(*cf, *rpFormat, *rpOut, *rpSince, report.Config{
+			Platform: *rpPlatform,
+			Region:   *rpRegion,
+			Types:    *rpTypes,
+			Top:      *rpTop,
+		})
+
+	case cl.FullCommand():
+		releaseCalendar(*cf, *clFrom, *clTo, *clICal, release.Config{
+			Platform: *clPlatform,
+			Types:    *clTypes,
+		})
+
+	case al.FullCommand():
+		config, db := loadConfig(*cf)
+		if err := checkAlerts(context.Background(), config, db, *alPlatform); err != nil {
 			log.Fatal(err)
 		}
 	}
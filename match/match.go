@@ -0,0 +1,71 @@
+// Package match provides the similarity measures and grouping shared by
+// matching of records, across platforms or within one
+package match
+
+// MaxBlock is the largest block of records paired, larger blocks of common
+// names or boilerplate descriptions are too costly to pair
+const MaxBlock = 200
+
+// Jaccard similarity of two sets, 0 if either is empty
+func Jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	n := 0
+	for k := range a {
+		if b[k] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(a)+len(b)-n)
+}
+
+// Overlap coefficient of two sets, 0 if either is empty. Companies credited
+// on one record are often a subset of the ones of another.
+func Overlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	n := 0
+	for k := range a {
+		if b[k] {
+			n++
+		}
+	}
+	if len(a) < len(b) {
+		return float64(n) / float64(len(a))
+	}
+	return float64(n) / float64(len(b))
+}
+
+// Groups connects keys into groups, each named by its root key
+type Groups struct {
+	parent map[string]string
+}
+
+// NewGroups creates groups of single keys
+func NewGroups() *Groups {
+	return &Groups{parent: make(map[string]string)}
+}
+
+// Find returns the root of the group of key
+func (g *Groups) Find(key string) string {
+	p, ok := g.parent[key]
+	if !ok || p == key {
+		return key
+	}
+	root := g.Find(p)
+	g.parent[key] = root
+	return root
+}
+
+// Union joins the group of a into the group of b, the root of b stays root.
+// It reports if a and b were in different groups.
+func (g *Groups) Union(a, b string) bool {
+	ra, rb := g.Find(a), g.Find(b)
+	if ra == rb {
+		return false
+	}
+	g.parent[ra] = rb
+	return true
+}
@@ -0,0 +1,57 @@
+package match
+
+import (
+	"testing"
+)
+
+func set(keys ...string) map[string]bool {
+	ret := make(map[string]bool)
+	for _, k := range keys {
+		ret[k] = true
+	}
+	return ret
+}
+
+func TestJaccardOverlap(t *testing.T) {
+	var tests = []struct {
+		a, b    map[string]bool
+		jaccard float64
+		overlap float64
+	}{
+		{set("witcher", "3", "wild", "hunt"), set("witcher", "3"), 0.5, 1},
+		{set("valve"), set("valve", "hidden path"), 0.5, 1},
+		{set("a", "b"), set("c"), 0, 0},
+		{set(), set("a"), 0, 0},
+	}
+	for caseid, c := range tests {
+		if j, o := Jaccard(c.a, c.b), Overlap(c.a, c.b); j != c.jaccard || o != c.overlap {
+			t.Errorf("case #%d, got: %v %v, expected: %v %v", caseid+1, j, o, c.jaccard, c.overlap)
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+	g := NewGroups()
+	var tests = []struct {
+		a, b   string
+		joined bool
+	}{
+		{"a", "b", true},
+		{"c", "d", true},
+		{"b", "d", true},
+		{"a", "c", false},
+	}
+	for caseid, c := range tests {
+		if joined := g.Union(c.a, c.b); joined != c.joined {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, joined, c.joined)
+		}
+	}
+	for _, k := range []string{"a", "b", "c", "d"} {
+		if root := g.Find(k); root != "d" {
+			t.Errorf("got root of %s: %s, expected: d", k, root)
+		}
+	}
+	if root := g.Find("e"); root != "e" {
+		t.Errorf("got root of e: %s, expected: e", root)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ksang/gamecha/dupes"
+	"github.com/ksang/gamecha/learn"
+	"github.com/ksang/gamecha/recommend"
+	"github.com/ksang/gamecha/release"
+	"github.com/ksang/gamecha/studio"
+)
+
+// printDupes prints duplicate groups, a record per line under its group
+func printDupes(groups []dupes.Group) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i, g := range groups {
+		fmt.Fprintf(w, "#%d %s\t\t\n", i+1, g.Kind)
+		for _, r := range g.Records {
+			fmt.Fprintf(w, "  %d\t%s\t%s\n", r.ID, r.Name, strings.Join(r.Developers, ", "))
+		}
+	}
+	w.Flush()
+	fmt.Printf("%d duplicate groups.\n", len(groups))
+}
+
+// printSimilar prints games ranked similar with terms weighing most in their scores
+func printSimilar(results []recommend.Result) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCORE\tID\tNAME\tSHARED")
+	for _, r := range results {
+		fmt.Fprintf(w, "%.3f\t%d\t%s\t%s\n", r.Score, r.ID, r.Name, strings.Join(r.Reasons, ", "))
+	}
+	w.Flush()
+}
+
+// printReport prints cross-validation metrics of a genre classifier
+func printReport(r *learn.Report) {
+	fmt.Printf("%d-fold cross-validation of %d records\n", r.Folds, r.Docs)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GENRE\tSUPPORT\tPRECISION\tRECALL\tF1")
+	for _, m := range r.Genres {
+		fmt.Fprintf(w, "%s\t%d\t%.3f\t%.3f\t%.3f\n", m.Genre, m.Support, m.Precision, m.Recall, m.F1)
+	}
+	fmt.Fprintf(w, "micro avg\t\t%.3f\t%.3f\t%.3f\n", r.MicroPrecision, r.MicroRecall, r.MicroF1)
+	fmt.Fprintf(w, "macro avg\t\t\t\t%.3f\n", r.MacroF1)
+	w.Flush()
+}
+
+// printPredictions prints genres predicted for records with their probabilities
+func printPredictions(preds []learn.RecordPrediction) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREDICTED\tGENRES")
+	for _, p := range preds {
+		var ps []string
+		for _, g := range p.Predictions {
+			ps = append(ps, fmt.Sprintf("%s %.2f", g.Genre, g.Probability))
+		}
+		genres := "-"
+		if len(p.Genres) > 0 {
+			genres = strings.Join(p.Genres, ", ")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", p.ID, p.Name, strings.Join(ps, ", "), genres)
+	}
+	w.Flush()
+}
+
+// printCalendar prints releases of a calendar, imprecise dates as told
+func printCalendar(entries []release.Entry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tPRECISION\tID\tNAME\tSTATUS")
+	for _, e := range entries {
+		status := "released"
+		if e.Release.ComingSoon {
+			status = "coming soon"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", e.Release, e.Release.Precision, e.ID, e.Name, status)
+	}
+	w.Flush()
+}
+
+// printStudio prints a studio of graph with the publishers of games it developed
+// and the developers of games it published
+func printStudio(g *studio.Graph, s *studio.Studio) {
+	fmt.Printf("Studio:\t\t%s (%s)\n", s.Name, s.Key)
+	fmt.Printf("Developed:\t%d games\n", len(s.Developed))
+	fmt.Printf("Published:\t%d games\n", len(s.Published))
+	printPartners("PUBLISHER", g.Publishers(s))
+	printPartners("DEVELOPER", g.Developers(s))
+}
+
+func printPartners(role string, partners []studio.Partner) {
+	if len(partners) == 0 {
+		return
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tGAMES\n", role)
+	for _, p := range partners {
+		fmt.Fprintf(w, "%s\t%d\n", p.Studio.Name, p.Games)
+	}
+	w.Flush()
+}
+
+// printCentral prints studios ranked by centrality
+func printCentral(central []studio.Centrality) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STUDIO\tPAGERANK\tPARTNERS\tGAMES\tDEVELOPED\tPUBLISHED")
+	for _, c := range central {
+		fmt.Fprintf(w, "%s\t%.4f\t%d\t%d\t%d\t%d\n", c.Studio.Name, c.PageRank, c.Degree, c.Games,
+			len(c.Studio.Developed), len(c.Studio.Published))
+	}
+	w.Flush()
+}
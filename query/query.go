@@ -3,12 +3,35 @@ package query
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/ksang/gamecha/identity"
+	"github.com/ksang/gamecha/release"
+	"github.com/ksang/gamecha/sanitize"
 	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/title"
 )
 
 type Querier interface {
-	GameList() error
+	GameList(filter Filter) error
+	PriceHistory(id int) error
+	GameDetail(id int, lang string) error
+	DLCList(id int) error
+	PackageDetail(id int) error
+	Reviews(id int, n int) error
+	Players(id int, since time.Duration) error
+	Achievements(id int) error
+	News(id int, n int) error
+	Library(userid string) error
+	Wishlist(userid string, removed bool) error
+	Links(all bool) error
+	Canonical(ref string) error
+	Clusters() error
 }
 
 func New(db store.GameStore, platform string) Querier {
@@ -23,7 +46,69 @@ type operator struct {
 	platform string
 }
 
-func (o *operator) GameList() error {
+// Filter selects games by their stored records, zero value selects all games.
+// Language filters take ISO 639-1 codes, e.g. ja or zh-CN.
+type Filter struct {
+	// Name selects games whose normalized title has all words of it
+	Name      string
+	Type      string
+	Interface string
+	Audio     string
+	Subtitles string
+	// Delisted selects only games gone from the platform
+	Delisted bool
+	// Cluster selects games of a cluster found by clustering analysis
+	Cluster int
+}
+
+func (f Filter) empty() bool {
+	return f == Filter{}
+}
+
+func (f Filter) match(r store.GameRecord) bool {
+	if f.Name != "" && !matchName(f.Name, r.Name) {
+		return false
+	}
+	if f.Type != "" && !strings.EqualFold(f.Type, r.Type) {
+		return false
+	}
+	if f.Interface != "" && !r.SupportsLanguage(f.Interface, "interface") {
+		return false
+	}
+	if f.Audio != "" && !r.SupportsLanguage(f.Audio, "audio") {
+		return false
+	}
+	if f.Subtitles != "" && !r.SupportsLanguage(f.Subtitles, "subtitles") {
+		return false
+	}
+	if f.Delisted && !r.Delisted {
+		return false
+	}
+	if f.Cluster != 0 && f.Cluster != r.Cluster {
+		return false
+	}
+	return true
+}
+
+// matchName reports whether every word of the normalized query is in
+// the normalized name, so editions, numerals and punctuation do not matter
+func matchName(query, name string) bool {
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(title.Key(name)) {
+		words[w] = true
+	}
+	for _, w := range strings.Fields(title.Key(query)) {
+		if !words[w] {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *operator) GameList(filter Filter) error {
+	if !filter.empty() {
+		return o.filteredGameList(filter)
+	}
 	res, err := o.db.GetGameList(o.platform)
 	if err != nil {
 		return err
@@ -34,3 +119,482 @@ func (o *operator) GameList() error {
 	fmt.Printf("Total %d games.\n", len(res))
 	return nil
 }
+
+func (o *operator) filteredGameList(filter Filter) error {
+	c := 0
+	if err := o.db.ForEachGameRecord(o.platform, func(subid string, r store.GameRecord) error {
+		if !filter.match(r) {
+			return nil
+		}
+		if r.Delisted && !r.LastSeen.IsZero() {
+			fmt.Printf("%s %s (delisted, seen %s to %s)\n", subid, r.Name,
+				r.FirstSeen.Format("2006-01-02"), r.LastSeen.Format("2006-01-02"))
+		} else if r.Delisted {
+			fmt.Printf("%s %s (delisted)\n", subid, r.Name)
+		} else {
+			fmt.Printf("%s %s\n", subid, r.Name)
+		}
+		c++
+		return nil
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("Total %d games.\n", c)
+	return nil
+}
+
+func (o *operator) PriceHistory(id int) error {
+	history, err := o.db.GetPriceHistory(o.platform, id)
+	if err != nil {
+		return err
+	}
+	var regions []string
+	for r := range history {
+		regions = append(regions, r)
+	}
+	sort.Strings(regions)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REGION\tTIME\tCURRENCY\tINITIAL\tFINAL\tDISCOUNT")
+	for _, r := range regions {
+		for _, p := range history[r] {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%.2f\t%d%%\n", r, p.Time.Format("2006-01-02 15:04"),
+				p.Currency, float64(p.Initial)/100, float64(p.Final)/100, p.Discount)
+		}
+	}
+	w.Flush()
+	if len(regions) == 0 {
+		fmt.Printf("No price history of %s game %d.\n", o.platform, id)
+	}
+	return nil
+}
+
+// GameDetail prints a game record, texts are shown in lang if it was collected
+func (o *operator) GameDetail(id int, lang string) error {
+	r, err := o.db.GetGameRecord(o.platform, strconv.Itoa(id))
+	if err != nil {
+		return err
+	}
+	if r.ID == 0 {
+		fmt.Printf("No %s game %d in store.\n", o.platform, id)
+		return nil
+	}
+	text := store.LocalizedText{
+		Name:             r.Name,
+		Description:      r.Description,
+		About:            r.About,
+		ShortDescription: r.ShortDescription,
+		DescriptionText:  r.DescriptionText,
+	}
+	if lang != "" {
+		if l, ok := r.Localized[lang]; ok {
+			text = l
+		} else {
+			fmt.Printf("No %s texts collected, showing default language.\n", lang)
+		}
+	}
+	fmt.Printf("Name: %s\n", text.Name)
+	fmt.Printf("ID: %d\n", r.ID)
+	fmt.Printf("Type: %s\n", r.Type)
+	fmt.Printf("Required age: %d\n", r.RequiredAge)
+	fmt.Printf("Developers: %s\n", strings.Join(r.Developers, ", "))
+	fmt.Printf("Publishers: %s\n", strings.Join(r.Publishers, ", "))
+	if d := release.Of(*r); d.ComingSoon {
+		fmt.Printf("Release date: %s (coming soon)\n", d)
+	} else {
+		fmt.Printf("Release date: %s\n", d)
+	}
+	fmt.Printf("Languages: %s\n", r.Languages)
+	if len(r.Genres) > 0 {
+		fmt.Printf("Genres: %s\n", strings.Join(r.Genres, ", "))
+	}
+	if len(r.Categories) > 0 {
+		fmt.Printf("Categories: %s\n", strings.Join(r.Categories, ", "))
+	}
+	if r.Cluster != 0 {
+		fmt.Printf("Cluster: %s\n", o.clusterName(r.Cluster))
+	}
+	for _, platform := range []string{"pc", "mac", "linux"} {
+		if req, ok := r.Requirements[platform]; ok {
+			fmt.Printf("Requirements %s minimum: %s\n", platform, formatSpec(req.Minimum))
+			fmt.Printf("Requirements %s recommended: %s\n", platform, formatSpec(req.Recommended))
+		}
+	}
+	if r.FullGame != 0 {
+		fmt.Printf("Full game: %d\n", r.FullGame)
+	}
+	fmt.Printf("DLC: %d\n", len(r.DLC))
+	for _, g := range r.PackageGroups {
+		for _, p := range g.Options {
+			fmt.Printf("Package %d: %s\n", p.PackageID, p.Text)
+		}
+	}
+	fmt.Printf("Summary: %s\n", text.ShortDescription)
+	desc := text.DescriptionText
+	if desc == "" {
+		// records collected before texts were cleaned
+		desc = sanitize.Text(text.Description)
+	}
+	fmt.Printf("Description:\n%s\n", desc)
+	return nil
+}
+
+// DLCList prints DLCs of a game, or the base game if id is a DLC
+func (o *operator) DLCList(id int) error {
+	r, err := o.db.GetGameRecord(o.platform, strconv.Itoa(id))
+	if err != nil {
+		return err
+	}
+	if r.ID == 0 {
+		fmt.Printf("No %s game %d in store.\n", o.platform, id)
+		return nil
+	}
+	if r.FullGame != 0 {
+		fmt.Printf("%d %s is content of %s\n", r.ID, r.Name, o.gameName(r.FullGame))
+	}
+	for _, d := range r.DLC {
+		fmt.Println(o.gameName(d))
+	}
+	fmt.Printf("Total %d DLC.\n", len(r.DLC))
+	return nil
+}
+
+// PackageDetail prints the apps bundled in a package
+func (o *operator) PackageDetail(id int) error {
+	p, err := o.db.GetPackageRecord(o.platform, id)
+	if err != nil {
+		return err
+	}
+	if p.ID == 0 {
+		fmt.Printf("No %s package %d in store.\n", o.platform, id)
+		return nil
+	}
+	fmt.Printf("Package %d: %s, %.2f %s\n", p.ID, p.Name, float64(p.Price.Final)/100, p.Price.Currency)
+	for _, a := range p.Apps {
+		fmt.Printf("%d %s\n", a.ID, a.Name)
+	}
+	fmt.Printf("Total %d apps.\n", len(p.Apps))
+	return nil
+}
+
+// Reviews prints review summary and n latest updated reviews of a game
+func (o *operator) Reviews(id int, n int) error {
+	rs, err := o.db.GetReviews(o.platform, id)
+	if err != nil {
+		return err
+	}
+	if rs.Summary.Updated.IsZero() {
+		fmt.Printf("No reviews of %s game %d collected.\n", o.platform, id)
+		return nil
+	}
+	s := rs.Summary
+	fmt.Printf("%s: %d positive, %d negative of %d reviews (updated %s)\n", s.Description,
+		s.TotalPositive, s.TotalNegative, s.TotalReviews, s.Updated.Format("2006-01-02 15:04"))
+	for i, r := range rs.Reviews {
+		if i >= n {
+			break
+		}
+		vote := "Not Recommended"
+		if r.VotedUp {
+			vote = "Recommended"
+		}
+		fmt.Printf("\n%s, %s, %s, %.1fh at review, %d helpful\n%s\n", r.Created.Format("2006-01-02"),
+			vote, r.Language, float64(r.PlaytimeAtReview)/60, r.VotesUp, r.Text)
+	}
+	fmt.Printf("\nTotal %d reviews collected.\n", len(rs.Reviews))
+	return nil
+}
+
+// Players prints concurrent player samples of a game taken within since,
+// zero since prints all samples
+func (o *operator) Players(id int, since time.Duration) error {
+	samples, err := o.db.GetPlayerSamples(o.platform, id)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		fmt.Printf("No players of %s game %d sampled.\n", o.platform, id)
+		return nil
+	}
+	fmt.Printf("Players of %s:\n", o.gameName(id))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tSPAN\tPLAYERS\tMIN\tMAX")
+	c := 0
+	for _, s := range samples {
+		if since > 0 && s.Time.Add(s.Span).Before(time.Now().Add(-since)) {
+			continue
+		}
+		if s.Span == 0 {
+			fmt.Fprintf(w, "%s\t-\t%d\t-\t-\n", s.Time.Format("2006-01-02 15:04"), s.Count)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\n", s.Time.Format("2006-01-02 15:04"), s.Span, s.Count, s.Min, s.Max)
+		}
+		c++
+	}
+	w.Flush()
+	fmt.Printf("Total %d samples.\n", c)
+	return nil
+}
+
+// Achievements prints achievements of a game, rarest first
+func (o *operator) Achievements(id int) error {
+	as, err := o.db.GetAchievements(o.platform, id)
+	if err != nil {
+		return err
+	}
+	if as.Updated.IsZero() {
+		fmt.Printf("No achievements of %s game %d collected.\n", o.platform, id)
+		return nil
+	}
+	achievements := append([]store.Achievement(nil), as.Achievements...)
+	sort.SliceStable(achievements, func(i, j int) bool {
+		return achievements[i].Percent < achievements[j].Percent
+	})
+	fmt.Printf("Achievements of %s (updated %s):\n", o.gameName(id), as.Updated.Format("2006-01-02 15:04"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "UNLOCKED\tNAME\tDESCRIPTION")
+	for _, a := range achievements {
+		desc := a.Description
+		if a.Hidden && desc == "" {
+			desc = "(hidden)"
+		}
+		fmt.Fprintf(w, "%.1f%%\t%s\t%s\n", a.Percent, a.DisplayName, desc)
+	}
+	w.Flush()
+	fmt.Printf("Total %d achievements.\n", len(achievements))
+	return nil
+}
+
+// News prints latest n news of a game and how often news are published
+func (o *operator) News(id int, n int) error {
+	ns, err := o.db.GetNews(o.platform, id)
+	if err != nil {
+		return err
+	}
+	if ns.Updated.IsZero() {
+		fmt.Printf("No news of %s game %d collected.\n", o.platform, id)
+		return nil
+	}
+	fmt.Printf("News of %s (updated %s):\n", o.gameName(id), ns.Updated.Format("2006-01-02 15:04"))
+	for i, item := range ns.Items {
+		if i >= n {
+			break
+		}
+		fmt.Printf("\n%s, %s, %s by %s\n%s\n%s\n", item.Date.Format("2006-01-02"), item.FeedLabel,
+			item.Title, item.Author, item.URL, item.Contents)
+	}
+	if len(ns.Items) > 0 {
+		latest := ns.Items[0].Date
+		fmt.Printf("\nLatest news %d days ago", int(time.Since(latest).Hours()/24))
+		if len(ns.Items) > 1 {
+			span := latest.Sub(ns.Items[len(ns.Items)-1].Date)
+			fmt.Printf(", one every %.1f days on average", span.Hours()/24/float64(len(ns.Items)-1))
+		}
+		fmt.Println(".")
+	}
+	fmt.Printf("Total %d news collected.\n", len(ns.Items))
+	return nil
+}
+
+// Library prints imported games of a user by playtime, with their types
+// from linked game records
+func (o *operator) Library(userid string) error {
+	lib, err := o.db.GetLibrary(o.platform, userid)
+	if err != nil {
+		return err
+	}
+	if lib.Updated.IsZero() {
+		fmt.Printf("No %s library of user %s imported.\n", o.platform, userid)
+		return nil
+	}
+	games := append([]store.LibraryEntry(nil), lib.Games...)
+	sort.SliceStable(games, func(i, j int) bool {
+		return games[i].PlaytimeForever > games[j].PlaytimeForever
+	})
+	fmt.Printf("Library of %s (imported %s):\n", userid, lib.Updated.Format("2006-01-02 15:04"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTYPE\tHOURS\tRECENT")
+	for _, g := range games {
+		typ := "-"
+		r, err := o.db.GetGameRecord(o.platform, strconv.Itoa(g.ID))
+		if err != nil {
+			return err
+		}
+		if r.ID != 0 {
+			typ = r.Type
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%.1f\t%.1f\n", g.ID, g.Name, typ,
+			float64(g.PlaytimeForever)/60, float64(g.PlaytimeRecent)/60)
+	}
+	w.Flush()
+	fmt.Printf("Total %d games.\n", len(games))
+	return nil
+}
+
+// Wishlist prints wished games of a user and changes noticed at last sync,
+// removed items are printed only if removed is set
+func (o *operator) Wishlist(userid string, removed bool) error {
+	wl, err := o.db.GetWishlist(o.platform, userid)
+	if err != nil {
+		return err
+	}
+	if wl.Synced.IsZero() {
+		fmt.Printf("No %s wishlist of user %s synced.\n", o.platform, userid)
+		return nil
+	}
+	fmt.Printf("Wishlist of %s (synced %s):\n", userid, wl.Synced.Format("2006-01-02 15:04"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tADDED\tREMOVED\tDISCOUNT\tEARLY ACCESS")
+	c := 0
+	for _, item := range wl.Items {
+		if !item.Removed.IsZero() && !removed {
+			continue
+		}
+		rm := "-"
+		if !item.Removed.IsZero() {
+			rm = item.Removed.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d%%\t%v\n", item.ID, item.Name,
+			item.Added.Format("2006-01-02"), rm, item.Discount, item.EarlyAccess)
+		c++
+	}
+	w.Flush()
+	fmt.Printf("Total %d items.\n", c)
+	PrintWishlistChanges(wl.Changes)
+	return nil
+}
+
+// PrintWishlistChanges prints changes of wishlist items noticed at sync
+func PrintWishlistChanges(changes []store.WishlistChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Println("\nChanges since previous sync:")
+	for _, c := range changes {
+		fmt.Printf("%d %s: %s\n", c.ID, c.Name, c.Kind)
+	}
+}
+
+// Links prints matches of records across platforms by score, matches
+// already confirmed or rejected are printed only if all is set
+func (o *operator) Links(all bool) error {
+	links, err := o.db.GetLinks()
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].Score > links[j].Score
+	})
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCORE\tA\tB\tOVERRIDE")
+	c := 0
+	for _, l := range links {
+		if l.Override != "" && !all {
+			continue
+		}
+		override := l.Override
+		if override == "" {
+			override = "-"
+		}
+		fmt.Fprintf(w, "%.2f\t%s\t%s\t%s\n", l.Score, o.refName(l.A), o.refName(l.B), override)
+		c++
+	}
+	w.Flush()
+	fmt.Printf("Total %d links.\n", c)
+	return nil
+}
+
+// Canonical prints canonical game id of a record and records sharing it
+func (o *operator) Canonical(ref string) error {
+	canonical, err := o.db.GetCanonicalIDs()
+	if err != nil {
+		return err
+	}
+	id, ok := canonical[ref]
+	if !ok {
+		fmt.Printf("Record %s is not resolved.\n", ref)
+		return nil
+	}
+	var refs []string
+	for r, cid := range canonical {
+		if cid == id {
+			refs = append(refs, r)
+		}
+	}
+	sort.Strings(refs)
+	fmt.Printf("Canonical id: %s\n", id)
+	for _, r := range refs {
+		fmt.Println(o.refName(r))
+	}
+	return nil
+}
+
+// Clusters prints clusters found by the latest clustering analysis
+func (o *operator) Clusters() error {
+	clusters, err := o.db.GetClusters(o.platform)
+	if err != nil {
+		return err
+	}
+	if len(clusters) == 0 {
+		fmt.Printf("No clusters of %s games, run analyze clusters first.\n", o.platform)
+		return nil
+	}
+	PrintClusters(clusters)
+	return nil
+}
+
+// PrintClusters prints clusters with their sizes and distinguishing terms
+func PrintClusters(clusters []store.Cluster) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSIZE\tLABEL\tTERMS")
+	for _, c := range clusters {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\n", c.ID, c.Size, c.Label, strings.Join(c.Terms, ", "))
+	}
+	w.Flush()
+}
+
+// clusterName formats id with label of cluster if it is in store
+func (o *operator) clusterName(id int) string {
+	clusters, err := o.db.GetClusters(o.platform)
+	if err == nil {
+		for _, c := range clusters {
+			if c.ID == id {
+				return fmt.Sprintf("%d %s", id, c.Label)
+			}
+		}
+	}
+	return strconv.Itoa(id)
+}
+
+// refName formats a platform:id reference with name of the record if it is in store
+func (o *operator) refName(ref string) string {
+	platform, id, err := identity.ParseRef(ref)
+	if err != nil {
+		return ref
+	}
+	r, err := o.db.GetGameRecord(platform, strconv.Itoa(id))
+	if err != nil || r.ID == 0 {
+		return ref + " (not collected)"
+	}
+	return ref + " " + r.Name
+}
+
+// gameName formats id with name of game if it is in store
+func (o *operator) gameName(id int) string {
+	r, err := o.db.GetGameRecord(o.platform, strconv.Itoa(id))
+	if err != nil || r.ID == 0 {
+		return fmt.Sprintf("%d (not collected)", id)
+	}
+	return fmt.Sprintf("%d %s", id, r.Name)
+}
+
+func formatSpec(s store.HardwareSpec) string {
+	if s.Raw == "" {
+		return "-"
+	}
+	ret := fmt.Sprintf("OS: %s, CPU: %s, RAM: %dMB, GPU: %s, VRAM: %dMB, DirectX: %s, OpenGL: %s, Disk: %dMB",
+		s.OS, s.CPU, s.RAM, s.GPU, s.VRAM, s.DirectX, s.OpenGL, s.Disk)
+	if len(s.Unparsed) > 0 {
+		ret += " (unparsed: " + strings.Join(s.Unparsed, ", ") + ")"
+	}
+	return ret
+}
@@ -1 +1,45 @@
 package query
+
+import (
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+)
+
+func TestFilterMatch(t *testing.T) {
+	r := store.GameRecord{
+		Name:    "Counter-Strike",
+		Type:    "game",
+		Cluster: 3,
+		LanguageSupport: []store.LanguageSupport{
+			{Code: "en", Interface: true, FullAudio: true, Subtitles: true},
+			{Code: "ja", Interface: true, Subtitles: true},
+		},
+	}
+	var tests = []struct {
+		f     Filter
+		match bool
+	}{
+		{Filter{}, true},
+		{Filter{Audio: "en"}, true},
+		{Filter{Audio: "ja"}, false},
+		{Filter{Interface: "ja", Subtitles: "ja"}, true},
+		{Filter{Interface: "ja", Audio: "ja"}, false},
+		{Filter{Subtitles: "de"}, false},
+		{Filter{Type: "Game", Audio: "en"}, true},
+		{Filter{Type: "dlc"}, false},
+		{Filter{Delisted: true}, false},
+		{Filter{Name: "counter strike"}, true},
+		{Filter{Name: "COUNTER-STRIKE™"}, true},
+		{Filter{Name: "strike", Type: "game"}, true},
+		{Filter{Name: "counter strike 2"}, false},
+		{Filter{Cluster: 3}, true},
+		{Filter{Cluster: 1}, false},
+	}
+
+	for caseid, c := range tests {
+		if m := c.f.match(r); m != c.match {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, m, c.match)
+		}
+	}
+}
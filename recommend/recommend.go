@@ -0,0 +1,313 @@
+// Package recommend ranks games similar to a game by their stored records,
+// using tf-idf vectors of descriptions, genres, categories and companies.
+// User tags are not features, steam appdetails does not serve them.
+package recommend
+
+import (
+	"errors"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/studio"
+)
+
+// ErrNotIndexed indicates the game liked is not in the similarity index
+var ErrNotIndexed = errors.New("game is not in similarity index")
+
+// fields of feature vectors with their weights, similarity of two records
+// is the weighted sum of similarities of their fields
+var fields = []struct {
+	prefix string
+	weight float64
+}{
+	{"word:", 0.5},
+	{"genre:", 0.2},
+	{"category:", 0.1},
+	{"developer:", 0.1},
+	{"publisher:", 0.1},
+}
+
+const (
+	// maxWords is the description words kept in a vector, by weight
+	maxWords = 64
+	// minWordDF leaves out words of a single description, they match nothing
+	minWordDF = 2
+	// maxWordRatio leaves out words of more than this part of descriptions
+	maxWordRatio = 0.5
+	// minWordLen leaves out short words, mostly stop words
+	minWordLen = 3
+)
+
+// stopWords are common words left out of description features
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "you": true, "your": true,
+	"are": true, "can": true, "this": true, "that": true, "from": true, "will": true,
+	"all": true, "its": true, "has": true, "have": true, "their": true, "into": true,
+	"our": true, "out": true, "more": true, "new": true, "who": true, "but": true,
+	"not": true, "each": true, "they": true, "them": true, "one": true, "own": true,
+}
+
+// Result is a game ranked similar, Reasons are the terms weighing most in its score
+type Result struct {
+	ID      int
+	Name    string
+	Score   float64
+	Reasons []string
+}
+
+// Recommender builds the similarity index of a platform and queries it
+type Recommender struct {
+	platform string
+	store    store.GameStore
+	debugLog *log.Logger
+	infoLog  *log.Logger
+}
+
+// New creates a recommender of records of a platform
+func New(platform string, db store.GameStore) *Recommender {
+	return &Recommender{
+		platform: platform,
+		store:    db,
+		debugLog: log.New(os.Stdout, "Recommend DEBUG:", log.LstdFlags|log.Lshortfile),
+		infoLog:  log.New(os.Stdout, "Recommend INFO:", log.LstdFlags|log.Lshortfile),
+	}
+}
+
+// Words splits a text into lowercase words, leaving out stop words,
+// numbers and words shorter than minWordLen
+func Words(text string) []string {
+	var ret []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		if len(w) >= minWordLen && !stopWords[w] && strings.TrimFunc(w, unicode.IsNumber) != "" {
+			ret = append(ret, w)
+		}
+	}
+	return ret
+}
+
+// features counts terms of a record, prefixed by their field
+func features(r store.GameRecord) map[string]int {
+	ret := make(map[string]int)
+	text := r.DescriptionText
+	if text == "" {
+		text = r.AboutText
+	}
+	if text == "" {
+		text = r.ShortDescription
+	}
+	for _, w := range Words(text) {
+		ret["word:"+w]++
+	}
+	for _, g := range r.Genres {
+		ret["genre:"+strings.ToLower(g)] = 1
+	}
+	for _, c := range r.Categories {
+		ret["category:"+strings.ToLower(c)] = 1
+	}
+	for _, d := range r.Developers {
+		if k := studio.Key(d); k != "" {
+			ret["developer:"+k] = 1
+		}
+	}
+	for _, p := range r.Publishers {
+		if k := studio.Key(p); k != "" {
+			ret["publisher:"+k] = 1
+		}
+	}
+	return ret
+}
+
+// vector weighs terms of a record by tf-idf, terms of each field are scaled
+// to the field weight and the vector is normalized to unit length
+func vector(terms map[string]int, df map[string]int, n int) map[string]float64 {
+	byField := make(map[string]map[string]float64)
+	for t, c := range terms {
+		d, ok := df[t]
+		if !ok {
+			continue
+		}
+		f := t[:strings.Index(t, ":")+1]
+		if byField[f] == nil {
+			byField[f] = make(map[string]float64)
+		}
+		byField[f][t] = (1 + math.Log(float64(c))) * (1 + math.Log(float64(1+n)/float64(1+d)))
+	}
+	if words := byField["word:"]; len(words) > maxWords {
+		ts := make([]string, 0, len(words))
+		for t := range words {
+			ts = append(ts, t)
+		}
+		sort.Slice(ts, func(i, j int) bool {
+			if words[ts[i]] != words[ts[j]] {
+				return words[ts[i]] > words[ts[j]]
+			}
+			return ts[i] < ts[j]
+		})
+		for _, t := range ts[maxWords:] {
+			delete(words, t)
+		}
+	}
+	ret := make(map[string]float64)
+	for _, f := range fields {
+		ws := byField[f.prefix]
+		norm := 0.0
+		for _, w := range ws {
+			norm += w * w
+		}
+		for t, w := range ws {
+			ret[t] = w / math.Sqrt(norm) * math.Sqrt(f.weight)
+		}
+	}
+	norm := 0.0
+	for _, w := range ret {
+		norm += w * w
+	}
+	for t := range ret {
+		ret[t] /= math.Sqrt(norm)
+	}
+	return ret
+}
+
+// Vectors weighs features of records of a platform kept by keep, all records
+// if keep is nil. Records with no features are left out.
+func Vectors(db store.GameStore, platform string, keep func(r store.GameRecord) bool) (map[int]map[string]float64, error) {
+	df := make(map[string]int)
+	n := 0
+	if err := db.ForEachGameRecord(platform, func(subid string, gr store.GameRecord) error {
+		if keep != nil && !keep(gr) {
+			return nil
+		}
+		for t := range features(gr) {
+			df[t]++
+		}
+		n++
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for t, d := range df {
+		if strings.HasPrefix(t, "word:") && (d < minWordDF || float64(d) > maxWordRatio*float64(n)) {
+			delete(df, t)
+		}
+	}
+	vectors := make(map[int]map[string]float64)
+	if err := db.ForEachGameRecord(platform, func(subid string, gr store.GameRecord) error {
+		if keep != nil && !keep(gr) {
+			return nil
+		}
+		if v := vector(features(gr), df, n); len(v) > 0 {
+			vectors[gr.ID] = v
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// Build indexes vectors of all records of the platform, replacing the saved
+// index, and returns the number of records indexed
+func (r *Recommender) Build() (int, error) {
+	vectors, err := Vectors(r.store, r.platform, nil)
+	if err != nil {
+		return 0, err
+	}
+	postings := make(map[string][]store.Posting)
+	for id, v := range vectors {
+		for t, w := range v {
+			postings[t] = append(postings[t], store.Posting{ID: id, Weight: w})
+		}
+	}
+	r.infoLog.Printf("indexed %d %s records, %d terms", len(vectors), r.platform, len(postings))
+	return len(vectors), r.store.SaveSimilarityIndex(r.platform, vectors, postings)
+}
+
+// Like ranks n games most similar to the game of id by cosine similarity.
+// Only games of the same type are ranked, DLC and soundtracks of a game are
+// left out of its results.
+func (r *Recommender) Like(id, n int) ([]Result, error) {
+	v, err := r.store.GetSimilarityVector(r.platform, id)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, ErrNotIndexed
+	}
+	terms := make([]string, 0, len(v))
+	for t := range v {
+		terms = append(terms, t)
+	}
+	postings, err := r.store.GetPostings(r.platform, terms)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[int]float64)
+	for t, ps := range postings {
+		for _, p := range ps {
+			scores[p.ID] += v[t] * p.Weight
+		}
+	}
+	delete(scores, id)
+	ids := make([]int, 0, len(scores))
+	for i := range scores {
+		ids = append(ids, i)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	liked, err := r.store.GetGameRecord(r.platform, strconv.Itoa(id))
+	if err != nil {
+		return nil, err
+	}
+	var ret []Result
+	for _, i := range ids {
+		if len(ret) >= n {
+			break
+		}
+		gr, err := r.store.GetGameRecord(r.platform, strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		if gr.Type != liked.Type || gr.FullGame == id || (liked.FullGame != 0 && gr.ID == liked.FullGame) {
+			continue
+		}
+		res := Result{ID: i, Name: gr.Name, Score: scores[i]}
+		if other, err := r.store.GetSimilarityVector(r.platform, i); err == nil {
+			res.Reasons = reasons(v, other)
+		}
+		ret = append(ret, res)
+	}
+	return ret, nil
+}
+
+// reasons are the three shared terms weighing most in similarity of vectors
+func reasons(a, b map[string]float64) []string {
+	var shared []string
+	for t := range a {
+		if _, ok := b[t]; ok {
+			shared = append(shared, t)
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		wi, wj := a[shared[i]]*b[shared[i]], a[shared[j]]*b[shared[j]]
+		if wi != wj {
+			return wi > wj
+		}
+		return shared[i] < shared[j]
+	})
+	if len(shared) > 3 {
+		shared = shared[:3]
+	}
+	return shared
+}
@@ -0,0 +1,100 @@
+package recommend
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestVector(t *testing.T) {
+	var tests = []struct {
+		terms map[string]int
+		df    map[string]int
+		res   map[string]float64
+	}{
+		// weights of fields are kept, idf tells terms apart within a field
+		{
+			map[string]int{"word:witcher": 1, "genre:rpg": 1, "category:single-player": 1,
+				"developer:cd projekt red": 1, "publisher:cd projekt red": 1},
+			map[string]int{"word:witcher": 2, "genre:rpg": 5, "category:single-player": 9,
+				"developer:cd projekt red": 2, "publisher:cd projekt red": 2},
+			map[string]float64{"word:witcher": math.Sqrt(0.5), "genre:rpg": math.Sqrt(0.2),
+				"category:single-player": math.Sqrt(0.1), "developer:cd projekt red": math.Sqrt(0.1),
+				"publisher:cd projekt red": math.Sqrt(0.1)},
+		},
+		// missing fields scale up the others, terms not indexed are dropped
+		{
+			map[string]int{"genre:rpg": 1, "developer:valve": 1, "word:rare": 1},
+			map[string]int{"genre:rpg": 5, "developer:valve": 2},
+			map[string]float64{"genre:rpg": math.Sqrt(2.0 / 3), "developer:valve": math.Sqrt(1.0 / 3)},
+		},
+		{map[string]int{"word:rare": 1}, map[string]int{}, map[string]float64{}},
+	}
+	for caseid, c := range tests {
+		res := vector(c.terms, c.df, 10)
+		if len(res) != len(c.res) {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, res, c.res)
+			continue
+		}
+		for k, w := range c.res {
+			if math.Abs(res[k]-w) > 1e-9 {
+				t.Errorf("case #%d, %s got: %v, expected: %v", caseid+1, k, res[k], w)
+			}
+		}
+	}
+}
+
+func TestLike(t *testing.T) {
+	cdpr := []string{"CD PROJEKT RED"}
+	records := []store.GameRecord{
+		{ID: 292030, Name: "The Witcher 3: Wild Hunt", Type: "game", Developers: cdpr, Publishers: cdpr,
+			Genres: []string{"RPG"}, Categories: []string{"Single-player"},
+			DescriptionText: "Become a monster slayer for hire, the witcher Geralt, in an open world fantasy adventure."},
+		{ID: 20920, Name: "The Witcher 2: Assassins of Kings", Type: "game", Developers: cdpr, Publishers: cdpr,
+			Genres: []string{"RPG"}, Categories: []string{"Single-player"},
+			DescriptionText: "A story of the witcher Geralt, a professional monster slayer for hire, tangled in a fantasy web of intrigue."},
+		{ID: 1091500, Name: "Cyberpunk 2077", Type: "game", Developers: cdpr, Publishers: cdpr,
+			Genres: []string{"RPG"}, Categories: []string{"Single-player"},
+			DescriptionText: "An action story set in Night City, a megalopolis obsessed with power, glamour and body modification."},
+		{ID: 10, Name: "Counter-Strike", Type: "game", Developers: []string{"Valve"}, Publishers: []string{"Valve"},
+			Genres: []string{"Action"}, Categories: []string{"Multi-player"},
+			DescriptionText: "Play the world's number one online action game, teams of terrorists and counter-terrorists."},
+		{ID: 378648, Name: "The Witcher 3: Wild Hunt - Blood and Wine", Type: "dlc", FullGame: 292030,
+			Developers: cdpr, Publishers: cdpr, Genres: []string{"RPG"},
+			DescriptionText: "The witcher Geralt travels to Toussaint, a land of wine and monster hunting."},
+	}
+	db := storetest.New(t, records...)
+	rec := New("steam", db)
+	if n, err := rec.Build(); err != nil || n != len(records) {
+		t.Fatalf("Build got: %d %v, expected: %d", n, err, len(records))
+	}
+	var tests = []struct {
+		id  int
+		n   int
+		ids []int
+		err error
+	}{
+		{292030, 2, []int{20920, 1091500}, nil},
+		{292030, 20, []int{20920, 1091500, 10}, nil},
+		{10, 1, []int{1091500}, nil},
+		{378648, 5, nil, nil},
+		{1, 5, nil, ErrNotIndexed},
+	}
+	for caseid, c := range tests {
+		res, err := rec.Like(c.id, c.n)
+		var ids []int
+		for _, r := range res {
+			ids = append(ids, r.ID)
+		}
+		if err != c.err || !reflect.DeepEqual(ids, c.ids) {
+			t.Errorf("case #%d, got: %v %v, expected: %v %v", caseid+1, ids, err, c.ids, c.err)
+		}
+	}
+	res, _ := rec.Like(292030, 1)
+	if len(res) != 1 || res[0].Score <= 0 || res[0].Score > 1 || len(res[0].Reasons) != 3 {
+		t.Errorf("got: %+v, expected score within 0 to 1 with 3 reasons", res)
+	}
+}
@@ -0,0 +1,107 @@
+package release
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+)
+
+// precisionOrder ranks precisions of entries on the same date, exact dates first
+var precisionOrder = map[string]int{
+	store.PrecisionDay:     0,
+	store.PrecisionMonth:   1,
+	store.PrecisionQuarter: 2,
+	store.PrecisionYear:    3,
+}
+
+// Config is the configuration struct of release calendars
+type Config struct {
+	// Platform to list releases of, e.g. steam
+	Platform string
+	// Types of apps to list, e.g. game, all types if empty
+	Types []string
+	// From and To are the period of the calendar, To is exclusive
+	From time.Time
+	To   time.Time
+}
+
+// Entry is a game released in the period of a calendar
+type Entry struct {
+	ID      int
+	Name    string
+	Type    string
+	Release store.ReleaseDate
+}
+
+// Calendar lists releases of a platform in a period
+type Calendar struct {
+	config   Config
+	store    store.GameStore
+	debugLog *log.Logger
+	infoLog  *log.Logger
+}
+
+// New creates a release calendar
+func New(cfg Config, db store.GameStore) *Calendar {
+	return &Calendar{
+		config:   cfg,
+		store:    db,
+		debugLog: log.New(os.Stdout, "Release DEBUG:", log.LstdFlags|log.Lshortfile),
+		infoLog:  log.New(os.Stdout, "Release INFO:", log.LstdFlags|log.Lshortfile),
+	}
+}
+
+func (c *Calendar) listed(appType string) bool {
+	if len(c.config.Types) == 0 {
+		return true
+	}
+	for _, t := range c.config.Types {
+		if strings.EqualFold(t, appType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Entries lists games whose release date falls in the period, a game known
+// by month, quarter or year is listed if its period overlaps. Entries are
+// ordered by date, exact dates first.
+func (c *Calendar) Entries() ([]Entry, error) {
+	var ret []Entry
+	unknown := 0
+	if err := c.store.ForEachGameRecord(c.config.Platform, func(subid string, r store.GameRecord) error {
+		if !c.listed(r.Type) {
+			return nil
+		}
+		d := Of(r)
+		if !d.Known() {
+			if d.ComingSoon {
+				unknown++
+			}
+			return nil
+		}
+		if d.Date.Before(c.config.To) && d.End().After(c.config.From) {
+			ret = append(ret, Entry{ID: r.ID, Name: r.Name, Type: r.Type, Release: d})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		a, b := ret[i].Release, ret[j].Release
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if precisionOrder[a.Precision] != precisionOrder[b.Precision] {
+			return precisionOrder[a.Precision] < precisionOrder[b.Precision]
+		}
+		return ret[i].ID < ret[j].ID
+	})
+	c.infoLog.Printf("%d %s releases from %s to %s, %d coming soon without a date", len(ret), c.config.Platform,
+		c.config.From.Format("2006-01-02"), c.config.To.AddDate(0, 0, -1).Format("2006-01-02"), unknown)
+	return ret, nil
+}
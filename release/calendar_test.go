@@ -0,0 +1,88 @@
+package release
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestEntries(t *testing.T) {
+	records := []store.GameRecord{
+		{ID: 1, Name: "Out Today", Type: "game", Release: Parse("1 Nov, 2024", false)},
+		{ID: 2, Name: "This Quarter", Type: "game", Release: Parse("Q4 2024", true)},
+		{ID: 3, Name: "November", Type: "game", Release: Parse("November 2024", true)},
+		{ID: 4, Name: "Later", Type: "game", Release: Parse("1 Jan, 2025", true)},
+		{ID: 5, Name: "Someday", Type: "game", Release: Parse("Coming soon", true)},
+		{ID: 6, Name: "Old", Type: "game", ReleaseYear: 2024},
+		{ID: 7, Name: "Soundtrack", Type: "music", Release: Parse("15 Nov, 2024", true)},
+		{ID: 8, Name: "Mid November", Type: "game", Release: Parse("15 Nov, 2024", true)},
+		{ID: 9, Name: "Past", Type: "game", Release: Parse("31 Oct, 2024", false)},
+	}
+	db := storetest.New(t, records...)
+	entries, err := New(Config{
+		Platform: "steam",
+		Types:    []string{"game"},
+		From:     time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+	}, db).Entries()
+	if err != nil {
+		t.Fatalf("Entries err: %v", err)
+	}
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, strconv.Itoa(e.ID))
+	}
+	if got := strings.Join(ids, " "); got != "6 2 1 3 8" {
+		t.Errorf("got: %s, expected: 6 2 1 3 8", got)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICal(&buf, "steam", entries); err != nil {
+		t.Fatalf("WriteICal err: %v", err)
+	}
+	ical := buf.String()
+	var tests = []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:steam-2@gamecha\r\nDTSTAMP:",
+		"DTSTART;VALUE=DATE:20241001\r\nDTEND;VALUE=DATE:20250101\r\nSUMMARY:This Quarter (Q4 2024)\r\n",
+		"DTSTART;VALUE=DATE:20241101\r\nDTEND;VALUE=DATE:20241102\r\nSUMMARY:Out Today\r\n",
+		"END:VCALENDAR\r\n",
+	}
+	for caseid, s := range tests {
+		if !strings.Contains(ical, s) {
+			t.Errorf("case #%d, %q not in:\n%s", caseid+1, s, ical)
+		}
+	}
+	if n := strings.Count(ical, "BEGIN:VEVENT"); n != len(entries) {
+		t.Errorf("got: %d events, expected: %d", n, len(entries))
+	}
+}
+
+func TestICalText(t *testing.T) {
+	var tests = []struct {
+		s    string
+		text string
+	}{
+		{"Hello, World; Again", `Hello\, World\; Again`},
+		{"a\\b\nc", `a\\b\nc`},
+	}
+	for caseid, c := range tests {
+		if text := icalText(c.s); text != c.text {
+			t.Errorf("case #%d, got: %q, expected: %q", caseid+1, text, c.text)
+		}
+	}
+	long := "SUMMARY:" + strings.Repeat("日本", 30)
+	for i, l := range strings.Split(fold(long), "\r\n") {
+		if len(l) > maxLine {
+			t.Errorf("line #%d of %d octets, expected at most %d", i+1, len(l), maxLine)
+		}
+		if i > 0 && !strings.HasPrefix(l, " ") {
+			t.Errorf("line #%d not continued: %q", i+1, l)
+		}
+	}
+}
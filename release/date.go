@@ -0,0 +1,147 @@
+// Package release parses release dates of games from platform texts, and
+// builds calendars of games released or coming in a period
+package release
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ksang/gamecha/store"
+)
+
+// months are month names and abbreviations of store page locales, english,
+// german, french and spanish
+var months = map[string]time.Month{
+	"january": 1, "jan": 1, "januar": 1, "janvier": 1, "janv": 1, "enero": 1, "ene": 1,
+	"february": 2, "feb": 2, "februar": 2, "février": 2, "fevrier": 2, "févr": 2, "fevr": 2, "febrero": 2,
+	"march": 3, "mar": 3, "märz": 3, "marz": 3, "mär": 3, "mrz": 3, "mars": 3, "marzo": 3,
+	"april": 4, "apr": 4, "avril": 4, "avr": 4, "abril": 4, "abr": 4,
+	"may": 5, "mai": 5, "mayo": 5,
+	"june": 6, "jun": 6, "juni": 6, "juin": 6, "junio": 6,
+	"july": 7, "jul": 7, "juli": 7, "juillet": 7, "juil": 7, "julio": 7,
+	"august": 8, "aug": 8, "août": 8, "aout": 8, "agosto": 8, "ago": 8,
+	"september": 9, "sep": 9, "sept": 9, "septembre": 9, "septiembre": 9, "setiembre": 9,
+	"october": 10, "oct": 10, "oktober": 10, "okt": 10, "octobre": 10, "octubre": 10,
+	"november": 11, "nov": 11, "novembre": 11, "noviembre": 11,
+	"december": 12, "dec": 12, "dezember": 12, "dez": 12, "décembre": 12, "decembre": 12, "déc": 12,
+	"diciembre": 12, "dic": 12,
+}
+
+var (
+	// quarterRe matches quarters, e.g. Q3 2024, T3 2024, 3rd quarter 2024 or 3. Quartal 2024
+	quarterRe = regexp.MustCompile(`(?i)\b(?:[qt]([1-4])|([1-4])(?:st|nd|rd|th|e|er|\.)?\s*(?:quarter|quartal|trimestre))\b`)
+	// ordinalRe matches days with ordinal suffixes, e.g. 1st or 1er
+	ordinalRe = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th|er|e)$`)
+	// cjkDate are year, month and day marks of chinese, japanese and korean dates
+	cjkDate = strings.NewReplacer("年", " ", "月", " ", "日", " ", "년", " ", "월", " ", "일", " ")
+)
+
+// Parse parses a release date text of a store page. Dates are parsed as
+// precise as the text tells, e.g. 1 Nov, 2000 is a day, November 2000 a
+// month, Q3 2024 a quarter and Early 2025 a year. Texts without a year,
+// e.g. Coming soon or TBA, are unknown.
+func Parse(text string, comingSoon bool) store.ReleaseDate {
+	ret := store.ReleaseDate{Precision: store.PrecisionUnknown, ComingSoon: comingSoon, Raw: strings.TrimSpace(text)}
+	lower := strings.ToLower(cjkDate.Replace(ret.Raw))
+	tokens := strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	year, yearAt := 0, -1
+	for i, t := range tokens {
+		if n, err := strconv.Atoi(t); err == nil && len(t) == 4 && n >= 1970 && n <= 2100 {
+			year, yearAt = n, i
+			break
+		}
+	}
+	if year == 0 {
+		return ret
+	}
+	if m := quarterRe.FindStringSubmatch(lower); m != nil {
+		q, _ := strconv.Atoi(m[1] + m[2])
+		ret.Date, ret.Precision = date(year, time.Month(3*q-2), 1), store.PrecisionQuarter
+		return ret
+	}
+	var month time.Month
+	var numbers []int
+	for i, t := range tokens {
+		if i == yearAt {
+			continue
+		}
+		if m, ok := months[strings.TrimSuffix(t, ".")]; ok && month == 0 {
+			month = m
+			continue
+		}
+		if m := ordinalRe.FindStringSubmatch(t); m != nil {
+			t = m[1]
+		}
+		if n, err := strconv.Atoi(t); err == nil && len(t) <= 2 {
+			numbers = append(numbers, n)
+		}
+	}
+	day := 0
+	switch {
+	case month != 0 && len(numbers) > 0:
+		day = numbers[0]
+	case month == 0 && len(numbers) >= 2 && yearAt == 0:
+		// year first, e.g. 2000-11-01 or 2000年11月1日
+		month, day = time.Month(numbers[0]), numbers[1]
+	case month == 0 && len(numbers) >= 2:
+		// day first unless it can only be a month, e.g. 01.11.2000 or 11/30/2000
+		day, month = numbers[0], time.Month(numbers[1])
+		if month > 12 {
+			day, month = numbers[1], time.Month(numbers[0])
+		}
+	case month == 0 && len(numbers) == 1 && yearAt == 0:
+		// year and month, e.g. 2000年11月
+		month = time.Month(numbers[0])
+	}
+	if month < 1 || month > 12 {
+		ret.Date, ret.Precision = date(year, 1, 1), store.PrecisionYear
+		return ret
+	}
+	if d := date(year, month, day); day > 0 && d.Day() == day {
+		ret.Date, ret.Precision = d, store.PrecisionDay
+		return ret
+	}
+	ret.Date, ret.Precision = date(year, month, 1), store.PrecisionMonth
+	return ret
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Of is the release date of a record. Records collected before release dates
+// were parsed have only their year and month known.
+func Of(r store.GameRecord) store.ReleaseDate {
+	if r.Release.Precision != "" {
+		return r.Release
+	}
+	if r.ReleaseYear != 0 && r.ReleaseMonth != 0 {
+		d := date(r.ReleaseYear, time.Month(r.ReleaseMonth), 1)
+		return store.ReleaseDate{Date: d, Precision: store.PrecisionMonth, Raw: d.Format("January 2006")}
+	}
+	if r.ReleaseYear != 0 {
+		return store.ReleaseDate{Date: date(r.ReleaseYear, 1, 1), Precision: store.PrecisionYear, Raw: strconv.Itoa(r.ReleaseYear)}
+	}
+	return store.ReleaseDate{Precision: store.PrecisionUnknown}
+}
+
+// Year of a release date, 0 if unknown
+func Year(d store.ReleaseDate) int {
+	if !d.Known() {
+		return 0
+	}
+	return d.Date.Year()
+}
+
+// Month of a release date from 1 to 12, 0 if unknown or known less precisely
+func Month(d store.ReleaseDate) int {
+	if d.Precision != store.PrecisionDay && d.Precision != store.PrecisionMonth {
+		return 0
+	}
+	return int(d.Date.Month())
+}
@@ -0,0 +1,82 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+)
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		text      string
+		date      string
+		precision string
+	}{
+		{"1 Nov, 2000", "2000-11-01", store.PrecisionDay},
+		{"Nov 1, 2000", "2000-11-01", store.PrecisionDay},
+		{"November 21st, 2023", "2023-11-21", store.PrecisionDay},
+		{"21. Nov. 2023", "2023-11-21", store.PrecisionDay},
+		{"21 nov. 2023", "2023-11-21", store.PrecisionDay},
+		{"1er déc. 2023", "2023-12-01", store.PrecisionDay},
+		{"3 MAR 2015", "2015-03-03", store.PrecisionDay},
+		{"3 ENE 2015", "2015-01-03", store.PrecisionDay},
+		{"2000-11-01", "2000-11-01", store.PrecisionDay},
+		{"01.11.2000", "2000-11-01", store.PrecisionDay},
+		{"11/30/2000", "2000-11-30", store.PrecisionDay},
+		{"2000年11月1日", "2000-11-01", store.PrecisionDay},
+		{"2000년 11월 1일", "2000-11-01", store.PrecisionDay},
+		{"30 Feb, 2024", "2024-02-01", store.PrecisionMonth},
+		{"November 2000", "2000-11-01", store.PrecisionMonth},
+		{"Dezember 2024", "2024-12-01", store.PrecisionMonth},
+		{"2024年6月", "2024-06-01", store.PrecisionMonth},
+		{"Q3 2024", "2024-07-01", store.PrecisionQuarter},
+		{"q1, 2025", "2025-01-01", store.PrecisionQuarter},
+		{"4th Quarter 2024", "2024-10-01", store.PrecisionQuarter},
+		{"2. Quartal 2025", "2025-04-01", store.PrecisionQuarter},
+		{"T3 2024", "2024-07-01", store.PrecisionQuarter},
+		{"2024", "2024-01-01", store.PrecisionYear},
+		{"Early 2025", "2025-01-01", store.PrecisionYear},
+		{"Coming 2026!", "2026-01-01", store.PrecisionYear},
+		{"Coming soon", "", store.PrecisionUnknown},
+		{"To be announced", "", store.PrecisionUnknown},
+		{"TBA", "", store.PrecisionUnknown},
+		{"", "", store.PrecisionUnknown},
+	}
+	for caseid, c := range tests {
+		d := Parse(c.text, false)
+		got := ""
+		if !d.Date.IsZero() {
+			got = d.Date.Format("2006-01-02")
+		}
+		if got != c.date || d.Precision != c.precision || d.Raw != c.text {
+			t.Errorf("case #%d, got: %s %s, expected: %s %s", caseid+1, got, d.Precision, c.date, c.precision)
+		}
+	}
+	if d := Parse("Coming soon", true); !d.ComingSoon {
+		t.Errorf("got: %+v, expected coming soon", d)
+	}
+}
+
+func TestOf(t *testing.T) {
+	var tests = []struct {
+		r    store.GameRecord
+		str  string
+		year int
+	}{
+		{store.GameRecord{Release: Parse("1 Nov, 2000", false), ReleaseYear: 2000}, "1 Nov 2000", 2000},
+		{store.GameRecord{ReleaseYear: 2015}, "2015", 2015},
+		{store.GameRecord{ReleaseYear: 2015, ReleaseMonth: 3}, "Mar 2015", 2015},
+		{store.GameRecord{Release: Parse("Coming soon", true)}, "Coming soon", 0},
+		{store.GameRecord{}, "unknown", 0},
+	}
+	for caseid, c := range tests {
+		d := Of(c.r)
+		if d.String() != c.str || Year(d) != c.year {
+			t.Errorf("case #%d, got: %s %d, expected: %s %d", caseid+1, d, Year(d), c.str, c.year)
+		}
+	}
+	if d := Of(store.GameRecord{ReleaseYear: 2015}); !d.Date.Equal(time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got: %v, expected: 2015-01-01", d.Date)
+	}
+}
@@ -0,0 +1,67 @@
+package release
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+)
+
+// maxLine is the most octets of an iCalendar line, longer lines are folded
+const maxLine = 75
+
+// WriteICal writes entries as all-day iCalendar events. An event spans the
+// period of its release date, e.g. all of Q3 2024.
+func WriteICal(w io.Writer, platform string, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	line := func(s string) { bw.WriteString(fold(s) + "\r\n") }
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//ksang//gamecha//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:" + icalText(platform+" releases"))
+	for _, e := range entries {
+		summary := e.Name
+		if e.Release.Precision != store.PrecisionDay {
+			summary += " (" + e.Release.String() + ")"
+		}
+		line("BEGIN:VEVENT")
+		line(fmt.Sprintf("UID:%s-%d@gamecha", platform, e.ID))
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + e.Release.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + e.Release.End().Format("20060102"))
+		line("SUMMARY:" + icalText(summary))
+		line("DESCRIPTION:" + icalText(fmt.Sprintf("%s %d, released %s, precision %s",
+			platform, e.ID, e.Release.Raw, e.Release.Precision)))
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// icalText escapes a text value, backslashes, separators and newlines
+func icalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// fold splits a line into lines of at most maxLine octets, continued lines
+// start with a space. Lines are not split inside a utf-8 character.
+func fold(s string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > maxLine {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
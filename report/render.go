@@ -0,0 +1,168 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of written reports
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+)
+
+// ErrFormat indicates an unknown report format
+var ErrFormat = errors.New("unknown report format")
+
+// table is a section of a written report
+type table struct {
+	Title  string
+	Note   string
+	Header []string
+	Rows   [][]string
+}
+
+// tables lays out sections of a report, markdown and html share them
+func (r *Report) tables() []table {
+	releases := table{
+		Title:  "Releases by genre",
+		Note:   fmt.Sprintf("%d released games without a known release month are left out.", r.Undated),
+		Header: append([]string{"Month", "Total"}, r.Genres...),
+	}
+	for _, rel := range r.Releases {
+		row := []string{rel.Period, strconv.Itoa(rel.Total)}
+		for _, g := range r.Genres {
+			row = append(row, strconv.Itoa(rel.Genres[g]))
+		}
+		releases.Rows = append(releases.Rows, row)
+	}
+	pricing := table{
+		Title:  "Free and paid games",
+		Note:   "Games never priced in region are unpriced, free share is of free and paid games.",
+		Header: []string{"Free", "Paid", "Unpriced", "Free share"},
+		Rows: [][]string{{strconv.Itoa(r.Pricing.Free), strconv.Itoa(r.Pricing.Paid),
+			strconv.Itoa(r.Pricing.Unpriced), percent(r.Pricing.FreeShare)}},
+	}
+	prices := table{
+		Title:  "Prices and discounts",
+		Note:   "Averages of paid games over their last price in each month.",
+		Header: []string{"Month", "Currency", "Games", "Avg price", "Avg initial", "Avg discount", "Discounted"},
+	}
+	for _, p := range r.Prices {
+		prices.Rows = append(prices.Rows, []string{p.Period, p.Currency, strconv.Itoa(p.Games),
+			fmt.Sprintf("%.2f", p.AvgPrice), fmt.Sprintf("%.2f", p.AvgInitial),
+			fmt.Sprintf("%.1f%%", p.AvgDiscount), strconv.Itoa(p.Discounted)})
+	}
+	coverage := table{
+		Title:  "Language coverage",
+		Note:   "Share of games released in a year supporting a language.",
+		Header: append([]string{"Year", "Games"}, r.Languages...),
+	}
+	for _, c := range r.Coverage {
+		row := []string{c.Period, strconv.Itoa(c.Games)}
+		for _, l := range r.Languages {
+			row = append(row, percent(c.Languages[l]))
+		}
+		coverage.Rows = append(coverage.Rows, row)
+	}
+	publishers := table{
+		Title:  "Top publishers",
+		Header: []string{"Publisher", "Games", "Developers", "Free"},
+	}
+	for _, p := range r.Publishers {
+		publishers.Rows = append(publishers.Rows, []string{p.Name, strconv.Itoa(p.Games),
+			strconv.Itoa(p.Developers), strconv.Itoa(p.Free)})
+	}
+	return []table{releases, pricing, prices, coverage, publishers}
+}
+
+func percent(f float64) string {
+	return fmt.Sprintf("%.1f%%", f*100)
+}
+
+func (r *Report) title() string {
+	return fmt.Sprintf("Market report of %s", r.Platform)
+}
+
+func (r *Report) summary() string {
+	return fmt.Sprintf("%d games, prices in region %s, generated %s.", r.Games, r.Region,
+		r.Generated.Format(time.RFC3339))
+}
+
+// Write writes report in format, md, html or json
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatMarkdown:
+		return r.writeMarkdown(w)
+	case FormatHTML:
+		return htmlTemplate.Execute(w, struct {
+			Title   string
+			Summary string
+			Tables  []table
+		}{r.title(), r.summary(), r.tables()})
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	return ErrFormat
+}
+
+func (r *Report) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%s\n", r.title(), r.summary())
+	for _, t := range r.tables() {
+		fmt.Fprintf(&b, "\n## %s\n\n", t.Title)
+		if t.Note != "" {
+			fmt.Fprintf(&b, "%s\n\n", t.Note)
+		}
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(mdCells(t.Header), " | "))
+		fmt.Fprintf(&b, "|%s\n", strings.Repeat(" --- |", len(t.Header)))
+		for _, row := range t.Rows {
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(mdCells(row), " | "))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mdCells escapes pipes of cells, they would split a cell
+func mdCells(cells []string) []string {
+	ret := make([]string, len(cells))
+	for i, c := range cells {
+		ret[i] = strings.Replace(c, "|", `\|`, -1)
+	}
+	return ret
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Summary}}</p>
+{{range .Tables}}<h2>{{.Title}}</h2>
+{{if .Note}}<p>{{.Note}}</p>
+{{end}}<table>
+<tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,410 @@
+// Package report aggregates the collected catalog into market trend reports:
+// releases by genre, free and paid shares, prices and discounts over time,
+// language coverage and top publishers
+package report
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksang/gamecha/release"
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/studio"
+)
+
+// monthLayout is the layout of monthly periods
+const monthLayout = "2006-01"
+
+// Config is the configuration struct of reports
+type Config struct {
+	// Platform to report records of, e.g. steam
+	Platform string
+	// Region of prices, e.g. us
+	Region string
+	// Types of apps to report, e.g. game, all types if empty
+	Types []string
+	// Since leaves out periods before it, all periods if zero
+	Since time.Time
+	// Top is the number of genres, languages and publishers reported
+	Top int
+	// Studios normalizes publisher names
+	Studios studio.Config
+}
+
+// Report is the aggregates of a platform. Genres and Languages are the most
+// common ones, ordered by count, reported per period. Releases are reported
+// by month, language coverage by year.
+type Report struct {
+	Generated time.Time  `json:"generated"`
+	Platform  string     `json:"platform"`
+	Region    string     `json:"region"`
+	Games     int        `json:"games"`
+	Genres    []string   `json:"genres"`
+	Releases  []Releases `json:"releases"`
+	// Undated is the number of games released without a known release month
+	Undated    int         `json:"undated"`
+	Pricing    Pricing     `json:"pricing"`
+	Prices     []Prices    `json:"prices"`
+	Languages  []string    `json:"languages"`
+	Coverage   []Coverage  `json:"coverage"`
+	Publishers []Publisher `json:"publishers"`
+}
+
+// Releases is the number of games released in a period, in all and by genre
+type Releases struct {
+	Period string         `json:"period"`
+	Total  int            `json:"total"`
+	Genres map[string]int `json:"genres"`
+}
+
+// Pricing is the free and paid split of games, games never priced in region
+// are unpriced
+type Pricing struct {
+	Free      int     `json:"free"`
+	Paid      int     `json:"paid"`
+	Unpriced  int     `json:"unpriced"`
+	FreeShare float64 `json:"free_share"`
+}
+
+// Prices is the average price of paid games in a month, of their last price
+// point in the month. Prices are in currency units.
+type Prices struct {
+	Period      string  `json:"period"`
+	Currency    string  `json:"currency"`
+	Games       int     `json:"games"`
+	AvgPrice    float64 `json:"avg_price"`
+	AvgInitial  float64 `json:"avg_initial"`
+	AvgDiscount float64 `json:"avg_discount"`
+	Discounted  int     `json:"discounted"`
+}
+
+// Coverage is the share of games released in a period supporting a language
+// in interface, audio or subtitles
+type Coverage struct {
+	Period    string             `json:"period"`
+	Games     int                `json:"games"`
+	Languages map[string]float64 `json:"languages"`
+}
+
+// Publisher is a publisher by its output, with the developers it published
+type Publisher struct {
+	Name       string `json:"name"`
+	Games      int    `json:"games"`
+	Developers int    `json:"developers"`
+	Free       int    `json:"free"`
+}
+
+// Reporter builds reports of a platform
+type Reporter struct {
+	config   Config
+	store    store.GameStore
+	debugLog *log.Logger
+	infoLog  *log.Logger
+}
+
+// New creates a reporter
+func New(cfg Config, db store.GameStore) *Reporter {
+	return &Reporter{
+		config:   cfg,
+		store:    db,
+		debugLog: log.New(os.Stderr, "Report DEBUG:", log.LstdFlags|log.Lshortfile),
+		infoLog:  log.New(os.Stderr, "Report INFO:", log.LstdFlags|log.Lshortfile),
+	}
+}
+
+func (rp *Reporter) reported(r store.GameRecord) bool {
+	if len(rp.config.Types) == 0 {
+		return true
+	}
+	for _, t := range rp.config.Types {
+		if strings.EqualFold(t, r.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseMonth is the month a game was released in, empty if unknown or
+// known less precisely
+func releaseMonth(r store.GameRecord) string {
+	d := release.Of(r)
+	if d.Precision != store.PrecisionDay && d.Precision != store.PrecisionMonth {
+		return ""
+	}
+	return d.Date.Format(monthLayout)
+}
+
+// releaseYear is the year a game was released in, empty if unknown
+func releaseYear(r store.GameRecord) string {
+	if y := release.Year(release.Of(r)); y != 0 {
+		return strconv.Itoa(y)
+	}
+	return ""
+}
+
+// since reports if a year or month period is not before Since, a year is
+// in if any month of it is
+func (rp *Reporter) since(period string) bool {
+	if rp.config.Since.IsZero() {
+		return true
+	}
+	if len(period) == 4 {
+		return period >= rp.config.Since.Format("2006")
+	}
+	return period >= rp.config.Since.Format(monthLayout)
+}
+
+// Build aggregates records of the platform into a report
+func (rp *Reporter) Build() (*Report, error) {
+	var records []store.GameRecord
+	if err := rp.store.ForEachGameRecord(rp.config.Platform, func(subid string, r store.GameRecord) error {
+		if rp.reported(r) {
+			records = append(records, r)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	ret := &Report{
+		Generated: time.Now().UTC(),
+		Platform:  rp.config.Platform,
+		Region:    rp.config.Region,
+		Games:     len(records),
+	}
+	rp.releases(ret, records)
+	rp.coverage(ret, records)
+	if err := rp.prices(ret, records); err != nil {
+		return nil, err
+	}
+	if err := rp.publishers(ret); err != nil {
+		return nil, err
+	}
+	rp.infoLog.Printf("reported %d %s records, %d release periods, %d price periods",
+		ret.Games, rp.config.Platform, len(ret.Releases), len(ret.Prices))
+	return ret, nil
+}
+
+// releases counts games released per month, by the Top genres. Games coming
+// soon are not released yet.
+func (rp *Reporter) releases(ret *Report, records []store.GameRecord) {
+	byPeriod := make(map[string]*Releases)
+	genres := make(map[string]int)
+	for _, r := range records {
+		if release.Of(r).ComingSoon {
+			continue
+		}
+		period := releaseMonth(r)
+		if period == "" {
+			ret.Undated++
+			continue
+		}
+		if !rp.since(period) {
+			continue
+		}
+		rel, ok := byPeriod[period]
+		if !ok {
+			rel = &Releases{Period: period, Genres: make(map[string]int)}
+			byPeriod[period] = rel
+		}
+		rel.Total++
+		for _, g := range r.Genres {
+			rel.Genres[g]++
+			genres[g]++
+		}
+	}
+	ret.Genres = top(genres, rp.config.Top)
+	for _, rel := range byPeriod {
+		for g := range rel.Genres {
+			if !contains(ret.Genres, g) {
+				delete(rel.Genres, g)
+			}
+		}
+		ret.Releases = append(ret.Releases, *rel)
+	}
+	sort.Slice(ret.Releases, func(i, j int) bool { return ret.Releases[i].Period < ret.Releases[j].Period })
+}
+
+// coverage finds shares of games released per year supporting the Top
+// languages
+func (rp *Reporter) coverage(ret *Report, records []store.GameRecord) {
+	byPeriod := make(map[string]*Coverage)
+	supported := make(map[string]map[string]int)
+	languages := make(map[string]int)
+	for _, r := range records {
+		period := releaseYear(r)
+		if period == "" || !rp.since(period) {
+			continue
+		}
+		c, ok := byPeriod[period]
+		if !ok {
+			c = &Coverage{Period: period, Languages: make(map[string]float64)}
+			byPeriod[period] = c
+			supported[period] = make(map[string]int)
+		}
+		c.Games++
+		seen := make(map[string]bool)
+		for _, l := range r.LanguageSupport {
+			if l.Code == "" || seen[l.Code] || !(l.Interface || l.FullAudio || l.Subtitles) {
+				continue
+			}
+			seen[l.Code] = true
+			supported[period][l.Code]++
+			languages[l.Code]++
+		}
+	}
+	ret.Languages = top(languages, rp.config.Top)
+	for period, c := range byPeriod {
+		for _, l := range ret.Languages {
+			c.Languages[l] = float64(supported[period][l]) / float64(c.Games)
+		}
+		ret.Coverage = append(ret.Coverage, *c)
+	}
+	sort.Slice(ret.Coverage, func(i, j int) bool { return ret.Coverage[i].Period < ret.Coverage[j].Period })
+}
+
+// prices splits games into free and paid, and averages prices of paid
+// games by month and currency
+func (rp *Reporter) prices(ret *Report, records []store.GameRecord) error {
+	type sums struct {
+		games, discounted        int
+		price, initial, discount int
+	}
+	byPeriod := make(map[[2]string]*sums)
+	for _, r := range records {
+		history, err := rp.store.GetPriceHistory(rp.config.Platform, r.ID)
+		if err != nil {
+			return err
+		}
+		points := history[rp.config.Region]
+		switch {
+		case r.IsFree:
+			ret.Pricing.Free++
+			continue
+		case len(points) == 0:
+			ret.Pricing.Unpriced++
+			continue
+		}
+		ret.Pricing.Paid++
+		// last point of a game in each month
+		last := make(map[string]store.PricePoint)
+		for _, p := range points {
+			month := p.Time.UTC().Format(monthLayout)
+			if l, ok := last[month]; !ok || p.Time.After(l.Time) {
+				last[month] = p
+			}
+		}
+		for month, p := range last {
+			if p.Initial == 0 || !rp.since(month) {
+				continue
+			}
+			k := [2]string{month, p.Currency}
+			s, ok := byPeriod[k]
+			if !ok {
+				s = &sums{}
+				byPeriod[k] = s
+			}
+			s.games++
+			s.price += p.Final
+			s.initial += p.Initial
+			s.discount += p.Discount
+			if p.Discount > 0 {
+				s.discounted++
+			}
+		}
+	}
+	if n := ret.Pricing.Free + ret.Pricing.Paid; n > 0 {
+		ret.Pricing.FreeShare = float64(ret.Pricing.Free) / float64(n)
+	}
+	for k, s := range byPeriod {
+		n := float64(s.games)
+		ret.Prices = append(ret.Prices, Prices{
+			Period:      k[0],
+			Currency:    k[1],
+			Games:       s.games,
+			AvgPrice:    float64(s.price) / 100 / n,
+			AvgInitial:  float64(s.initial) / 100 / n,
+			AvgDiscount: float64(s.discount) / n,
+			Discounted:  s.discounted,
+		})
+	}
+	sort.Slice(ret.Prices, func(i, j int) bool {
+		if ret.Prices[i].Period != ret.Prices[j].Period {
+			return ret.Prices[i].Period < ret.Prices[j].Period
+		}
+		return ret.Prices[i].Currency < ret.Prices[j].Currency
+	})
+	return nil
+}
+
+// publishers ranks publishers by games published in reported periods,
+// spellings of a publisher are joined by the studio graph
+func (rp *Reporter) publishers(ret *Report) error {
+	free := make(map[int]bool)
+	g, err := studio.Build(rp.store, rp.config.Platform, studio.NewNormalizer(rp.config.Studios), func(r store.GameRecord) bool {
+		if !rp.reported(r) {
+			return false
+		}
+		if period := releaseYear(r); !rp.config.Since.IsZero() && (period == "" || !rp.since(period)) {
+			return false
+		}
+		free[r.ID] = r.IsFree
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	for _, s := range g.Studios {
+		if len(s.Published) == 0 {
+			continue
+		}
+		p := Publisher{Name: s.Name, Games: len(s.Published), Developers: len(g.Developers(s))}
+		for _, id := range s.Published {
+			if free[id] {
+				p.Free++
+			}
+		}
+		ret.Publishers = append(ret.Publishers, p)
+	}
+	sort.Slice(ret.Publishers, func(i, j int) bool {
+		if ret.Publishers[i].Games != ret.Publishers[j].Games {
+			return ret.Publishers[i].Games > ret.Publishers[j].Games
+		}
+		return ret.Publishers[i].Name < ret.Publishers[j].Name
+	})
+	if len(ret.Publishers) > rp.config.Top {
+		ret.Publishers = ret.Publishers[:rp.config.Top]
+	}
+	return nil
+}
+
+// top keys of counts, by count descending
+func top(counts map[string]int, n int) []string {
+	ret := make([]string, 0, len(counts))
+	for k := range counts {
+		ret = append(ret, k)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if counts[ret[i]] != counts[ret[j]] {
+			return counts[ret[i]] > counts[ret[j]]
+		}
+		return ret[i] < ret[j]
+	})
+	if len(ret) > n {
+		ret = ret[:n]
+	}
+	return ret
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
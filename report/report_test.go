@@ -0,0 +1,137 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksang/gamecha/release"
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func testStore(t *testing.T) store.GameStore {
+	en := []store.LanguageSupport{{Code: "en", Interface: true}}
+	enDe := []store.LanguageSupport{{Code: "en", Interface: true}, {Code: "de", Subtitles: true}}
+	records := []store.GameRecord{
+		{ID: 1, Name: "A", Type: "game", Release: release.Parse("5 Mar, 2019", false), Genres: []string{"Action"},
+			Publishers: []string{"Big Publisher Inc."}, LanguageSupport: en},
+		{ID: 2, Name: "B", Type: "game", Release: release.Parse("1 Nov, 2020", false), Genres: []string{"Action", "RPG"},
+			Publishers: []string{"Big Publisher"}, LanguageSupport: enDe},
+		{ID: 3, Name: "C", Type: "game", Release: release.Parse("Nov 2020", false), Genres: []string{"Indie"}, IsFree: true,
+			Developers: []string{"Small Studio"}, Publishers: []string{"Small Studio"}, LanguageSupport: en},
+		{ID: 4, Name: "D", Type: "game", Genres: []string{"RPG"}, Publishers: []string{"Big Publisher"}},
+		{ID: 5, Name: "D - Soundtrack", Type: "music", Release: release.Parse("Dec 2020", true)},
+	}
+	db := storetest.New(t, records...)
+	day := func(m time.Month, d int) time.Time { return time.Date(2020, m, d, 0, 0, 0, 0, time.UTC) }
+	for _, points := range []map[int]store.PricePoint{
+		{1: {Time: day(1, 1), Currency: "USD", Initial: 2000, Final: 2000}, 2: {Time: day(1, 1), Currency: "USD", Initial: 4000, Final: 4000}},
+		{1: {Time: day(1, 20), Currency: "USD", Initial: 2000, Final: 1000, Discount: 50}},
+		{1: {Time: day(2, 1), Currency: "USD", Initial: 2000, Final: 2000}},
+	} {
+		if err := db.SavePricePoints("steam", "us", points); err != nil {
+			t.Fatalf("SavePricePoints err: %v", err)
+		}
+	}
+	return db
+}
+
+func TestBuild(t *testing.T) {
+	r, err := New(Config{Platform: "steam", Region: "us", Types: []string{"game"}, Top: 2}, testStore(t)).Build()
+	if err != nil {
+		t.Fatalf("Build err: %v", err)
+	}
+	if r.Games != 4 || r.Undated != 1 {
+		t.Errorf("got: %d games %d undated, expected: 4 games 1 undated", r.Games, r.Undated)
+	}
+	var tests = []struct {
+		got      interface{}
+		expected interface{}
+	}{
+		{r.Genres, []string{"Action", "Indie"}},
+		{r.Releases, []Releases{
+			{Period: "2019-03", Total: 1, Genres: map[string]int{"Action": 1}},
+			{Period: "2020-11", Total: 2, Genres: map[string]int{"Action": 1, "Indie": 1}},
+		}},
+		{r.Pricing, Pricing{Free: 1, Paid: 2, Unpriced: 1, FreeShare: 1.0 / 3}},
+		{r.Prices, []Prices{
+			{Period: "2020-01", Currency: "USD", Games: 2, AvgPrice: 25, AvgInitial: 30, AvgDiscount: 25, Discounted: 1},
+			{Period: "2020-02", Currency: "USD", Games: 1, AvgPrice: 20, AvgInitial: 20},
+		}},
+		{r.Languages, []string{"en", "de"}},
+		{r.Coverage, []Coverage{
+			{Period: "2019", Games: 1, Languages: map[string]float64{"en": 1, "de": 0}},
+			{Period: "2020", Games: 2, Languages: map[string]float64{"en": 1, "de": 0.5}},
+		}},
+		{r.Publishers, []Publisher{
+			{Name: "Big Publisher", Games: 3},
+			{Name: "Small Studio", Games: 1, Free: 1},
+		}},
+	}
+	for caseid, c := range tests {
+		if !reflect.DeepEqual(c.got, c.expected) {
+			t.Errorf("case #%d, got: %+v, expected: %+v", caseid+1, c.got, c.expected)
+		}
+	}
+}
+
+func TestSince(t *testing.T) {
+	since := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+	r, err := New(Config{Platform: "steam", Region: "us", Types: []string{"game"}, Top: 5, Since: since}, testStore(t)).Build()
+	if err != nil {
+		t.Fatalf("Build err: %v", err)
+	}
+	if len(r.Releases) != 1 || r.Releases[0].Period != "2020-11" {
+		t.Errorf("got releases: %+v, expected: 2020-11 only", r.Releases)
+	}
+	if len(r.Prices) != 1 || r.Prices[0].Period != "2020-02" {
+		t.Errorf("got prices: %+v, expected: 2020-02 only", r.Prices)
+	}
+	if len(r.Publishers) != 2 || r.Publishers[0].Games != 1 {
+		t.Errorf("got publishers: %+v, expected: 2 publishers of 1 game", r.Publishers)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	r, err := New(Config{Platform: "steam", Region: "us", Top: 5}, testStore(t)).Build()
+	if err != nil {
+		t.Fatalf("Build err: %v", err)
+	}
+	// soundtrack coming soon is not released yet
+	if len(r.Releases) != 2 || r.Undated != 1 {
+		t.Errorf("got releases: %+v, %d undated, expected: 2019-03 and 2020-11, 1 undated", r.Releases, r.Undated)
+	}
+	r.Publishers = append(r.Publishers, Publisher{Name: "<Pipe | Games>"})
+	var tests = []struct {
+		format   string
+		contains []string
+	}{
+		{FormatMarkdown, []string{"# Market report of steam", "| Month | Total | Action |", `\| Games>`}},
+		{FormatHTML, []string{"<h2>Top publishers</h2>", "&lt;Pipe | Games&gt;"}},
+		{FormatJSON, []string{`"free_share"`}},
+	}
+	for caseid, c := range tests {
+		var buf bytes.Buffer
+		if err := r.Write(&buf, c.format); err != nil {
+			t.Fatalf("case #%d, Write err: %v", caseid+1, err)
+		}
+		for _, s := range c.contains {
+			if !strings.Contains(buf.String(), s) {
+				t.Errorf("case #%d, %q not in:\n%s", caseid+1, s, buf.String())
+			}
+		}
+		if c.format == FormatJSON {
+			var decoded Report
+			if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.Games != r.Games {
+				t.Errorf("case #%d, got: %v %d games, expected: %d games", caseid+1, err, decoded.Games, r.Games)
+			}
+		}
+	}
+	if err := r.Write(&bytes.Buffer{}, "pdf"); err != ErrFormat {
+		t.Errorf("got: %v, expected: %v", err, ErrFormat)
+	}
+}
@@ -0,0 +1,226 @@
+// Package sanitize cleans html and bbcode of game descriptions
+// into plain text or markdown, and extracts embedded media
+package sanitize
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Media is an image or video referenced in a description
+type Media struct {
+	Type string
+	URL  string
+}
+
+var (
+	tagRe       = regexp.MustCompile(`(?s)<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*)>|<!--.*?-->`)
+	attrRe      = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9_-]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	spaceRe     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	lineSpaceRe = regexp.MustCompile(` *\n *`)
+	// media urls starting with a placeholder not resolved by caller, e.g.
+	// {STEAM_APP_IMAGE}/extras/a.png, are dropped
+	placeholderRe = regexp.MustCompile(`^\{[A-Z_]+\}`)
+	// characters of plain text escaped in markdown
+	mdEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`)
+	// bbcode leftovers are rewritten as html before rendering
+	bbcodes = []struct {
+		re   *regexp.Regexp
+		html string
+	}{
+		{regexp.MustCompile(`(?is)\[img\]\s*(.*?)\s*\[/img\]`), `<img src="$1">`},
+		{regexp.MustCompile(`(?is)\[previewyoutube=([^;\]]+)[^\]]*\]\s*\[/previewyoutube\]`), `<video src="https://www.youtube.com/watch?v=$1"></video>`},
+		{regexp.MustCompile(`(?i)\[url=([^\]]+)\]`), `<a href="$1">`},
+		{regexp.MustCompile(`(?i)\[url\]([^\[]*)\[/url\]`), `<a href="$1">$1</a>`},
+		{regexp.MustCompile(`(?i)\[/url\]`), `</a>`},
+		{regexp.MustCompile(`(?i)\[(/?)(b|i|u|h1|h2|h3|p|strike|code|quote|table|tr|td|th)\]`), `<$1$2>`},
+		{regexp.MustCompile(`(?i)\[(/?)list\]`), `<${1}ul>`},
+		{regexp.MustCompile(`(?i)\[(/?)olist\]`), `<${1}ol>`},
+		{regexp.MustCompile(`\[\*\]`), `<li>`},
+		{regexp.MustCompile(`(?i)\[/?(spoiler|noparse|hr)\]`), ``},
+	}
+	// tags starting a new line in rendered output
+	blockTags = map[string]bool{
+		"div": true, "li": true, "tr": true, "blockquote": true, "quote": true,
+	}
+	// tags separated from surrounding text by an empty line
+	paragraphTags = map[string]bool{
+		"p": true, "ul": true, "ol": true, "table": true, "hr": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	}
+	// tags whose content is never rendered
+	skipTags = map[string]bool{"script": true, "style": true}
+)
+
+// Text renders description as plain text, media is dropped
+func Text(s string) string {
+	return render(s, false)
+}
+
+// Markdown renders description as markdown, media is kept as images and links
+// and markdown characters of text are escaped
+func Markdown(s string) string {
+	return render(s, true)
+}
+
+// ExtractMedia returns images and videos referenced in a description in order,
+// without duplicates and unresolved placeholder urls
+func ExtractMedia(s string) []Media {
+	var ret []Media
+	seen := make(map[string]bool)
+	add := func(typ, url string) {
+		if !mediaURL(url) || seen[url] {
+			return
+		}
+		seen[url] = true
+		ret = append(ret, Media{Type: typ, URL: url})
+	}
+	for _, m := range tagRe.FindAllStringSubmatch(convertBBCode(s), -1) {
+		if m[1] != "" {
+			continue
+		}
+		attrs := parseAttrs(m[3])
+		switch strings.ToLower(m[2]) {
+		case "img":
+			add("image", attrs["src"])
+		case "video", "source", "iframe":
+			add("video", attrs["src"])
+		}
+	}
+	return ret
+}
+
+func mediaURL(url string) bool {
+	return url != "" && !placeholderRe.MatchString(url)
+}
+
+func convertBBCode(s string) string {
+	for _, bb := range bbcodes {
+		s = bb.re.ReplaceAllString(s, bb.html)
+	}
+	return s
+}
+
+func parseAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrRe.FindAllStringSubmatch(s, -1) {
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+	return attrs
+}
+
+func render(s string, md bool) string {
+	s = convertBBCode(s)
+	var b strings.Builder
+	var links []string
+	skip := 0
+	// line breaks are held back until next output, so adjacent
+	// block tags don't stack up empty lines
+	breaks := 0
+	write := func(out string) {
+		if b.Len() > 0 && breaks > 0 {
+			b.WriteString(strings.Repeat("\n", minInt(breaks, 2)))
+		}
+		breaks = 0
+		b.WriteString(out)
+	}
+	text := func(t string) {
+		t = spaceRe.ReplaceAllString(t, " ")
+		if skip > 0 || (breaks > 0 && strings.TrimSpace(t) == "") {
+			return
+		}
+		t = html.UnescapeString(t)
+		if md {
+			t = mdEscaper.Replace(t)
+		}
+		write(t)
+	}
+	last := 0
+	for _, loc := range tagRe.FindAllStringSubmatchIndex(s, -1) {
+		text(s[last:loc[0]])
+		last = loc[1]
+		if loc[4] < 0 {
+			// comment
+			continue
+		}
+		closing := loc[3] > loc[2]
+		tag := strings.ToLower(s[loc[4]:loc[5]])
+		if skipTags[tag] {
+			if closing && skip > 0 {
+				skip--
+			} else if !closing {
+				skip++
+			}
+			continue
+		}
+		if skip > 0 {
+			continue
+		}
+		attrs := parseAttrs(s[loc[6]:loc[7]])
+		switch {
+		case tag == "br":
+			breaks++
+		case paragraphTags[tag]:
+			breaks = maxInt(breaks, 2)
+		case blockTags[tag]:
+			breaks = maxInt(breaks, 1)
+		}
+		if tag == "li" && !closing {
+			write("- ")
+		}
+		if !md {
+			continue
+		}
+		switch tag {
+		case "b", "strong":
+			write("**")
+		case "i", "em":
+			write("*")
+		case "strike", "s", "del":
+			write("~~")
+		case "code":
+			write("`")
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			if !closing {
+				write(strings.Repeat("#", int(tag[1]-'0')) + " ")
+			}
+		case "hr":
+			write("---")
+			breaks = 2
+		case "a":
+			if !closing {
+				links = append(links, attrs["href"])
+				write("[")
+			} else if len(links) > 0 {
+				write("](" + links[len(links)-1] + ")")
+				links = links[:len(links)-1]
+			}
+		case "img":
+			if mediaURL(attrs["src"]) {
+				write("![" + mdEscaper.Replace(attrs["alt"]) + "](" + attrs["src"] + ")")
+			}
+		case "video", "source":
+			if !closing && mediaURL(attrs["src"]) {
+				write("[video](" + attrs["src"] + ")")
+			}
+		}
+	}
+	text(s[last:])
+	out := lineSpaceRe.ReplaceAllString(b.String(), "\n")
+	return strings.TrimSpace(out)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,64 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+var description = `<h1>Special Edition</h1><p>Includes the <strong>soundtrack</strong> &amp; artbook.</p><br>
+<img src="https://cdn.akamai.steamstatic.com/steam/apps/10/extras/banner.gif?t=1" /><br><br>
+Play the world's <i>number 1</i> online action game.<ul class="bb_ul"><li>Ally with teammates<br></li><li>Rescue hostages</li></ul>
+<video class="bb_img" autoplay muted loop playsinline poster="https://cdn.akamai.steamstatic.com/poster.jpg"><source src="https://cdn.akamai.steamstatic.com/clip.webm" type="video/webm"></video>
+Visit <a href="https://steamcommunity.com/linkfilter/?url=https://example.com" target="_blank">our site</a>[b]now[/b]!<script>alert(1)</script>
+[img]{STEAM_APP_IMAGE}/extras/old.png[/img]`
+
+func TestText(t *testing.T) {
+	var tests = []struct {
+		s string
+		r string
+	}{
+		{
+			description,
+			"Special Edition\n\nIncludes the soundtrack & artbook.\n\nPlay the world's number 1 online action game.\n\n- Ally with teammates\n- Rescue hostages\n\nVisit our sitenow!",
+		},
+		{"plain   text\nwith  spaces", "plain text with spaces"},
+		{"", ""},
+	}
+
+	for caseid, c := range tests {
+		if res := Text(c.s); res != c.r {
+			t.Errorf("case #%d, got: %q, expected: %q", caseid+1, res, c.r)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var tests = []struct {
+		s string
+		r string
+	}{
+		{
+			description,
+			"# Special Edition\n\nIncludes the **soundtrack** & artbook.\n\n![](https://cdn.akamai.steamstatic.com/steam/apps/10/extras/banner.gif?t=1)\n\nPlay the world's *number 1* online action game.\n\n- Ally with teammates\n- Rescue hostages\n\n[video](https://cdn.akamai.steamstatic.com/clip.webm) Visit [our site](https://steamcommunity.com/linkfilter/?url=https://example.com)**now**!",
+		},
+		{"[list][*]one[*]two[/list]", "- one\n- two"},
+		{"snake_case *stars* [tag] <b>bold</b>", `snake\_case \*stars\* \[tag\] **bold**`},
+	}
+
+	for caseid, c := range tests {
+		if res := Markdown(c.s); res != c.r {
+			t.Errorf("case #%d, got: %q, expected: %q", caseid+1, res, c.r)
+		}
+	}
+}
+
+func TestExtractMedia(t *testing.T) {
+	res := ExtractMedia(description + `<img src="https://cdn.akamai.steamstatic.com/steam/apps/10/extras/banner.gif?t=1">`)
+	expected := []Media{
+		{Type: "image", URL: "https://cdn.akamai.steamstatic.com/steam/apps/10/extras/banner.gif?t=1"},
+		{Type: "video", URL: "https://cdn.akamai.steamstatic.com/clip.webm"},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("got: %v, expected: %v", res, expected)
+	}
+}
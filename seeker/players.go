@@ -0,0 +1,86 @@
+package seeker
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+)
+
+// RetentionPolicy controls rollup of player samples: raw samples older than Raw
+// are rolled up hourly, hourly ones older than Hourly are rolled up daily,
+// and samples older than Max are dropped. Zero durations disable the step.
+type RetentionPolicy struct {
+	Raw    time.Duration
+	Hourly time.Duration
+	Max    time.Duration
+}
+
+// Rollup compacts samples sorted by time according to policy
+func (p RetentionPolicy) Rollup(samples []store.PlayerSample, now time.Time) []store.PlayerSample {
+	if p.Max > 0 {
+		cut := sort.Search(len(samples), func(i int) bool {
+			return !samples[i].Time.Before(now.Add(-p.Max))
+		})
+		samples = samples[cut:]
+	}
+	if p.Hourly > 0 {
+		samples = rollupBefore(samples, now.Add(-p.Hourly), 24*time.Hour)
+	}
+	if p.Raw > 0 {
+		samples = rollupBefore(samples, now.Add(-p.Raw), time.Hour)
+	}
+	return samples
+}
+
+// rollupBefore downsamples the samples older than t to step, newer ones are kept
+func rollupBefore(samples []store.PlayerSample, t time.Time, step time.Duration) []store.PlayerSample {
+	cut := sort.Search(len(samples), func(i int) bool {
+		return !samples[i].Time.Before(t.Truncate(step))
+	})
+	return append(downsample(samples[:cut], step), samples[cut:]...)
+}
+
+// sampleWeight is the number of raw samples a sample averages
+func sampleWeight(s store.PlayerSample) int64 {
+	if s.Samples > 0 {
+		return int64(s.Samples)
+	}
+	return 1
+}
+
+// downsample merges samples sorted by time into buckets of step, weighted by
+// raw samples they average. Samples covering more than step are kept as they
+// are, ones of step join samples of their bucket.
+func downsample(samples []store.PlayerSample, step time.Duration) []store.PlayerSample {
+	var ret []store.PlayerSample
+	var sum int64
+	for _, s := range samples {
+		if s.Span > step {
+			ret = append(ret, s)
+			continue
+		}
+		if s.Span == 0 {
+			s.Min, s.Max = s.Count, s.Count
+		}
+		w := sampleWeight(s)
+		bucket := s.Time.Truncate(step)
+		last := len(ret) - 1
+		if last < 0 || ret[last].Span != step || !ret[last].Time.Equal(bucket) {
+			ret = append(ret, store.PlayerSample{Time: bucket, Span: step, Count: s.Count, Min: s.Min, Max: s.Max, Samples: int(w)})
+			sum = int64(s.Count) * w
+			continue
+		}
+		b := &ret[last]
+		sum += int64(s.Count) * w
+		b.Samples += int(w)
+		b.Count = int(sum / int64(b.Samples))
+		if s.Min < b.Min {
+			b.Min = s.Min
+		}
+		if s.Max > b.Max {
+			b.Max = s.Max
+		}
+	}
+	return ret
+}
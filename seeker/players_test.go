@@ -0,0 +1,94 @@
+package seeker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+)
+
+func TestRollup(t *testing.T) {
+	now := time.Date(2019, 3, 10, 12, 0, 0, 0, time.UTC)
+	raw := func(d time.Duration, c int) store.PlayerSample {
+		return store.PlayerSample{Time: now.Add(-d), Count: c}
+	}
+	var tests = []struct {
+		p RetentionPolicy
+		s []store.PlayerSample
+		r []store.PlayerSample
+	}{
+		{
+			RetentionPolicy{},
+			[]store.PlayerSample{raw(time.Hour, 10), raw(0, 20)},
+			[]store.PlayerSample{raw(time.Hour, 10), raw(0, 20)},
+		},
+		{
+			// raw samples older than 2h are rolled up hourly
+			RetentionPolicy{Raw: 2 * time.Hour},
+			[]store.PlayerSample{
+				raw(3*time.Hour+30*time.Minute, 10),
+				raw(3*time.Hour+20*time.Minute, 30),
+				raw(2*time.Hour+50*time.Minute, 50),
+				raw(time.Hour, 100),
+			},
+			[]store.PlayerSample{
+				{Time: now.Add(-4 * time.Hour), Span: time.Hour, Count: 20, Min: 10, Max: 30, Samples: 2},
+				{Time: now.Add(-3 * time.Hour), Span: time.Hour, Count: 50, Min: 50, Max: 50, Samples: 1},
+				raw(time.Hour, 100),
+			},
+		},
+		{
+			// hourly samples older than 1 day are rolled up daily, older than 3 days dropped
+			RetentionPolicy{Raw: time.Hour, Hourly: 24 * time.Hour, Max: 72 * time.Hour},
+			[]store.PlayerSample{
+				{Time: now.Add(-80 * time.Hour), Span: time.Hour, Count: 1, Min: 1, Max: 1},
+				{Time: now.Add(-60 * time.Hour), Span: time.Hour, Count: 10, Min: 5, Max: 15},
+				{Time: now.Add(-59 * time.Hour), Span: time.Hour, Count: 20, Min: 15, Max: 40},
+				raw(30*time.Minute, 7),
+			},
+			[]store.PlayerSample{
+				{Time: now.Add(-60 * time.Hour), Span: 24 * time.Hour, Count: 15, Min: 5, Max: 40, Samples: 2},
+				raw(30*time.Minute, 7),
+			},
+		},
+	}
+
+	for caseid, c := range tests {
+		res := c.p.Rollup(c.s, now)
+		if !reflect.DeepEqual(res, c.r) {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, res, c.r)
+		}
+	}
+}
+
+func TestDownsampleWeights(t *testing.T) {
+	day := time.Date(2019, 3, 8, 0, 0, 0, 0, time.UTC)
+	var tests = []struct {
+		s []store.PlayerSample
+		r []store.PlayerSample
+	}{
+		{
+			// hourly rollups weigh by raw samples they average
+			[]store.PlayerSample{
+				{Time: day, Span: time.Hour, Count: 10, Min: 5, Max: 15, Samples: 6},
+				{Time: day.Add(time.Hour), Span: time.Hour, Count: 40, Min: 30, Max: 50, Samples: 3},
+			},
+			[]store.PlayerSample{{Time: day, Span: 24 * time.Hour, Count: 20, Min: 5, Max: 50, Samples: 9}},
+		},
+		{
+			// an existing bucket is merged, not reset
+			[]store.PlayerSample{
+				{Time: day, Span: 24 * time.Hour, Count: 10, Min: 5, Max: 15, Samples: 6},
+				{Time: day.Add(2 * time.Hour), Count: 40},
+			},
+			[]store.PlayerSample{{Time: day, Span: 24 * time.Hour, Count: 14, Min: 5, Max: 40, Samples: 7}},
+		},
+	}
+	for caseid, c := range tests {
+		res := downsample(c.s, 24*time.Hour)
+		if !reflect.DeepEqual(res, c.r) {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, res, c.r)
+		}
+	}
+}
@@ -19,5 +19,32 @@ func Start(ctx context.Context, cfg *Config, db store.GameStore) error {
 	if err != nil {
 		return err
 	}
-	return ss.WaitUntilDone(ctx)
+	if err := ss.WaitUntilDone(ctx); err != nil {
+		return err
+	}
+	if err := ss.TrackPrices(ctx); err != nil {
+		return err
+	}
+	if err := ss.CollectReviews(ctx); err != nil {
+		return err
+	}
+	if err := ss.CollectAchievements(ctx); err != nil {
+		return err
+	}
+	return ss.CollectNews(ctx)
+}
+
+// StartSampler samples concurrent players of tracked apps until context is done
+func StartSampler(ctx context.Context, cfg *Config, db store.GameStore) error {
+	return newSteamSeeker(cfg.SteamConfig, db).RunPlayerSampler(ctx)
+}
+
+// ImportLibrary of a steam user into store
+func ImportLibrary(ctx context.Context, cfg *Config, db store.GameStore, steamid string) (*store.Library, error) {
+	return newSteamSeeker(cfg.SteamConfig, db).ImportLibrary(ctx, steamid)
+}
+
+// SyncWishlist of a steam user into store
+func SyncWishlist(ctx context.Context, cfg *Config, db store.GameStore, steamid string) (*store.Wishlist, error) {
+	return newSteamSeeker(cfg.SteamConfig, db).SyncWishlist(ctx, steamid)
 }
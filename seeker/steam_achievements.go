@@ -0,0 +1,128 @@
+package seeker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/valyala/fastjson"
+)
+
+var (
+	pathGetSchemaForGame          = "/ISteamUserStats/GetSchemaForGame/v2/"
+	pathGetAchievementPercentages = "/ISteamUserStats/GetGlobalAchievementPercentagesForApp/v2/"
+)
+
+// CollectAchievements fetches achievement schema and global unlock percentages
+// of tracked apps, schema requires the configured web api key
+func (steam *SteamSeeker) CollectAchievements(ctx context.Context) error {
+	if len(steam.config.Tracked) == 0 || steam.config.Key == "" {
+		return nil
+	}
+	steam.infoLog.Printf("collecting achievements of %d tracked apps", len(steam.config.Tracked))
+	steam.runPool(ctx, "collectAchievements", steam.config.Tracked, steam.collectAchievements)
+	return nil
+}
+
+func (steam *SteamSeeker) collectAchievements(ctx context.Context, appid int) error {
+	achievements, err := steam.getSteamAchievements(ctx, pathGetSchemaForGame, "appid", appid, parseSteamAchievementSchema)
+	if err != nil {
+		return err
+	}
+	// apps without achievements have nothing to be percentaged
+	if len(achievements) > 0 {
+		percents, err := steam.getSteamAchievements(ctx, pathGetAchievementPercentages, "gameid", appid, parseSteamAchievementPercentages)
+		if err != nil {
+			return err
+		}
+		byName := make(map[string]float64, len(percents))
+		for _, a := range percents {
+			byName[a.Name] = a.Percent
+		}
+		for i := range achievements {
+			achievements[i].Percent = byName[achievements[i].Name]
+		}
+	}
+	steam.debugLog.Printf("workerThread[%d] got %d achievements of app: %d", ctx.Value(workerIDKey), len(achievements), appid)
+	return steam.store.SaveAchievements("steam", appid, store.AchievementSet{
+		Updated:      time.Now(),
+		Achievements: achievements,
+	})
+}
+
+// getSteamAchievements calls a ISteamUserStats api of app and parses body with parse
+func (steam *SteamSeeker) getSteamAchievements(ctx context.Context, path string, idParam string, appid int,
+	parse func([]byte) ([]store.Achievement, error)) ([]store.Achievement, error) {
+	req, err := http.NewRequest("GET", steam.config.Portal+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Add(idParam, strconv.Itoa(appid))
+	if steam.config.Key != "" {
+		q.Add("key", steam.config.Key)
+	}
+	req.URL.RawQuery = q.Encode()
+	var ret []store.Achievement
+	if err := httpDo(ctx, req, steam.client, func(resp *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests:
+			return ErrSteamRateLimit
+		case http.StatusForbidden:
+			// the api responds forbidden for apps without stats
+			return nil
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		ret, err = parse(body)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func parseSteamAchievementSchema(data []byte) ([]store.Achievement, error) {
+	var p fastjson.Parser
+	root, err := p.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	var ret []store.Achievement
+	for _, a := range root.GetArray("game", "availableGameStats", "achievements") {
+		ret = append(ret, store.Achievement{
+			Name:        string(a.GetStringBytes("name")),
+			DisplayName: string(a.GetStringBytes("displayName")),
+			Description: string(a.GetStringBytes("description")),
+			Hidden:      a.GetInt("hidden") == 1,
+			Icon:        string(a.GetStringBytes("icon")),
+			IconGray:    string(a.GetStringBytes("icongray")),
+		})
+	}
+	return ret, nil
+}
+
+func parseSteamAchievementPercentages(data []byte) ([]store.Achievement, error) {
+	var p fastjson.Parser
+	root, err := p.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	var ret []store.Achievement
+	for _, a := range root.GetArray("achievementpercentages", "achievements") {
+		ret = append(ret, store.Achievement{
+			Name:    string(a.GetStringBytes("name")),
+			Percent: numberOrString(a.Get("percent")),
+		})
+	}
+	return ret, nil
+}
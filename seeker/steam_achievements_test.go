@@ -0,0 +1,91 @@
+package seeker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestParseSteamAchievementSchema(t *testing.T) {
+	var tests = []struct {
+		s string
+		r []store.Achievement
+	}{
+		{`{"game":{"gameName":"Dota 2"}}`, nil},
+		{
+			`{"game":{"gameName":"Half-Life 2","availableGameStats":{"achievements":[
+				{"name":"HL2_HIT_CANCOP_WITHCAN","defaultvalue":0,"displayName":"Defiant","hidden":0,
+				 "description":"Hit the trashcan cop with the can.","icon":"http://a/1.jpg","icongray":"http://a/1g.jpg"},
+				{"name":"HL2_BEAT_GAME","defaultvalue":0,"displayName":"Singularity Collapse","hidden":1,
+				 "icon":"http://a/2.jpg","icongray":"http://a/2g.jpg"}]}}}`,
+			[]store.Achievement{
+				{Name: "HL2_HIT_CANCOP_WITHCAN", DisplayName: "Defiant", Description: "Hit the trashcan cop with the can.",
+					Icon: "http://a/1.jpg", IconGray: "http://a/1g.jpg"},
+				{Name: "HL2_BEAT_GAME", DisplayName: "Singularity Collapse", Hidden: true,
+					Icon: "http://a/2.jpg", IconGray: "http://a/2g.jpg"},
+			},
+		},
+	}
+	for caseid, c := range tests {
+		res, err := parseSteamAchievementSchema([]byte(c.s))
+		if err != nil {
+			t.Errorf("case #%d, err: %v", caseid+1, err)
+		}
+		if !reflect.DeepEqual(res, c.r) {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, res, c.r)
+		}
+	}
+}
+
+func TestParseSteamAchievementPercentages(t *testing.T) {
+	s := `{"achievementpercentages":{"achievements":[
+		{"name":"HL2_HIT_CANCOP_WITHCAN","percent":"88.5"},{"name":"HL2_BEAT_GAME","percent":31.2}]}}`
+	expected := []store.Achievement{
+		{Name: "HL2_HIT_CANCOP_WITHCAN", Percent: 88.5},
+		{Name: "HL2_BEAT_GAME", Percent: 31.2},
+	}
+	res, err := parseSteamAchievementPercentages([]byte(s))
+	if err != nil || !reflect.DeepEqual(res, expected) {
+		t.Errorf("got: %v %v, expected: %v", res, err, expected)
+	}
+}
+
+func TestCollectAchievements(t *testing.T) {
+	db := storetest.New(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Query().Get("key") != "k":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == pathGetSchemaForGame && r.URL.Query().Get("appid") == "220":
+			w.Write([]byte(`{"game":{"availableGameStats":{"achievements":[{"name":"A","displayName":"Alpha"},{"name":"B"}]}}}`))
+		case r.URL.Path == pathGetAchievementPercentages && r.URL.Query().Get("gameid") == "220":
+			w.Write([]byte(`{"achievementpercentages":{"achievements":[{"name":"B","percent":"10.5"},{"name":"A","percent":"90"}]}}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer ts.Close()
+
+	steam := newSteamSeeker(SteamConfig{Portal: ts.URL, Key: "k", WorkerNum: 2, Tracked: []int{220, 10}}, db)
+	if err := steam.CollectAchievements(context.Background()); err != nil {
+		t.Fatalf("CollectAchievements err: %v", err)
+	}
+	var tests = []struct {
+		id int
+		r  []store.Achievement
+	}{
+		{220, []store.Achievement{{Name: "A", DisplayName: "Alpha", Percent: 90}, {Name: "B", Percent: 10.5}}},
+		{10, nil},
+	}
+	for caseid, c := range tests {
+		as, err := db.GetAchievements("steam", c.id)
+		if err != nil || as.Updated.IsZero() || !reflect.DeepEqual(as.Achievements, c.r) {
+			t.Errorf("case #%d, got: %v %v, expected: %v", caseid+1, as, err, c.r)
+		}
+	}
+}
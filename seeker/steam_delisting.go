@@ -0,0 +1,141 @@
+package seeker
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+)
+
+// trackListing updates when apps of the app list were seen, records of apps
+// gone from the list are marked delisted and restored once they reappear.
+// It returns number of records delisted or restored.
+func (steam *SteamSeeker) trackListing(listed map[int]string, t time.Time) (int, error) {
+	// an empty list is a broken response rather than every app delisted
+	if len(listed) == 0 {
+		return 0, nil
+	}
+	seen, err := steam.store.GetAppsSeen("steam")
+	if err != nil {
+		return 0, err
+	}
+	changed := 0
+	for id := range listed {
+		s, ok := seen[id]
+		if !ok {
+			s.FirstSeen = t
+		}
+		s.LastSeen = t
+		if s.Delisted && !s.Unavailable {
+			s.Delisted = false
+			if err := steam.setListing(id, s); err != nil {
+				return changed, err
+			}
+			changed++
+		}
+		seen[id] = s
+	}
+	for id, s := range seen {
+		if _, ok := listed[id]; ok || s.Delisted {
+			continue
+		}
+		s.Delisted = true
+		if err := steam.setListing(id, s); err != nil {
+			return changed, err
+		}
+		seen[id] = s
+		changed++
+	}
+	steam.debugLog.Printf("trackListing: %d records delisted or restored", changed)
+	return changed, steam.store.SaveAppsSeen("steam", seen)
+}
+
+// setListing updates listing state of the record of app if it is in store
+func (steam *SteamSeeker) setListing(appid int, s store.AppSeen) error {
+	gr, err := steam.store.GetGameRecord("steam", strconv.Itoa(appid))
+	if err != nil || gr.ID == 0 {
+		return err
+	}
+	if s.Delisted {
+		steam.infoLog.Printf("app %d %s is delisted, last seen %s", appid, gr.Name, s.LastSeen.Format("2006-01-02"))
+	} else {
+		steam.infoLog.Printf("app %d %s is listed again", appid, gr.Name)
+	}
+	gr.Delisted, gr.FirstSeen, gr.LastSeen = s.Delisted, s.FirstSeen, s.LastSeen
+	return steam.store.SaveGameRecord("steam", strconv.Itoa(appid), *gr)
+}
+
+// delistUnavailable marks the record of app delisted when store does not
+// serve its detail anymore, false is returned if app has no record
+func (steam *SteamSeeker) delistUnavailable(ctx context.Context, appid int) (bool, error) {
+	gr, err := steam.store.GetGameRecord("steam", strconv.Itoa(appid))
+	if err != nil || gr.ID == 0 {
+		return false, err
+	}
+	seen, err := steam.store.GetAppsSeen("steam")
+	if err != nil {
+		return false, err
+	}
+	s, ok := seen[appid]
+	if s.Unavailable {
+		return true, nil
+	}
+	steam.debugLog.Printf("workerThread[%d] app: %d detail unavailable, delisting", ctx.Value(workerIDKey), appid)
+	if !ok {
+		s.FirstSeen, s.LastSeen = gr.FirstSeen, gr.LastSeen
+	}
+	s.Delisted, s.Unavailable = true, true
+	if err := steam.store.SaveAppsSeen("steam", map[int]store.AppSeen{appid: s}); err != nil {
+		return true, err
+	}
+	return true, steam.setListing(appid, s)
+}
+
+// relistAvailable restores a fetched record delisted as unavailable, store
+// serves its detail again
+func (steam *SteamSeeker) relistAvailable(gr *store.GameRecord) error {
+	seen, err := steam.store.GetAppsSeen("steam")
+	if err != nil {
+		return err
+	}
+	s, ok := seen[gr.ID]
+	if !ok || !s.Unavailable {
+		return nil
+	}
+	steam.infoLog.Printf("app %d %s is served again", gr.ID, gr.Name)
+	s.Delisted, s.Unavailable = false, false
+	// app list kept seeing the app while store did not serve it
+	gr.Delisted, gr.FirstSeen, gr.LastSeen = false, s.FirstSeen, s.LastSeen
+	return steam.store.SaveAppsSeen("steam", map[int]store.AppSeen{gr.ID: s})
+}
+
+// recheckRecords lists apps whose records were fetched RecheckInterval ago
+// or earlier, they are fetched again to follow detail changes and find apps
+// store stopped serving. Apps not released yet or without an exact release
+// date are fetched every ReleaseRecheckInterval until their date is settled,
+// so are records stored before release dates were parsed.
+func (steam *SteamSeeker) recheckRecords(t time.Time) ([]int, error) {
+	var ret []int
+	err := steam.store.ForEachGameRecord("steam", func(subid string, r store.GameRecord) error {
+		age := t.Sub(r.Fetched)
+		stale := steam.config.RecheckInterval > 0 && age >= steam.config.RecheckInterval
+		unsettled := steam.config.ReleaseRecheckInterval > 0 && age >= steam.config.ReleaseRecheckInterval &&
+			!releaseSettled(r.Release)
+		if stale || unsettled {
+			ret = append(ret, r.ID)
+		}
+		return nil
+	})
+	return ret, err
+}
+
+// releaseSettled tells if a release date is not expected to change, the app
+// is out and its day is known, or store tells no date at all. Records stored
+// before release dates were parsed have no precision, they are not settled.
+func releaseSettled(d store.ReleaseDate) bool {
+	if d.ComingSoon {
+		return false
+	}
+	return d.Precision == store.PrecisionDay || d.Precision == store.PrecisionUnknown
+}
@@ -0,0 +1,146 @@
+package seeker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ksang/gamecha/release"
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestTrackListing(t *testing.T) {
+	db := storetest.New(t, store.GameRecord{ID: 10}, store.GameRecord{ID: 20})
+	t1 := time.Unix(1500000000, 0)
+	steam := newSteamSeeker(SteamConfig{WorkerNum: 1}, db)
+	var tests = []struct {
+		listed   map[int]string
+		changed  int
+		delisted map[int]bool
+	}{
+		{map[int]string{10: "CS", 20: "TFC", 30: "DoD"}, 0, map[int]bool{10: false, 20: false}},
+		// 20 and 30 gone, only 20 has a record
+		{map[int]string{10: "CS"}, 2, map[int]bool{10: false, 20: true}},
+		// broken empty list changes nothing
+		{map[int]string{}, 0, map[int]bool{10: false, 20: true}},
+		{map[int]string{10: "CS"}, 0, map[int]bool{10: false, 20: true}},
+		// 20 is back
+		{map[int]string{10: "CS", 20: "TFC"}, 1, map[int]bool{10: false, 20: false}},
+	}
+	for caseid, c := range tests {
+		now := t1.Add(time.Duration(caseid) * time.Hour)
+		changed, err := steam.trackListing(c.listed, now)
+		if err != nil || changed != c.changed {
+			t.Errorf("case #%d, got changed: %d %v, expected: %d", caseid+1, changed, err, c.changed)
+		}
+		for id, delisted := range c.delisted {
+			gr, _ := db.GetGameRecord("steam", strconv.Itoa(id))
+			if gr.Delisted != delisted {
+				t.Errorf("case #%d, app %d got delisted: %v, expected: %v", caseid+1, id, gr.Delisted, delisted)
+			}
+		}
+	}
+	gr, _ := db.GetGameRecord("steam", "20")
+	if !gr.FirstSeen.Equal(t1) || !gr.LastSeen.Equal(t1.Add(4*time.Hour)) {
+		t.Errorf("got first seen: %v last seen: %v", gr.FirstSeen, gr.LastSeen)
+	}
+	seen, _ := db.GetAppsSeen("steam")
+	if s := seen[30]; !s.Delisted || !s.LastSeen.Equal(t1) {
+		t.Errorf("got seen of app 30: %v", s)
+	}
+}
+
+func TestGetSteamAppDetailUnavailable(t *testing.T) {
+	t1 := time.Unix(1500000000, 0)
+	db := storetest.New(t, store.GameRecord{ID: 10, Name: "CS", Cluster: 2})
+	available := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("appids")
+		if !available {
+			w.Write([]byte(`{"` + id + `":{"success":false}}`))
+			return
+		}
+		w.Write([]byte(`{"` + id + `":{"success":true,"data":{"type":"game","name":"CS","steam_appid":` + id + `,"required_age":0}}}`))
+	}))
+	defer ts.Close()
+	defer func(p string) { pathGetAppDetail = p }(pathGetAppDetail)
+	pathGetAppDetail = ts.URL
+
+	steam := newSteamSeeker(SteamConfig{WorkerNum: 1}, db)
+	if _, err := steam.trackListing(map[int]string{10: "CS"}, t1); err != nil {
+		t.Fatalf("trackListing err: %v", err)
+	}
+	if err := steam.getSteamAppDetail(context.Background(), 10); err != nil {
+		t.Errorf("getSteamAppDetail of stored app err: %v", err)
+	}
+	if gr, _ := db.GetGameRecord("steam", "10"); !gr.Delisted || !gr.FirstSeen.Equal(t1) || !gr.LastSeen.Equal(t1) {
+		t.Errorf("stored app not delisted: %v", gr)
+	}
+	seen, _ := db.GetAppsSeen("steam")
+	if s := seen[10]; !s.Delisted || !s.Unavailable || !s.LastSeen.Equal(t1) {
+		t.Errorf("got seen of app 10: %v", s)
+	}
+	if err := steam.getSteamAppDetail(context.Background(), 20); err != ErrSteamFailReponse {
+		t.Errorf("getSteamAppDetail of new app got err: %v, expected: %v", err, ErrSteamFailReponse)
+	}
+
+	// still in app list, but not served by store
+	if _, err := steam.trackListing(map[int]string{10: "CS"}, t1.Add(time.Hour)); err != nil {
+		t.Fatalf("trackListing err: %v", err)
+	}
+	if gr, _ := db.GetGameRecord("steam", "10"); !gr.Delisted {
+		t.Errorf("unavailable app restored by listing: %v", gr)
+	}
+
+	available = true
+	if err := steam.getSteamAppDetail(context.Background(), 10); err != nil {
+		t.Fatalf("getSteamAppDetail err: %v", err)
+	}
+	if err := steam.saveRecord(<-steam.workerReturn); err != nil {
+		t.Fatalf("saveRecord err: %v", err)
+	}
+	gr, _ := db.GetGameRecord("steam", "10")
+	if gr.Delisted || gr.Cluster != 2 || !gr.LastSeen.Equal(t1.Add(time.Hour)) || gr.Fetched.IsZero() {
+		t.Errorf("got record served again: %v", gr)
+	}
+	seen, _ = db.GetAppsSeen("steam")
+	if s := seen[10]; s.Delisted || s.Unavailable {
+		t.Errorf("got seen of app 10: %v", s)
+	}
+}
+
+func TestRecheckRecords(t *testing.T) {
+	now := time.Now()
+	db := storetest.New(t,
+		store.GameRecord{ID: 10, Fetched: now.Add(-time.Hour), Release: release.Parse("1 Nov, 2000", false)},
+		store.GameRecord{ID: 20, Fetched: now.Add(-800 * time.Hour), Release: release.Parse("1 Nov, 2000", false)},
+		store.GameRecord{ID: 30},
+		store.GameRecord{ID: 40, Fetched: now.Add(-30 * time.Hour), Release: release.Parse("1 Jan, 2030", true)},
+		store.GameRecord{ID: 50, Fetched: now.Add(-time.Hour), Release: release.Parse("Nov 2020", false)},
+		store.GameRecord{ID: 60, Fetched: now.Add(-30 * time.Hour), Release: release.Parse("", false)},
+		store.GameRecord{ID: 70, Fetched: now.Add(-30 * time.Hour), ReleaseYear: 2015},
+	)
+	var tests = []struct {
+		interval        time.Duration
+		releaseInterval time.Duration
+		ids             []int
+	}{
+		{720 * time.Hour, 24 * time.Hour, []int{20, 30, 40, 70}},
+		{0, 24 * time.Hour, []int{30, 40, 70}},
+		{0, 0, nil},
+	}
+	for caseid, c := range tests {
+		steam := newSteamSeeker(SteamConfig{WorkerNum: 1, RecheckInterval: c.interval, ReleaseRecheckInterval: c.releaseInterval}, db)
+		ids, err := steam.recheckRecords(now)
+		sort.Ints(ids)
+		if err != nil || !reflect.DeepEqual(ids, c.ids) {
+			t.Errorf("case #%d, got: %v %v, expected: %v", caseid+1, ids, err, c.ids)
+		}
+	}
+}
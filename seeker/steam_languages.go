@@ -0,0 +1,161 @@
+package seeker
+
+import (
+	"strings"
+
+	"github.com/ksang/gamecha/sanitize"
+	"github.com/ksang/gamecha/store"
+)
+
+var (
+	// steamLanguageCodes maps steam store language names to ISO 639-1 codes,
+	// with region subtags where steam distinguishes variants. Filipino has
+	// no ISO 639-1 code, it is mapped to Tagalog it is based on.
+	steamLanguageCodes = map[string]string{
+		"arabic":                  "ar",
+		"bangla":                  "bn",
+		"bulgarian":               "bg",
+		"catalan":                 "ca",
+		"croatian":                "hr",
+		"czech":                   "cs",
+		"danish":                  "da",
+		"dutch":                   "nl",
+		"english":                 "en",
+		"estonian":                "et",
+		"filipino":                "tl",
+		"finnish":                 "fi",
+		"french":                  "fr",
+		"german":                  "de",
+		"greek":                   "el",
+		"hebrew":                  "he",
+		"hindi":                   "hi",
+		"hungarian":               "hu",
+		"icelandic":               "is",
+		"indonesian":              "id",
+		"italian":                 "it",
+		"japanese":                "ja",
+		"korean":                  "ko",
+		"latvian":                 "lv",
+		"lithuanian":              "lt",
+		"malay":                   "ms",
+		"norwegian":               "no",
+		"persian":                 "fa",
+		"polish":                  "pl",
+		"portuguese":              "pt",
+		"portuguese - brazil":     "pt-BR",
+		"portuguese - portugal":   "pt-PT",
+		"romanian":                "ro",
+		"russian":                 "ru",
+		"serbian":                 "sr",
+		"simplified chinese":      "zh-CN",
+		"slovak":                  "sk",
+		"slovenian":               "sl",
+		"spanish":                 "es",
+		"spanish - latin america": "es-419",
+		"spanish - spain":         "es-ES",
+		"swedish":                 "sv",
+		"thai":                    "th",
+		"traditional chinese":     "zh-TW",
+		"turkish":                 "tr",
+		"ukrainian":               "uk",
+		"vietnamese":              "vi",
+	}
+	// steamAPILanguageCodes maps language names of steam web api, passed as
+	// l parameter, to codes where they differ from store language names
+	steamAPILanguageCodes = map[string]string{
+		"brazilian":  "pt-BR",
+		"koreana":    "ko",
+		"latam":      "es-419",
+		"portuguese": "pt-PT",
+		"schinese":   "zh-CN",
+		"spanish":    "es-ES",
+		"tchinese":   "zh-TW",
+	}
+	// steamLanguageMarkers are the characters marking languages in the list,
+	// footnotes after the list explain them
+	steamLanguageMarkers = "*†‡"
+)
+
+// Language features marked in supported_languages
+const (
+	languageInterface = "interface"
+	languageAudio     = "audio"
+	languageSubtitles = "subtitles"
+)
+
+// steamAPILanguageCode is the code of a steam web api language name, e.g.
+// schinese is zh-CN, unknown names are kept as they are
+func steamAPILanguageCode(lang string) string {
+	lang = strings.ToLower(lang)
+	if code, ok := steamAPILanguageCodes[lang]; ok {
+		return code
+	}
+	if code, ok := steamLanguageCodes[lang]; ok {
+		return code
+	}
+	return lang
+}
+
+// steamLanguageFeature is the feature explained by a footnote, e.g.
+// languages with full audio support, empty if unknown
+func steamLanguageFeature(note string) string {
+	note = strings.ToLower(note)
+	for _, f := range []string{languageAudio, languageSubtitles, languageInterface} {
+		if strings.Contains(note, f) {
+			return f
+		}
+	}
+	return ""
+}
+
+// parseSteamLanguages parses appdetails supported_languages html, e.g.
+// English<strong>*</strong>, French<br><strong>*</strong>languages with full audio support
+// Lines after the list are footnotes explaining markers of languages, * is
+// full audio if no footnote tells otherwise. Listed languages have interface
+// and subtitles support unless a footnote marks the languages having them.
+// Unknown names are kept with empty code.
+func parseSteamLanguages(s string) []store.LanguageSupport {
+	lines := strings.Split(sanitize.Text(s), "\n")
+	markers := map[string]string{"*": languageAudio}
+	marked := make(map[string]bool)
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		note := strings.TrimLeft(line, steamLanguageMarkers)
+		marker := line[:len(line)-len(note)]
+		if f := steamLanguageFeature(note); marker != "" && f != "" {
+			markers[marker] = f
+			marked[f] = true
+		}
+	}
+	var ret []store.LanguageSupport
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(lines[0], ",") {
+		item = strings.TrimSpace(item)
+		name := strings.TrimSpace(strings.TrimRight(item, steamLanguageMarkers))
+		features := make(map[string]bool)
+		if marker := strings.TrimSpace(item[len(name):]); markers[marker] != "" {
+			features[markers[marker]] = true
+		} else {
+			for _, c := range marker {
+				features[markers[string(c)]] = true
+			}
+		}
+		code := steamLanguageCodes[strings.ToLower(name)]
+		key := code
+		if key == "" {
+			key = strings.ToLower(name)
+		}
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		ret = append(ret, store.LanguageSupport{
+			Code:      code,
+			Name:      name,
+			Interface: features[languageInterface] || !marked[languageInterface],
+			FullAudio: features[languageAudio],
+			Subtitles: features[languageSubtitles] || !marked[languageSubtitles],
+		})
+	}
+	return ret
+}
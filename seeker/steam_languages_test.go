@@ -0,0 +1,60 @@
+package seeker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+)
+
+func TestParseSteamLanguages(t *testing.T) {
+	var tests = []struct {
+		s string
+		l []store.LanguageSupport
+	}{
+		{
+			"English<strong>*</strong>, French<strong>*</strong>, Spanish - Spain, Simplified Chinese<br><strong>*</strong>languages with full audio support",
+			[]store.LanguageSupport{
+				{Code: "en", Name: "English", Interface: true, FullAudio: true, Subtitles: true},
+				{Code: "fr", Name: "French", Interface: true, FullAudio: true, Subtitles: true},
+				{Code: "es-ES", Name: "Spanish - Spain", Interface: true, Subtitles: true},
+				{Code: "zh-CN", Name: "Simplified Chinese", Interface: true, Subtitles: true},
+			},
+		},
+		{
+			"English, Japanese*, Klingon",
+			[]store.LanguageSupport{
+				{Code: "en", Name: "English", Interface: true, Subtitles: true},
+				{Code: "ja", Name: "Japanese", Interface: true, FullAudio: true, Subtitles: true},
+				{Name: "Klingon", Interface: true, Subtitles: true},
+			},
+		},
+		{
+			"[b]Portuguese - Brazil[/b]*, english, English<br />*languages with full audio support",
+			[]store.LanguageSupport{
+				{Code: "pt-BR", Name: "Portuguese - Brazil", Interface: true, FullAudio: true, Subtitles: true},
+				{Code: "en", Name: "english", Interface: true, Subtitles: true},
+			},
+		},
+		{
+			"English<strong>*</strong>, German<strong>**</strong>, Filipino<br><strong>*</strong>languages with full audio support<br><strong>**</strong>languages with subtitles",
+			[]store.LanguageSupport{
+				{Code: "en", Name: "English", Interface: true, FullAudio: true},
+				{Code: "de", Name: "German", Interface: true, Subtitles: true},
+				{Code: "tl", Name: "Filipino", Interface: true},
+			},
+		},
+		{
+			"",
+			nil,
+		},
+	}
+
+	for caseid, c := range tests {
+		res := parseSteamLanguages(c.s)
+		if !reflect.DeepEqual(res, c.l) {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, res, c.l)
+		}
+		t.Logf("Result: %v", res)
+	}
+}
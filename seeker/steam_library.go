@@ -0,0 +1,146 @@
+package seeker
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/valyala/fastjson"
+)
+
+var (
+	pathGetOwnedGames          = "/IPlayerService/GetOwnedGames/v1/"
+	pathGetRecentlyPlayedGames = "/IPlayerService/GetRecentlyPlayedGames/v1/"
+)
+
+// ErrSteamNoKey indicates steam api called requires configured web api key
+var ErrSteamNoKey = errors.New("steam web api key not configured")
+
+// ImportLibrary fetches owned and recently played games of a steam user and
+// stores them as the user library. Owned apps missing from store are queued
+// for detail fetch on next seeker run.
+func (steam *SteamSeeker) ImportLibrary(ctx context.Context, steamid string) (*store.Library, error) {
+	if steam.config.Key == "" {
+		return nil, ErrSteamNoKey
+	}
+	var owned, recent []store.LibraryEntry
+	if err := steam.withRetry(ctx, "getOwnedGames", func() (err error) {
+		owned, err = steam.getSteamUserGames(ctx, pathGetOwnedGames, steamid)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if err := steam.withRetry(ctx, "getRecentlyPlayedGames", func() (err error) {
+		recent, err = steam.getSteamUserGames(ctx, pathGetRecentlyPlayedGames, steamid)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	lib := store.Library{
+		UserID:  steamid,
+		Updated: time.Now(),
+		Games:   mergeLibraryEntries(owned, recent),
+	}
+	var missing []int
+	for i, g := range lib.Games {
+		gr, err := steam.store.GetGameRecord("steam", strconv.Itoa(g.ID))
+		if err != nil {
+			return nil, err
+		}
+		lib.Games[i].Linked = gr.ID != 0
+		if gr.ID == 0 {
+			missing = append(missing, g.ID)
+		}
+	}
+	steam.infoLog.Printf("imported %d games of user %s, %d missing from store", len(lib.Games), steamid, len(missing))
+	if err := steam.store.QueueApps("steam", missing); err != nil {
+		return nil, err
+	}
+	if err := steam.store.SaveLibrary("steam", steamid, lib); err != nil {
+		return nil, err
+	}
+	return &lib, nil
+}
+
+// mergeLibraryEntries adds recent playtimes to owned games, recently played
+// games not owned, e.g. family shared ones, are appended
+func mergeLibraryEntries(owned, recent []store.LibraryEntry) []store.LibraryEntry {
+	idx := make(map[int]int, len(owned))
+	for i, g := range owned {
+		idx[g.ID] = i
+	}
+	for _, g := range recent {
+		if i, ok := idx[g.ID]; ok {
+			owned[i].PlaytimeRecent = g.PlaytimeRecent
+			continue
+		}
+		owned = append(owned, g)
+	}
+	return owned
+}
+
+func (steam *SteamSeeker) getSteamUserGames(ctx context.Context, path string, steamid string) ([]store.LibraryEntry, error) {
+	req, err := http.NewRequest("GET", steam.config.Portal+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Add("key", steam.config.Key)
+	q.Add("steamid", steamid)
+	q.Add("include_appinfo", "1")
+	q.Add("include_played_free_games", "1")
+	req.URL.RawQuery = q.Encode()
+	var games []store.LibraryEntry
+	if err := httpDo(ctx, req, steam.client, func(resp *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests:
+			return ErrSteamRateLimit
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ErrSteamFailReponse
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		games, err = parseSteamUserGames(body)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// parseSteamUserGames parses owned or recently played games response,
+// private profiles respond with no games
+func parseSteamUserGames(data []byte) ([]store.LibraryEntry, error) {
+	var p fastjson.Parser
+	root, err := p.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if !root.Exists("response") {
+		return nil, ErrSteamFailReponse
+	}
+	var ret []store.LibraryEntry
+	for _, g := range root.GetArray("response", "games") {
+		e := store.LibraryEntry{
+			ID:              g.GetInt("appid"),
+			Name:            string(g.GetStringBytes("name")),
+			PlaytimeForever: g.GetInt("playtime_forever"),
+			PlaytimeRecent:  g.GetInt("playtime_2weeks"),
+		}
+		if t := g.GetInt64("rtime_last_played"); t > 0 {
+			e.LastPlayed = time.Unix(t, 0)
+		}
+		ret = append(ret, e)
+	}
+	return ret, nil
+}
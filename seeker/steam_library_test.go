@@ -0,0 +1,86 @@
+package seeker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestParseSteamUserGames(t *testing.T) {
+	var tests = []struct {
+		s   string
+		r   []store.LibraryEntry
+		err error
+	}{
+		{`{"response":{}}`, nil, nil},
+		{`{}`, nil, ErrSteamFailReponse},
+		{
+			`{"response":{"game_count":2,"games":[
+				{"appid":10,"name":"Counter-Strike","playtime_forever":600,"rtime_last_played":1500000000},
+				{"appid":570,"name":"Dota 2","playtime_2weeks":30,"playtime_forever":45,"rtime_last_played":0}]}}`,
+			[]store.LibraryEntry{
+				{ID: 10, Name: "Counter-Strike", PlaytimeForever: 600, LastPlayed: time.Unix(1500000000, 0)},
+				{ID: 570, Name: "Dota 2", PlaytimeForever: 45, PlaytimeRecent: 30},
+			},
+			nil,
+		},
+	}
+	for caseid, c := range tests {
+		res, err := parseSteamUserGames([]byte(c.s))
+		if err != c.err || !reflect.DeepEqual(res, c.r) {
+			t.Errorf("case #%d, got: %v %v, expected: %v %v", caseid+1, res, err, c.r, c.err)
+		}
+	}
+}
+
+func TestImportLibrary(t *testing.T) {
+	db := storetest.New(t, store.GameRecord{ID: 10, Name: "Counter-Strike"})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "k" || r.URL.Query().Get("steamid") != "76561197960287930" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case pathGetOwnedGames:
+			w.Write([]byte(`{"response":{"game_count":2,"games":[
+				{"appid":10,"name":"Counter-Strike","playtime_forever":600},
+				{"appid":570,"name":"Dota 2","playtime_forever":45}]}}`))
+		case pathGetRecentlyPlayedGames:
+			w.Write([]byte(`{"response":{"total_count":2,"games":[
+				{"appid":570,"name":"Dota 2","playtime_2weeks":30,"playtime_forever":45},
+				{"appid":220,"name":"Half-Life 2","playtime_2weeks":60,"playtime_forever":60}]}}`))
+		}
+	}))
+	defer ts.Close()
+
+	steam := newSteamSeeker(SteamConfig{Portal: ts.URL, WorkerNum: 1}, db)
+	if _, err := steam.ImportLibrary(context.Background(), "76561197960287930"); err != ErrSteamNoKey {
+		t.Errorf("got err: %v, expected: %v", err, ErrSteamNoKey)
+	}
+	steam.config.Key = "k"
+	if _, err := steam.ImportLibrary(context.Background(), "76561197960287930"); err != nil {
+		t.Fatalf("ImportLibrary err: %v", err)
+	}
+	lib, err := db.GetLibrary("steam", "76561197960287930")
+	if err != nil {
+		t.Fatalf("GetLibrary err: %v", err)
+	}
+	expected := []store.LibraryEntry{
+		{ID: 10, Name: "Counter-Strike", PlaytimeForever: 600, Linked: true},
+		{ID: 570, Name: "Dota 2", PlaytimeForever: 45, PlaytimeRecent: 30},
+		{ID: 220, Name: "Half-Life 2", PlaytimeForever: 60, PlaytimeRecent: 60},
+	}
+	if !reflect.DeepEqual(lib.Games, expected) {
+		t.Errorf("got library: %v, expected: %v", lib.Games, expected)
+	}
+	queued, err := db.GetQueuedApps("steam")
+	if err != nil || !reflect.DeepEqual(queued, []int{220, 570}) {
+		t.Errorf("got queued: %v %v, expected: %v", queued, err, []int{220, 570})
+	}
+}
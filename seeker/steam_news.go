@@ -0,0 +1,147 @@
+package seeker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksang/gamecha/sanitize"
+	"github.com/ksang/gamecha/store"
+	"github.com/valyala/fastjson"
+)
+
+var (
+	pathGetNewsForApp = "/ISteamNews/GetNewsForApp/v2/"
+	newsPerPage       = 20
+)
+
+// CollectNews fetches news and patch notes of tracked apps, only news newer
+// than the stored ones are fetched, at most NewsCap items are kept per app
+func (steam *SteamSeeker) CollectNews(ctx context.Context) error {
+	if len(steam.config.Tracked) == 0 || steam.config.NewsCap <= 0 {
+		return nil
+	}
+	steam.infoLog.Printf("collecting news of %d tracked apps", len(steam.config.Tracked))
+	steam.runPool(ctx, "collectAppNews", steam.config.Tracked, steam.collectAppNews)
+	return nil
+}
+
+func (steam *SteamSeeker) collectAppNews(ctx context.Context, appid int) error {
+	ns, err := steam.store.GetNews("steam", appid)
+	if err != nil {
+		return err
+	}
+	var newest time.Time
+	known := make(map[string]bool, len(ns.Items))
+	for _, n := range ns.Items {
+		known[n.ID] = true
+		if n.Date.After(newest) {
+			newest = n.Date
+		}
+	}
+	var fresh []store.NewsItem
+	var enddate int64
+	done := false
+	for !done && len(fresh) < steam.config.NewsCap {
+		items, err := steam.getSteamNewsPage(ctx, appid, enddate)
+		if err != nil {
+			return err
+		}
+		if len(items) < newsPerPage {
+			done = true
+		}
+		for _, n := range items {
+			// news are ordered by date, newest first
+			if n.Date.Before(newest) || len(fresh) >= steam.config.NewsCap {
+				done = true
+				break
+			}
+			// stored news and the previous page may share a second with fresh news
+			if known[n.ID] {
+				continue
+			}
+			known[n.ID] = true
+			fresh = append(fresh, n)
+		}
+		if len(items) > 0 {
+			// next page starts at the second of the oldest item, the ones
+			// fetched already are skipped by id. A page within one second
+			// would be served again, so it is stepped past.
+			next := items[len(items)-1].Date.Unix()
+			if next == enddate {
+				next--
+			}
+			enddate = next
+		}
+	}
+	steam.debugLog.Printf("workerThread[%d] got %d new news of app: %d", ctx.Value(workerIDKey), len(fresh), appid)
+	ns.Items = append(fresh, ns.Items...)
+	if len(ns.Items) > steam.config.NewsCap {
+		ns.Items = ns.Items[:steam.config.NewsCap]
+	}
+	ns.Updated = time.Now()
+	return steam.store.SaveNews("steam", appid, *ns)
+}
+
+// getSteamNewsPage fetches news of app published at or before enddate, zero enddate is now
+func (steam *SteamSeeker) getSteamNewsPage(ctx context.Context, appid int, enddate int64) ([]store.NewsItem, error) {
+	req, err := http.NewRequest("GET", steam.config.Portal+pathGetNewsForApp, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Add("appid", strconv.Itoa(appid))
+	q.Add("count", strconv.Itoa(newsPerPage))
+	// full contents
+	q.Add("maxlength", "0")
+	if enddate > 0 {
+		q.Add("enddate", strconv.FormatInt(enddate, 10))
+	}
+	req.URL.RawQuery = q.Encode()
+	var items []store.NewsItem
+	if err := httpDo(ctx, req, steam.client, func(resp *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return ErrSteamRateLimit
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		items, err = parseSteamNews(body)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func parseSteamNews(data []byte) ([]store.NewsItem, error) {
+	var p fastjson.Parser
+	root, err := p.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if !root.Exists("appnews") {
+		return nil, ErrSteamFailReponse
+	}
+	var ret []store.NewsItem
+	for _, n := range root.GetArray("appnews", "newsitems") {
+		ret = append(ret, store.NewsItem{
+			ID:        string(n.GetStringBytes("gid")),
+			Title:     string(n.GetStringBytes("title")),
+			URL:       string(n.GetStringBytes("url")),
+			Author:    string(n.GetStringBytes("author")),
+			Feed:      string(n.GetStringBytes("feedname")),
+			FeedLabel: string(n.GetStringBytes("feedlabel")),
+			Date:      time.Unix(n.GetInt64("date"), 0),
+			Contents:  sanitize.Text(string(n.GetStringBytes("contents"))),
+		})
+	}
+	return ret, nil
+}
@@ -0,0 +1,145 @@
+package seeker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+// newsStandIn serves news with ids from newest down to 1, one per hour,
+// or two per hour if paired
+type newsStandIn struct {
+	sync.Mutex
+	newest int
+	paired bool
+	pages  int
+}
+
+func (ns *newsStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ns.Lock()
+	defer ns.Unlock()
+	ns.pages++
+	count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+	enddate, _ := strconv.Atoi(r.URL.Query().Get("enddate"))
+	var items []map[string]interface{}
+	for id := ns.newest; id > 0 && len(items) < count; id-- {
+		date := 1500000000 + id*3600
+		if ns.paired {
+			date = 1500000000 + (id+1)/2*3600
+		}
+		if enddate > 0 && date > enddate {
+			continue
+		}
+		items = append(items, map[string]interface{}{
+			"gid":       strconv.Itoa(id),
+			"title":     "Patch " + strconv.Itoa(id),
+			"url":       "https://steamstore-a.akamaihd.net/news/" + strconv.Itoa(id),
+			"author":    "Valve",
+			"contents":  "<p>Fixed <b>bugs</b></p>",
+			"feedlabel": "Product Update",
+			"date":      date,
+			"feedname":  "steam_updates",
+			"appid":     10,
+		})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"appnews": map[string]interface{}{"appid": 10, "newsitems": items, "count": ns.newest},
+	})
+}
+
+func TestCollectAppNews(t *testing.T) {
+	db := storetest.New(t)
+	standIn := &newsStandIn{}
+	ts := httptest.NewServer(standIn)
+	defer ts.Close()
+	defer func(n int) { newsPerPage = n }(newsPerPage)
+	newsPerPage = 2
+
+	steam := newSteamSeeker(SteamConfig{Portal: ts.URL, WorkerNum: 2, Tracked: []int{10}, NewsCap: 4}, db)
+	var tests = []struct {
+		newest int
+		pages  int
+		ids    []string
+	}{
+		// capped at 4 of 5 news, pages overlap by the item at their boundary
+		{5, 3, []string{"5", "4", "3", "2"}},
+		// two new items, fetching stops past the stored newest
+		{7, 3, []string{"7", "6", "5", "4"}},
+		// nothing new
+		{7, 1, []string{"7", "6", "5", "4"}},
+	}
+	for caseid, c := range tests {
+		standIn.newest, standIn.pages = c.newest, 0
+		if err := steam.CollectNews(context.Background()); err != nil {
+			t.Errorf("case #%d, CollectNews err: %v", caseid+1, err)
+		}
+		ns, err := db.GetNews("steam", 10)
+		if err != nil {
+			t.Errorf("case #%d, GetNews err: %v", caseid+1, err)
+			continue
+		}
+		var ids []string
+		for _, n := range ns.Items {
+			ids = append(ids, n.ID)
+		}
+		if standIn.pages != c.pages || len(ids) != len(c.ids) {
+			t.Errorf("case #%d, got pages: %d news: %v, expected: %d %v", caseid+1, standIn.pages, ids, c.pages, c.ids)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != c.ids[i] {
+				t.Errorf("case #%d, got news: %v, expected: %v", caseid+1, ids, c.ids)
+				break
+			}
+		}
+	}
+	ns, _ := db.GetNews("steam", 10)
+	expected := store.NewsItem{
+		ID:        "7",
+		Title:     "Patch 7",
+		URL:       "https://steamstore-a.akamaihd.net/news/7",
+		Author:    "Valve",
+		Feed:      "steam_updates",
+		FeedLabel: "Product Update",
+		Date:      time.Unix(1500000000+7*3600, 0),
+		Contents:  "Fixed bugs",
+	}
+	if n := ns.Items[0]; n != expected {
+		t.Errorf("got news: %#v, expected: %#v", n, expected)
+	}
+}
+
+func TestCollectAppNewsSameSecond(t *testing.T) {
+	db := storetest.New(t)
+	standIn := &newsStandIn{newest: 5, paired: true}
+	ts := httptest.NewServer(standIn)
+	defer ts.Close()
+	defer func(n int) { newsPerPage = n }(newsPerPage)
+	newsPerPage = 2
+
+	steam := newSteamSeeker(SteamConfig{Portal: ts.URL, WorkerNum: 1, Tracked: []int{10}, NewsCap: 4}, db)
+	if err := steam.CollectNews(context.Background()); err != nil {
+		t.Fatalf("CollectNews err: %v", err)
+	}
+	ns, err := db.GetNews("steam", 10)
+	if err != nil {
+		t.Fatalf("GetNews err: %v", err)
+	}
+	var ids []string
+	for _, n := range ns.Items {
+		ids = append(ids, n.ID)
+	}
+	// 3 shares its second with 4 ending the first page
+	if expected := []string{"5", "4", "3", "2"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("got news: %v, expected: %v", ids, expected)
+	}
+}
@@ -0,0 +1,114 @@
+package seeker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/ksang/gamecha/store"
+)
+
+var (
+	pathGetPackageDetail = "https://store.steampowered.com/api/packagedetails"
+)
+
+// packagedetails response parsing
+type steamPackageDetailResp map[string]steamPackageDetail
+
+type steamPackageDetail struct {
+	Success bool                   `json:"success"`
+	Data    steamPackageDetailData `json:"data"`
+}
+
+type steamPackageDetailData struct {
+	Name string `json:"name"`
+	Apps []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"apps"`
+	Price struct {
+		Currency string `json:"currency"`
+		Initial  int    `json:"initial"`
+		Final    int    `json:"final"`
+		Discount int    `json:"discount_percent"`
+	} `json:"price"`
+}
+
+// collectPackages saves details of packages of a fetched app, stored ones
+// are fetched again to follow prices and apps. Failures are logged and left
+// for next run.
+func (steam *SteamSeeker) collectPackages(ctx context.Context, ids []int) {
+	for _, id := range ids {
+		if _, fetched := steam.packages.LoadOrStore(id, true); fetched {
+			continue
+		}
+		var pr store.PackageRecord
+		if err := steam.withRetry(ctx, "getPackageDetail", func() (err error) {
+			pr, err = steam.fetchSteamPackage(ctx, id)
+			return err
+		}); err != nil {
+			steam.packages.Delete(id)
+			steam.debugLog.Printf("workerThread[%d] getting package detail: %d err: %v", ctx.Value(workerIDKey), id, err)
+			continue
+		}
+		if err := steam.store.SavePackageRecord("steam", pr); err != nil {
+			steam.infoLog.Printf("failed to save package record, packageid: %d", id)
+		}
+	}
+}
+
+func (steam *SteamSeeker) fetchSteamPackage(ctx context.Context, id int) (store.PackageRecord, error) {
+	req, err := http.NewRequest("GET", pathGetPackageDetail, nil)
+	if err != nil {
+		return store.PackageRecord{}, err
+	}
+	q := req.URL.Query()
+	q.Add("packageids", strconv.Itoa(id))
+	req.URL.RawQuery = q.Encode()
+	var pr store.PackageRecord
+	if err := httpDo(ctx, req, steam.client, func(resp *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return ErrSteamRateLimit
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		pr, err = parseSteamPackageDetail(id, body)
+		return err
+	}); err != nil {
+		return store.PackageRecord{}, err
+	}
+	return pr, nil
+}
+
+func parseSteamPackageDetail(id int, data []byte) (store.PackageRecord, error) {
+	var resp steamPackageDetailResp
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return store.PackageRecord{}, err
+	}
+	pd, ok := resp[strconv.Itoa(id)]
+	if !ok || !pd.Success {
+		return store.PackageRecord{}, ErrSteamFailReponse
+	}
+	pr := store.PackageRecord{
+		ID:   id,
+		Name: pd.Data.Name,
+		Price: store.PricePoint{
+			Currency: pd.Data.Price.Currency,
+			Initial:  pd.Data.Price.Initial,
+			Final:    pd.Data.Price.Final,
+			Discount: pd.Data.Price.Discount,
+		},
+	}
+	for _, a := range pd.Data.Apps {
+		pr.Apps = append(pr.Apps, store.PackageApp{ID: a.ID, Name: a.Name})
+	}
+	return pr, nil
+}
@@ -0,0 +1,109 @@
+package seeker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestParseSteamPackageDetail(t *testing.T) {
+	var tests = []struct {
+		id  int
+		s   string
+		r   store.PackageRecord
+		err error
+	}{
+		{
+			7,
+			`{"7": {"success": true, "data": {"name": "Counter-Strike: Condition Zero", "page_content": "",
+  "apps": [{"id": 10, "name": "Counter-Strike"}, {"id": 80, "name": "Counter-Strike: Condition Zero"}],
+  "price": {"currency": "CNY", "initial": 3700, "final": 3700, "discount_percent": 0, "individual": 7400},
+  "platforms": {"windows": true, "mac": true, "linux": true},
+  "release_date": {"coming_soon": false, "date": ""}}}}`,
+			store.PackageRecord{
+				ID:    7,
+				Name:  "Counter-Strike: Condition Zero",
+				Apps:  []store.PackageApp{{ID: 10, Name: "Counter-Strike"}, {ID: 80, Name: "Counter-Strike: Condition Zero"}},
+				Price: store.PricePoint{Currency: "CNY", Initial: 3700, Final: 3700},
+			},
+			nil,
+		},
+		{
+			8,
+			`{"8": {"success": false}}`,
+			store.PackageRecord{},
+			ErrSteamFailReponse,
+		},
+	}
+
+	for caseid, c := range tests {
+		res, err := parseSteamPackageDetail(c.id, []byte(c.s))
+		if err != c.err {
+			t.Errorf("case #%d, err: %v", caseid+1, err)
+		}
+		if !reflect.DeepEqual(res, c.r) {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, res, c.r)
+		}
+	}
+}
+
+func TestNewGameRecordRelations(t *testing.T) {
+	dataStr := `{"2028850": {"success": true, "data": {"type": "dlc", "name": "Soundtrack", "steam_appid": 2028850,
+  "required_age": 0, "fullgame": {"appid": "10", "name": "Counter-Strike"}, "dlc": [2028851, 2028852],
+  "packages": [7, 8],
+  "package_groups": [{"name": "default", "title": "Buy Soundtrack", "display_type": 0, "is_recurring_subscription": "false",
+    "subs": [{"packageid": 7, "percent_savings_text": " ", "option_text": "Soundtrack - ¥ 37", "can_get_free_license": "0",
+      "is_free_license": false, "price_in_cents_with_discount": 3700}]}]}}}`
+	steam := &SteamSeeker{}
+	res, err := steam.parseSteamAppDetail([]byte(dataStr))
+	if err != nil {
+		t.Fatalf("parseSteamAppDetail err: %v", err)
+	}
+	gr, err := steam.newGameRecord(res.Data)
+	if err != nil {
+		t.Fatalf("newGameRecord err: %v", err)
+	}
+	groups := []store.PackageGroup{
+		{Name: "default", Title: "Buy Soundtrack", Options: []store.PackageOption{{PackageID: 7, Text: "Soundtrack - ¥ 37", Price: 3700}}},
+	}
+	if gr.FullGame != 10 || !reflect.DeepEqual(gr.DLC, []int{2028851, 2028852}) ||
+		!reflect.DeepEqual(gr.Packages, []int{7, 8}) || !reflect.DeepEqual(gr.PackageGroups, groups) {
+		t.Errorf("got: %#v", gr)
+	}
+}
+
+func TestCollectPackages(t *testing.T) {
+	db := storetest.New(t)
+	if err := db.SavePackageRecord("steam", store.PackageRecord{ID: 7, Name: "CS", Price: store.PricePoint{Currency: "USD", Final: 999}}); err != nil {
+		t.Fatalf("SavePackageRecord err: %v", err)
+	}
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		id := r.URL.Query().Get("packageids")
+		fmt.Fprintf(w, `{%q: {"success": true, "data": {"name": "CS", "apps": [{"id": 10, "name": "Counter-Strike"}],
+			"price": {"currency": "USD", "initial": 999, "final": 499, "discount_percent": 50}}}}`, id)
+	}))
+	defer ts.Close()
+	defer func(p string) { pathGetPackageDetail = p }(pathGetPackageDetail)
+	pathGetPackageDetail = ts.URL
+
+	steam := newSteamSeeker(SteamConfig{WorkerNum: 1}, db)
+	ctx := context.WithValue(context.Background(), workerIDKey, 0)
+	// package shared by two apps is fetched once, stored one is updated
+	steam.collectPackages(ctx, []int{7})
+	steam.collectPackages(ctx, []int{7})
+	pr, err := db.GetPackageRecord("steam", 7)
+	if err != nil {
+		t.Fatalf("GetPackageRecord err: %v", err)
+	}
+	if pr.Price.Final != 499 || len(pr.Apps) != 1 || requests != 1 {
+		t.Errorf("got package: %v after %d requests, expected final price 499 after 1", pr, requests)
+	}
+}
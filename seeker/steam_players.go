@@ -0,0 +1,105 @@
+package seeker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/valyala/fastjson"
+)
+
+var (
+	pathGetCurrentPlayers = "/ISteamUserStats/GetNumberOfCurrentPlayers/v1/"
+)
+
+// SamplePlayers takes one concurrent player count sample of every tracked app,
+// stored samples are rolled up according to PlayerRetention
+func (steam *SteamSeeker) SamplePlayers(ctx context.Context) error {
+	if len(steam.config.Tracked) == 0 {
+		return nil
+	}
+	steam.infoLog.Printf("sampling players of %d tracked apps", len(steam.config.Tracked))
+	now := time.Now()
+	steam.runPool(ctx, "samplePlayers", steam.config.Tracked, func(ctx context.Context, appid int) error {
+		return steam.samplePlayers(ctx, appid, now)
+	})
+	return nil
+}
+
+// RunPlayerSampler samples players every PlayerInterval until context is done
+func (steam *SteamSeeker) RunPlayerSampler(ctx context.Context) error {
+	ticker := time.NewTicker(steam.config.PlayerInterval)
+	defer ticker.Stop()
+	for {
+		if err := steam.SamplePlayers(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (steam *SteamSeeker) samplePlayers(ctx context.Context, appid int, t time.Time) error {
+	count, err := steam.getSteamPlayerCount(ctx, appid)
+	if err != nil {
+		return err
+	}
+	samples, err := steam.store.GetPlayerSamples("steam", appid)
+	if err != nil {
+		return err
+	}
+	steam.debugLog.Printf("workerThread[%d] app: %d has %d players", ctx.Value(workerIDKey), appid, count)
+	samples = append(samples, store.PlayerSample{Time: t, Count: count})
+	samples = steam.config.PlayerRetention.Rollup(samples, t)
+	return steam.store.SavePlayerSamples("steam", appid, samples)
+}
+
+func (steam *SteamSeeker) getSteamPlayerCount(ctx context.Context, appid int) (int, error) {
+	req, err := http.NewRequest("GET", steam.config.Portal+pathGetCurrentPlayers, nil)
+	if err != nil {
+		return 0, err
+	}
+	q := req.URL.Query()
+	q.Add("appid", strconv.Itoa(appid))
+	if steam.config.Key != "" {
+		q.Add("key", steam.config.Key)
+	}
+	req.URL.RawQuery = q.Encode()
+	var count int
+	if err := httpDo(ctx, req, steam.client, func(resp *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return ErrSteamRateLimit
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		count, err = parseSteamPlayerCount(body)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func parseSteamPlayerCount(data []byte) (int, error) {
+	var p fastjson.Parser
+	root, err := p.ParseBytes(data)
+	if err != nil {
+		return 0, err
+	}
+	if root.GetInt("response", "result") != 1 {
+		return 0, ErrSteamFailReponse
+	}
+	return root.GetInt("response", "player_count"), nil
+}
@@ -0,0 +1,64 @@
+package seeker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestParseSteamPlayerCount(t *testing.T) {
+	var tests = []struct {
+		s   string
+		r   int
+		err error
+	}{
+		{`{"response":{"player_count":12345,"result":1}}`, 12345, nil},
+		{`{"response":{"result":42}}`, 0, ErrSteamFailReponse},
+	}
+	for caseid, c := range tests {
+		res, err := parseSteamPlayerCount([]byte(c.s))
+		if res != c.r || err != c.err {
+			t.Errorf("case #%d, got: %d %v, expected: %d %v", caseid+1, res, err, c.r, c.err)
+		}
+	}
+}
+
+func TestSamplePlayers(t *testing.T) {
+	db := storetest.New(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != pathGetCurrentPlayers || r.URL.Query().Get("key") != "k" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"response":{"player_count":%s0,"result":1}}`, r.URL.Query().Get("appid"))
+	}))
+	defer ts.Close()
+
+	steam := newSteamSeeker(SteamConfig{
+		Portal:          ts.URL,
+		Key:             "k",
+		WorkerNum:       2,
+		Tracked:         []int{10, 570},
+		PlayerRetention: RetentionPolicy{Raw: time.Hour},
+	}, db)
+	for i := 0; i < 2; i++ {
+		if err := steam.SamplePlayers(context.Background()); err != nil {
+			t.Fatalf("SamplePlayers err: %v", err)
+		}
+	}
+	for _, id := range steam.config.Tracked {
+		samples, err := db.GetPlayerSamples("steam", id)
+		if err != nil {
+			t.Errorf("app %d, GetPlayerSamples err: %v", id, err)
+			continue
+		}
+		if len(samples) != 2 || samples[0].Count != id*10 || samples[1].Time.Before(samples[0].Time) {
+			t.Errorf("app %d, got samples: %v", id, samples)
+		}
+	}
+}
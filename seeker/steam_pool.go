@@ -0,0 +1,38 @@
+package seeker
+
+import (
+	"context"
+	"sync"
+)
+
+// runPool calls fn for every app id using config number of workers,
+// each call is retried according to config. Failed apps are logged and skipped.
+func (steam *SteamSeeker) runPool(ctx context.Context, name string, ids []int, fn func(ctx context.Context, id int) error) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < steam.config.WorkerNum; i++ {
+		wg.Add(1)
+		wCtx := context.WithValue(ctx, workerIDKey, i)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				if err := steam.withRetry(wCtx, name, func() error {
+					return fn(wCtx, id)
+				}); err != nil && ctx.Err() == nil {
+					steam.infoLog.Printf("%s failed for app %d: %v", name, id, err)
+				}
+			}
+		}()
+	}
+loop:
+	for _, id := range ids {
+		select {
+		case jobs <- id:
+		case <-ctx.Done():
+			steam.infoLog.Printf("%s signaled to quit", name)
+			break loop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
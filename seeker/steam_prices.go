@@ -0,0 +1,151 @@
+package seeker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/valyala/fastjson"
+)
+
+var (
+	// priceBatchSize is the number of appids asked in one price_overview request
+	priceBatchSize = 100
+)
+
+// TrackPrices collects current prices of all saved steam apps
+// for every configured country and appends them to price histories
+func (steam *SteamSeeker) TrackPrices(ctx context.Context) error {
+	if len(steam.config.Countries) == 0 {
+		return nil
+	}
+	saved, err := steam.store.GetSavedGameList("steam")
+	if err != nil {
+		return err
+	}
+	ids := make([]int, 0, len(saved))
+	for id := range saved {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	now := time.Now()
+	for _, cc := range steam.config.Countries {
+		steam.infoLog.Printf("tracking prices of %d apps in region: %s", len(ids), cc)
+		for i := 0; i < len(ids); i += priceBatchSize {
+			end := i + priceBatchSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+			batch := ids[i:end]
+			if err := steam.withRetry(ctx, "getSteamPrices", func() error {
+				return steam.getSteamPrices(ctx, batch, cc, now)
+			}); err != nil {
+				steam.infoLog.Printf("skipped prices of apps %d-%d in region %s: %v", batch[0], batch[len(batch)-1], cc, err)
+			}
+			if ctx.Err() != nil {
+				steam.infoLog.Printf("price tracking signaled to quit")
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// withRetry calls fn until it succeeds, retrying according to config.
+// Rate limit errors do not count as retries.
+func (steam *SteamSeeker) withRetry(ctx context.Context, name string, fn func() error) error {
+	rc := steam.config.RetryCount
+	for i := 0; ; i++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if err == ErrSteamRateLimit {
+			i = 0
+		}
+		if rc > 0 && i >= rc {
+			return err
+		}
+		steam.debugLog.Printf("%s err: %v count: %d", name, err, i)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(steam.config.RetryInterval):
+		}
+	}
+}
+
+func (steam *SteamSeeker) getSteamPrices(ctx context.Context, appids []int, cc string, t time.Time) error {
+	req, err := http.NewRequest("GET", pathGetAppDetail, nil)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(appids))
+	for i, id := range appids {
+		ids[i] = strconv.Itoa(id)
+	}
+	q := req.URL.Query()
+	q.Add("appids", strings.Join(ids, ","))
+	q.Add("filters", "price_overview")
+	q.Add("cc", cc)
+	req.URL.RawQuery = q.Encode()
+	return httpDo(ctx, req, steam.client, func(resp *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return ErrSteamRateLimit
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		points, err := parseSteamPrices(body)
+		if err != nil {
+			return err
+		}
+		for id, p := range points {
+			p.Time = t
+			points[id] = p
+		}
+		return steam.store.SavePricePoints("steam", cc, points)
+	})
+}
+
+// parseSteamPrices parses a batched appdetails response filtered by price_overview.
+// Free and unavailable apps have no price and are left out of the result.
+func parseSteamPrices(data []byte) (map[int]store.PricePoint, error) {
+	var p fastjson.Parser
+	root, err := p.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := root.Object()
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[int]store.PricePoint)
+	obj.Visit(func(k []byte, v *fastjson.Value) {
+		po := v.Get("data", "price_overview")
+		if !v.GetBool("success") || po == nil {
+			return
+		}
+		id, err := strconv.Atoi(string(k))
+		if err != nil {
+			return
+		}
+		ret[id] = store.PricePoint{
+			Currency: string(po.GetStringBytes("currency")),
+			Initial:  po.GetInt("initial"),
+			Final:    po.GetInt("final"),
+			Discount: po.GetInt("discount_percent"),
+		}
+	})
+	return ret, nil
+}
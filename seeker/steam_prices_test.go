@@ -0,0 +1,58 @@
+package seeker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+)
+
+func TestParseSteamPrices(t *testing.T) {
+	var tests = []struct {
+		s string
+		p map[int]store.PricePoint
+	}{
+		{
+			`{
+  "10": {
+    "success": true,
+    "data": {
+      "price_overview": {
+        "currency": "CNY",
+        "initial": 3700,
+        "final": 1850,
+        "discount_percent": 50,
+        "initial_formatted": "¥ 37",
+        "final_formatted": "¥ 18.50"
+      }
+    }
+  },
+  "570": {
+    "success": true,
+    "data": []
+  },
+  "1": {
+    "success": false
+  }
+}`,
+			map[int]store.PricePoint{
+				10: {Currency: "CNY", Initial: 3700, Final: 1850, Discount: 50},
+			},
+		},
+		{
+			`{}`,
+			map[int]store.PricePoint{},
+		},
+	}
+
+	for caseid, c := range tests {
+		res, err := parseSteamPrices([]byte(c.s))
+		if err != nil {
+			t.Errorf("case #%d, err: %v", caseid+1, err)
+		}
+		if !reflect.DeepEqual(res, c.p) {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, res, c.p)
+		}
+		t.Logf("Result: %v", res)
+	}
+}
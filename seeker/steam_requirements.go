@@ -0,0 +1,247 @@
+package seeker
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ksang/gamecha/sanitize"
+	"github.com/ksang/gamecha/store"
+)
+
+var (
+	reqHeadingRe     = regexp.MustCompile(`(?i)\b(minimum|recommended)\s*:`)
+	reqLabelRe       = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z /]{1,30}?)\s*:\s*(.*)$`)
+	reqSizeRe        = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(TB|GB|MB|KB|G|M)\b`)
+	reqRAMRe         = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(TB|GB|MB|G|M)\b\+?\s*(?:of\s+)?(?:system\s+)?(?:RAM|memory)`)
+	reqDiskRe        = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(TB|GB|MB|G|M)\b\+?\s*(?:of\s+)?(?:available\s+|free\s+)?(?:hard\s+(?:drive|disk)\s+|hdd\s+|disk\s+)?(?:space|storage|hdd|hard drive|disk)`)
+	reqVRAMRe        = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(GB|MB|G|M)\b\+?\s*(?:of\s+)?(?:vram|video\s+(?:card|memory|ram)|graphics\s+(?:card|memory)|dedicated)`)
+	reqDirectXRe     = regexp.MustCompile(`(?i)direct\s?x\s*:?\s*(?:version\s*)?(\d+(?:\.\d+)?[a-c]?)`)
+	reqOpenGLRe      = regexp.MustCompile(`(?i)opengl\s*:?\s*(?:version\s*)?(\d+(?:\.\d+)?)`)
+	reqVersionRe     = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?[a-c]?)`)
+	reqOSRe          = regexp.MustCompile(`(?i)(windows|win ?(?:xp|vista|7|8|10|11)|mac ?os|os x|ubuntu|linux|steamos|debian|fedora|mint)[^,;(]*`)
+	reqCPURe         = regexp.MustCompile(`(?i)[^,;]*(processor|cpu|ghz|mhz|core i\d|ryzen|pentium|athlon|dual[- ]core|quad[- ]core)[^,;]*`)
+	reqGPURe         = regexp.MustCompile(`(?i)[^,;]*(video card|graphics|nvidia|geforce|radeon|ati |intel hd|gtx|rtx)[^,;]*`)
+	reqLabelToFields = map[string]string{
+		"os":                 "os",
+		"operating system":   "os",
+		"processor":          "cpu",
+		"cpu":                "cpu",
+		"memory":             "ram",
+		"ram":                "ram",
+		"system memory":      "ram",
+		"graphics":           "gpu",
+		"video card":         "gpu",
+		"video":              "gpu",
+		"graphics card":      "gpu",
+		"gpu":                "gpu",
+		"video memory":       "vram",
+		"vram":               "vram",
+		"directx":            "directx",
+		"direct x":           "directx",
+		"opengl":             "opengl",
+		"storage":            "disk",
+		"hard drive":         "disk",
+		"hard disk":          "disk",
+		"hard disk space":    "disk",
+		"hard drive space":   "disk",
+		"disk space":         "disk",
+		"free disk space":    "disk",
+		"hdd":                "disk",
+		"available space":    "disk",
+		"hard disk drive":    "disk",
+		"free hard drive":    "disk",
+		"storage space":      "disk",
+		"disk":               "disk",
+		"additional notes":   "",
+		"sound card":         "",
+		"network":            "",
+		"vr support":         "",
+		"additional":         "",
+		"peripherals":        "",
+		"input":              "",
+		"partner requires":   "",
+		"other requirements": "",
+	}
+)
+
+// parseSteamRequirements parses pc, mac or linux requirements of appdetails.
+// Steam sends either {"minimum": html, "recommended": html} or an empty array.
+// Some apps have both levels inside minimum html, they are split by headings.
+func parseSteamRequirements(v interface{}) *store.Requirements {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	min, _ := m["minimum"].(string)
+	rec, _ := m["recommended"].(string)
+	if rec == "" {
+		min, rec = splitRequirementLevels(min)
+	}
+	if strings.TrimSpace(min) == "" && strings.TrimSpace(rec) == "" {
+		return nil
+	}
+	return &store.Requirements{
+		Minimum:     parseHardwareSpec(min),
+		Recommended: parseHardwareSpec(rec),
+	}
+}
+
+// splitRequirementLevels splits requirement html holding both minimum and recommended levels
+func splitRequirementLevels(s string) (string, string) {
+	text := requirementText(s)
+	locs := reqHeadingRe.FindAllStringSubmatchIndex(text, -1)
+	min, rec := text, ""
+	for i, loc := range locs {
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		switch strings.ToLower(text[loc[2]:loc[3]]) {
+		case "minimum":
+			min = text[loc[1]:end]
+		case "recommended":
+			rec = text[loc[1]:end]
+			if i == 0 {
+				min = text[:loc[0]]
+			}
+		}
+	}
+	return min, rec
+}
+
+// requirementText converts requirement html to trimmed non-empty plain text lines,
+// list items are not marked
+func requirementText(s string) string {
+	var lines []string
+	for _, line := range strings.Split(sanitize.Text(s), "\n") {
+		if line = strings.TrimSpace(strings.TrimPrefix(line, "- ")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseHardwareSpec extracts hardware fields from one requirement level.
+// Labeled lines like "Memory: 8 GB RAM" are preferred, otherwise fields are
+// searched in free text. Required fields missing or unparsable are listed in Unparsed.
+func parseHardwareSpec(s string) store.HardwareSpec {
+	text := strings.TrimSpace(reqHeadingRe.ReplaceAllString(requirementText(s), ""))
+	spec := store.HardwareSpec{Raw: text}
+	if text == "" {
+		return spec
+	}
+	labeled := make(map[string]string)
+	var free []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if m := reqLabelRe.FindStringSubmatch(line); m != nil {
+			if field, ok := reqLabelToFields[strings.ToLower(strings.TrimSpace(m[1]))]; ok {
+				if _, ok := labeled[field]; field != "" && !ok {
+					labeled[field] = strings.TrimSpace(m[2])
+				}
+				continue
+			}
+		}
+		free = append(free, line)
+	}
+	freeText := strings.Join(free, ", ")
+
+	if v, ok := labeled["os"]; ok {
+		spec.OS = v
+	} else if m := reqOSRe.FindString(freeText); m != "" {
+		spec.OS = strings.TrimSpace(m)
+	}
+	if v, ok := labeled["cpu"]; ok {
+		spec.CPU = v
+	} else if m := reqCPURe.FindString(freeText); m != "" {
+		spec.CPU = strings.TrimSpace(m)
+	}
+	if v, ok := labeled["gpu"]; ok {
+		spec.GPU = v
+	} else if m := reqGPURe.FindString(freeText); m != "" {
+		spec.GPU = strings.TrimSpace(m)
+	}
+	if v, ok := labeled["ram"]; ok {
+		spec.RAM = sizeMB(reqSizeRe.FindStringSubmatch(v))
+	} else {
+		spec.RAM = sizeMB(reqRAMRe.FindStringSubmatch(freeText))
+	}
+	if v, ok := labeled["disk"]; ok {
+		spec.Disk = sizeMB(reqSizeRe.FindStringSubmatch(v))
+	} else {
+		spec.Disk = sizeMB(reqDiskRe.FindStringSubmatch(freeText))
+	}
+	if v, ok := labeled["vram"]; ok {
+		spec.VRAM = sizeMB(reqSizeRe.FindStringSubmatch(v))
+	} else if m := reqVRAMRe.FindStringSubmatch(text); m != nil {
+		spec.VRAM = sizeMB(m)
+	} else if m := reqSizeRe.FindStringSubmatch(spec.GPU); m != nil && spec.GPU != "" {
+		// vram is often given with the card, e.g. GTX 770 2GB
+		spec.VRAM = sizeMB(m)
+	}
+	if v, ok := labeled["directx"]; ok {
+		spec.DirectX = firstGroup(reqVersionRe.FindStringSubmatch(v))
+	} else {
+		spec.DirectX = firstGroup(reqDirectXRe.FindStringSubmatch(text))
+	}
+	if v, ok := labeled["opengl"]; ok {
+		spec.OpenGL = firstGroup(reqVersionRe.FindStringSubmatch(v))
+	} else {
+		spec.OpenGL = firstGroup(reqOpenGLRe.FindStringSubmatch(text))
+	}
+
+	// fields every requirement should have, and labeled fields that failed
+	isLabeled := func(field string) bool {
+		_, ok := labeled[field]
+		return ok
+	}
+	for _, f := range []struct {
+		name    string
+		missing bool
+	}{
+		{"os", spec.OS == ""},
+		{"cpu", spec.CPU == ""},
+		{"ram", spec.RAM == 0},
+		{"gpu", spec.GPU == ""},
+		{"vram", isLabeled("vram") && spec.VRAM == 0},
+		{"directx", isLabeled("directx") && spec.DirectX == ""},
+		{"opengl", isLabeled("opengl") && spec.OpenGL == ""},
+		{"disk", spec.Disk == 0},
+	} {
+		if f.missing {
+			spec.Unparsed = append(spec.Unparsed, f.name)
+		}
+	}
+	return spec
+}
+
+// sizeMB converts a size match of number and unit to megabytes
+func sizeMB(m []string) int {
+	if len(m) < 3 {
+		return 0
+	}
+	n, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+	if err != nil {
+		return 0
+	}
+	switch strings.ToUpper(m[2]) {
+	case "TB":
+		n *= 1024 * 1024
+	case "GB", "G":
+		n *= 1024
+	case "KB":
+		n /= 1024
+	}
+	return int(n)
+}
+
+func firstGroup(m []string) string {
+	if len(m) < 2 {
+		return ""
+	}
+	return m[1]
+}
@@ -0,0 +1,101 @@
+package seeker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+)
+
+func TestParseSteamRequirements(t *testing.T) {
+	var tests = []struct {
+		v interface{}
+		r *store.Requirements
+	}{
+		{
+			// both levels inside minimum, free text
+			map[string]interface{}{
+				"minimum": "\r\n\t\t\t<p><strong>Minimum:</strong> 500 mhz processor, 96mb ram, 16mb video card, Windows XP, Mouse, Keyboard, Internet Connection<br /></p>\r\n\t\t\t<p><strong>Recommended:</strong> 800 mhz processor, 128mb ram, 32mb+ video card, Windows XP, Mouse, Keyboard, Internet Connection<br /></p>\r\n\t\t\t",
+			},
+			&store.Requirements{
+				Minimum: store.HardwareSpec{
+					OS:       "Windows XP",
+					CPU:      "500 mhz processor",
+					RAM:      96,
+					GPU:      "16mb video card",
+					VRAM:     16,
+					Raw:      "500 mhz processor, 96mb ram, 16mb video card, Windows XP, Mouse, Keyboard, Internet Connection",
+					Unparsed: []string{"disk"},
+				},
+				Recommended: store.HardwareSpec{
+					OS:       "Windows XP",
+					CPU:      "800 mhz processor",
+					RAM:      128,
+					GPU:      "32mb+ video card",
+					VRAM:     32,
+					Raw:      "800 mhz processor, 128mb ram, 32mb+ video card, Windows XP, Mouse, Keyboard, Internet Connection",
+					Unparsed: []string{"disk"},
+				},
+			},
+		},
+		{
+			map[string]interface{}{
+				"minimum": "Minimum: Linux Ubuntu 12.04, Dual-core from Intel or AMD at 2.8 GHz, 1GB Memory, nVidia GeForce 8600/9600GT, ATI/AMD Radeaon HD2600/3600 (Graphic Drivers: nVidia 310, AMD 12.11), OpenGL 2.1, 4GB Hard Drive Space, OpenAL Compatible Sound Card",
+			},
+			&store.Requirements{
+				Minimum: store.HardwareSpec{
+					OS:     "Linux Ubuntu 12.04",
+					CPU:    "Dual-core from Intel or AMD at 2.8 GHz",
+					RAM:    1024,
+					GPU:    "nVidia GeForce 8600/9600GT",
+					OpenGL: "2.1",
+					Disk:   4096,
+					Raw:    "Linux Ubuntu 12.04, Dual-core from Intel or AMD at 2.8 GHz, 1GB Memory, nVidia GeForce 8600/9600GT, ATI/AMD Radeaon HD2600/3600 (Graphic Drivers: nVidia 310, AMD 12.11), OpenGL 2.1, 4GB Hard Drive Space, OpenAL Compatible Sound Card",
+				},
+			},
+		},
+		{
+			// labeled list of current store pages
+			map[string]interface{}{
+				"minimum":     `<strong>Minimum:</strong><br><ul class="bb_ul"><li>Requires a 64-bit processor and operating system<br></li><li><strong>OS:</strong> Windows 10 64-bit<br></li><li><strong>Processor:</strong> Intel Core i5-2500K / AMD FX-6300<br></li><li><strong>Memory:</strong> 8 GB RAM<br></li><li><strong>Graphics:</strong> NVIDIA GeForce GTX 770 2GB / AMD Radeon R9 280 3GB<br></li><li><strong>DirectX:</strong> Version 11<br></li><li><strong>Storage:</strong> 50 GB available space</li></ul>`,
+				"recommended": `<strong>Recommended:</strong><br><ul class="bb_ul"><li><strong>OS:</strong> Windows 10 64-bit<br></li><li><strong>Processor:</strong> Intel Core i7-4770K<br></li><li><strong>Memory:</strong> lots of RAM<br></li><li><strong>Graphics:</strong> NVIDIA GeForce GTX 1060<br></li><li><strong>DirectX:</strong> Version 12<br></li><li><strong>Storage:</strong> 1.5 TB SSD</li></ul>`,
+			},
+			&store.Requirements{
+				Minimum: store.HardwareSpec{
+					OS:      "Windows 10 64-bit",
+					CPU:     "Intel Core i5-2500K / AMD FX-6300",
+					RAM:     8192,
+					GPU:     "NVIDIA GeForce GTX 770 2GB / AMD Radeon R9 280 3GB",
+					VRAM:    2048,
+					DirectX: "11",
+					Disk:    51200,
+					Raw:     "Requires a 64-bit processor and operating system\nOS: Windows 10 64-bit\nProcessor: Intel Core i5-2500K / AMD FX-6300\nMemory: 8 GB RAM\nGraphics: NVIDIA GeForce GTX 770 2GB / AMD Radeon R9 280 3GB\nDirectX: Version 11\nStorage: 50 GB available space",
+				},
+				Recommended: store.HardwareSpec{
+					OS:       "Windows 10 64-bit",
+					CPU:      "Intel Core i7-4770K",
+					GPU:      "NVIDIA GeForce GTX 1060",
+					DirectX:  "12",
+					Disk:     1572864,
+					Raw:      "OS: Windows 10 64-bit\nProcessor: Intel Core i7-4770K\nMemory: lots of RAM\nGraphics: NVIDIA GeForce GTX 1060\nDirectX: Version 12\nStorage: 1.5 TB SSD",
+					Unparsed: []string{"ram"},
+				},
+			},
+		},
+		{
+			[]interface{}{},
+			nil,
+		},
+		{
+			map[string]interface{}{"minimum": ""},
+			nil,
+		},
+	}
+
+	for caseid, c := range tests {
+		res := parseSteamRequirements(c.v)
+		if !reflect.DeepEqual(res, c.r) {
+			t.Errorf("case #%d, got: %#v, expected: %#v", caseid+1, res, c.r)
+		}
+	}
+}
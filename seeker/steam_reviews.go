@@ -0,0 +1,178 @@
+package seeker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/valyala/fastjson"
+)
+
+var (
+	pathGetAppReviews = "https://store.steampowered.com/appreviews/"
+	reviewsPerPage    = 100
+)
+
+// steamReviewPage is one page of appreviews response
+type steamReviewPage struct {
+	summary *store.ReviewSummary
+	reviews []store.Review
+	cursor  string
+}
+
+// CollectReviews fetches user reviews of tracked apps, only reviews created
+// or edited since the stored ones are fetched, at most ReviewCap reviews are
+// kept per app
+func (steam *SteamSeeker) CollectReviews(ctx context.Context) error {
+	if len(steam.config.Tracked) == 0 || steam.config.ReviewCap <= 0 {
+		return nil
+	}
+	steam.infoLog.Printf("collecting reviews of %d tracked apps", len(steam.config.Tracked))
+	steam.runPool(ctx, "collectAppReviews", steam.config.Tracked, steam.collectAppReviews)
+	return nil
+}
+
+func (steam *SteamSeeker) collectAppReviews(ctx context.Context, appid int) error {
+	rs, err := steam.store.GetReviews("steam", appid)
+	if err != nil {
+		return err
+	}
+	var latest time.Time
+	for _, r := range rs.Reviews {
+		if r.Updated.After(latest) {
+			latest = r.Updated
+		}
+	}
+	var fresh []store.Review
+	fetched := make(map[string]bool)
+	cursor := "*"
+	done := false
+	for !done && len(fresh) < steam.config.ReviewCap {
+		page, err := steam.getSteamReviewPage(ctx, appid, cursor)
+		if err != nil {
+			return err
+		}
+		if page.summary != nil {
+			rs.Summary = *page.summary
+		}
+		for _, r := range page.reviews {
+			// reviews are ordered by update time, latest first. The ones
+			// updated in the second of the stored latest are fetched again,
+			// they replace the stored ones of the same id.
+			if r.Updated.Before(latest) || len(fresh) >= steam.config.ReviewCap {
+				done = true
+				break
+			}
+			if !fetched[r.ID] {
+				fetched[r.ID] = true
+				fresh = append(fresh, r)
+			}
+		}
+		if len(page.reviews) == 0 || page.cursor == "" || page.cursor == cursor {
+			done = true
+		}
+		cursor = page.cursor
+	}
+	steam.debugLog.Printf("workerThread[%d] got %d new or edited reviews of app: %d", ctx.Value(workerIDKey), len(fresh), appid)
+	for _, r := range rs.Reviews {
+		if !fetched[r.ID] {
+			fresh = append(fresh, r)
+		}
+	}
+	rs.Reviews = fresh
+	if len(rs.Reviews) > steam.config.ReviewCap {
+		rs.Reviews = rs.Reviews[:steam.config.ReviewCap]
+	}
+	rs.Summary.Updated = time.Now()
+	return steam.store.SaveReviews("steam", appid, *rs)
+}
+
+func (steam *SteamSeeker) getSteamReviewPage(ctx context.Context, appid int, cursor string) (steamReviewPage, error) {
+	req, err := http.NewRequest("GET", pathGetAppReviews+strconv.Itoa(appid), nil)
+	if err != nil {
+		return steamReviewPage{}, err
+	}
+	q := req.URL.Query()
+	q.Add("json", "1")
+	q.Add("filter", "updated")
+	q.Add("language", "all")
+	q.Add("purchase_type", "all")
+	q.Add("num_per_page", strconv.Itoa(reviewsPerPage))
+	q.Add("cursor", cursor)
+	req.URL.RawQuery = q.Encode()
+	var page steamReviewPage
+	if err := httpDo(ctx, req, steam.client, func(resp *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return ErrSteamRateLimit
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		page, err = parseSteamReviewPage(body)
+		return err
+	}); err != nil {
+		return steamReviewPage{}, err
+	}
+	return page, nil
+}
+
+func parseSteamReviewPage(data []byte) (steamReviewPage, error) {
+	var p fastjson.Parser
+	root, err := p.ParseBytes(data)
+	if err != nil {
+		return steamReviewPage{}, err
+	}
+	if root.GetInt("success") != 1 {
+		return steamReviewPage{}, ErrSteamFailReponse
+	}
+	page := steamReviewPage{
+		cursor: string(root.GetStringBytes("cursor")),
+	}
+	// only first page has totals
+	if qs := root.Get("query_summary"); qs != nil && qs.Exists("total_reviews") {
+		page.summary = &store.ReviewSummary{
+			Score:         qs.GetInt("review_score"),
+			Description:   string(qs.GetStringBytes("review_score_desc")),
+			TotalPositive: qs.GetInt("total_positive"),
+			TotalNegative: qs.GetInt("total_negative"),
+			TotalReviews:  qs.GetInt("total_reviews"),
+		}
+	}
+	for _, r := range root.GetArray("reviews") {
+		page.reviews = append(page.reviews, store.Review{
+			ID:               string(r.GetStringBytes("recommendationid")),
+			Author:           string(r.GetStringBytes("author", "steamid")),
+			Language:         string(r.GetStringBytes("language")),
+			Text:             string(r.GetStringBytes("review")),
+			VotedUp:          r.GetBool("voted_up"),
+			VotesUp:          r.GetInt("votes_up"),
+			VotesFunny:       r.GetInt("votes_funny"),
+			WeightedScore:    numberOrString(r.Get("weighted_vote_score")),
+			PlaytimeAtReview: r.GetInt("author", "playtime_at_review"),
+			PlaytimeForever:  r.GetInt("author", "playtime_forever"),
+			Created:          time.Unix(r.GetInt64("timestamp_created"), 0),
+			Updated:          time.Unix(r.GetInt64("timestamp_updated"), 0),
+		})
+	}
+	return page, nil
+}
+
+// numberOrString reads a float steam sends either as number or string
+func numberOrString(v *fastjson.Value) float64 {
+	if v == nil {
+		return 0
+	}
+	if v.Type() == fastjson.TypeString {
+		f, _ := strconv.ParseFloat(string(v.GetStringBytes()), 64)
+		return f
+	}
+	return v.GetFloat64()
+}
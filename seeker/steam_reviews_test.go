@@ -0,0 +1,141 @@
+package seeker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+// standInReview is a review served by reviewStandIn, times are seconds after 1500000000
+type standInReview struct {
+	id, created, updated int
+	text                 string
+}
+
+// reviewStandIn serves appreviews pages of reviews, latest updated first,
+// two reviews per page
+type reviewStandIn struct {
+	sync.Mutex
+	reviews []standInReview
+	pages   int
+}
+
+func (rs *reviewStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rs.Lock()
+	defer rs.Unlock()
+	rs.pages++
+	start := 0
+	if c := r.URL.Query().Get("cursor"); c != "*" {
+		start, _ = strconv.Atoi(c)
+	}
+	var reviews []map[string]interface{}
+	for i := start; i < len(rs.reviews) && i < start+2; i++ {
+		sr := rs.reviews[i]
+		text := sr.text
+		if text == "" {
+			text = "review " + strconv.Itoa(sr.id)
+		}
+		reviews = append(reviews, map[string]interface{}{
+			"recommendationid":    strconv.Itoa(sr.id),
+			"author":              map[string]interface{}{"steamid": "7656", "playtime_forever": 600, "playtime_at_review": 120},
+			"language":            "english",
+			"review":              text,
+			"timestamp_created":   1500000000 + sr.created,
+			"timestamp_updated":   1500000000 + sr.updated,
+			"voted_up":            sr.id%2 == 0,
+			"votes_up":            sr.id,
+			"votes_funny":         0,
+			"weighted_vote_score": "0.5",
+		})
+	}
+	resp := map[string]interface{}{
+		"success": 1,
+		"cursor":  strconv.Itoa(start + len(reviews)),
+		"reviews": reviews,
+	}
+	total := len(rs.reviews)
+	qs := map[string]interface{}{"num_reviews": len(reviews)}
+	if r.URL.Query().Get("cursor") == "*" {
+		qs = map[string]interface{}{"num_reviews": len(reviews), "review_score": 8, "review_score_desc": "Very Positive",
+			"total_positive": total / 2, "total_negative": total - total/2, "total_reviews": total}
+	}
+	resp["query_summary"] = qs
+	json.NewEncoder(w).Encode(resp)
+}
+
+// unedited are reviews of ids from newest down to 1, never edited
+func unedited(newest int) []standInReview {
+	var ret []standInReview
+	for id := newest; id > 0; id-- {
+		ret = append(ret, standInReview{id: id, created: id, updated: id})
+	}
+	return ret
+}
+
+func TestCollectAppReviews(t *testing.T) {
+	db := storetest.New(t)
+	standIn := &reviewStandIn{}
+	ts := httptest.NewServer(standIn)
+	defer ts.Close()
+	defer func(p string) { pathGetAppReviews = p }(pathGetAppReviews)
+	pathGetAppReviews = ts.URL + "/"
+
+	steam := newSteamSeeker(SteamConfig{WorkerNum: 2, Tracked: []int{10}, ReviewCap: 4}, db)
+	sameSecond := append([]standInReview{{id: 7, created: 7, updated: 7}, {id: 8, created: 7, updated: 7}}, unedited(6)...)
+	edited := append([]standInReview{{id: 5, created: 5, updated: 9, text: "edited"}}, sameSecond[:3]...)
+	edited = append(edited, unedited(4)...)
+	var tests = []struct {
+		reviews []standInReview
+		pages   int
+		ids     []string
+		total   int
+	}{
+		// capped at 4 of 5 reviews
+		{unedited(5), 2, []string{"5", "4", "3", "2"}, 5},
+		// two new reviews, fetching stops past the stored latest update
+		{unedited(7), 2, []string{"7", "6", "5", "4"}, 7},
+		// nothing new
+		{unedited(7), 1, []string{"7", "6", "5", "4"}, 7},
+		// a new review updated in the second of the stored latest one
+		{sameSecond, 2, []string{"7", "8", "6", "5"}, 8},
+		// an edited review is fetched again and replaces the stored one
+		{edited, 2, []string{"5", "7", "8", "6"}, 8},
+	}
+	for caseid, c := range tests {
+		standIn.reviews, standIn.pages = c.reviews, 0
+		if err := steam.CollectReviews(context.Background()); err != nil {
+			t.Errorf("case #%d, CollectReviews err: %v", caseid+1, err)
+		}
+		rs, err := db.GetReviews("steam", 10)
+		if err != nil {
+			t.Errorf("case #%d, GetReviews err: %v", caseid+1, err)
+			continue
+		}
+		var ids []string
+		for _, r := range rs.Reviews {
+			ids = append(ids, r.ID)
+		}
+		if standIn.pages != c.pages || len(ids) != len(c.ids) || rs.Summary.TotalReviews != c.total {
+			t.Errorf("case #%d, got pages: %d reviews: %v total: %d, expected: %d %v %d",
+				caseid+1, standIn.pages, ids, rs.Summary.TotalReviews, c.pages, c.ids, c.total)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != c.ids[i] {
+				t.Errorf("case #%d, got reviews: %v, expected: %v", caseid+1, ids, c.ids)
+				break
+			}
+		}
+	}
+	rs, _ := db.GetReviews("steam", 10)
+	if r := rs.Reviews[0]; r.PlaytimeAtReview != 120 || r.WeightedScore != 0.5 || r.Text != "edited" ||
+		r.Created.Unix() != 1500000005 || r.Updated.Unix() != 1500000009 {
+		t.Errorf("got review: %#v", r)
+	}
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/ksang/gamecha/release"
+	"github.com/ksang/gamecha/sanitize"
 	"github.com/ksang/gamecha/store"
 	"github.com/valyala/fastjson"
 )
@@ -22,6 +27,8 @@ var (
 	pathGetAppList   = "/ISteamApps/GetAppList/v2"
 	pathGetAppDetail = "https://store.steampowered.com/api/appdetails"
 	workerIDKey      = ContextKey("workerID")
+	// {STEAM_APP_IMAGE} placeholders of app texts point to app id under it
+	steamAppImages = "https://cdn.akamai.steamstatic.com/steam/apps/"
 )
 
 var (
@@ -29,6 +36,8 @@ var (
 	ErrSteamFailReponse = errors.New("failed steam response")
 	// ErrSteamRateLimit indicates steam api is rate limiting our seeker
 	ErrSteamRateLimit = errors.New("steam rate limit")
+	// ErrSteamSkippedType indicates steam app type is not one of configured types
+	ErrSteamSkippedType = errors.New("steam app type skipped")
 	// ErrSteamQuitTimeout indicates steam seeker took too long to graceful quit
 	ErrSteamQuitTimeout = errors.New("steam seeker grace quit timed out")
 )
@@ -40,6 +49,27 @@ type SteamConfig struct {
 	WorkerNum     int
 	RetryInterval time.Duration
 	RetryCount    int
+	// Countries are the store regions to collect prices for, e.g. us, cn
+	Countries []string
+	// Localizations are steam language names to fetch descriptions in, e.g. schinese
+	Localizations []string
+	// Types of apps to keep, e.g. game dlc demo, empty keeps all
+	Types []string
+	// Tracked apps are followed closely, e.g. their reviews are collected
+	Tracked []int
+	// RecheckInterval is how often stored records are fetched again, zero never
+	RecheckInterval time.Duration
+	// ReleaseRecheckInterval is how often records without a settled release
+	// date are fetched again, zero never
+	ReleaseRecheckInterval time.Duration
+	// ReviewCap is the max number of reviews kept per tracked app
+	ReviewCap int
+	// NewsCap is the max number of news kept per tracked app
+	NewsCap int
+	// PlayerInterval is how often concurrent players of tracked apps are sampled
+	PlayerInterval time.Duration
+	// PlayerRetention controls rollup of stored player samples
+	PlayerRetention RetentionPolicy
 }
 
 // SteamSeeker object
@@ -51,8 +81,10 @@ type SteamSeeker struct {
 	errc         chan error
 	workerDone   chan struct{}
 	workerReturn chan store.GameRecord
-	debugLog     *log.Logger
-	infoLog      *log.Logger
+	// packages fetched by this seeker, ones shared by apps are fetched once
+	packages sync.Map
+	debugLog *log.Logger
+	infoLog  *log.Logger
 }
 
 // appdetail response parsing
@@ -64,44 +96,58 @@ type steamAppDetail struct {
 }
 
 type steamAppDetailData struct {
-	Typ                 string                 `json:"type"`
-	Name                string                 `json:"name"`
-	Appid               int                    `json:"steam_appid"`
-	RequiredAge         interface{}            `json:"required_age"`
-	IsFree              bool                   `json:"is_free"`
-	DetailedDescription string                 `json:"detailed_description"`
-	AboutTheGame        string                 `json:"about_the_game"`
-	ShortDescription    string                 `json:"short_description"`
-	SupportLanguages    string                 `json:"supported_languages"`
-	HeaderImage         string                 `json:"header_image"`
-	Website             string                 `json:"website"`
-	PcRequirements      interface{}            `json:"pc_requirements"`
-	MacRequirements     interface{}            `json:"mac_requirements"`
-	LinuxRequirements   interface{}            `json:"linux_requirements"`
-	Developers          []string               `json:"developers"`
-	Publishers          []string               `json:"publishers"`
-	PriceOverview       map[string]interface{} `json:"price_overview"`
-	Packages            []int                  `json:"packages"`
-	// TODO: parse package group details
-	PackageGroups      []map[string]interface{} `json:"package_groups"`
-	Platforms          map[string]bool          `json:"platforms"`
-	MetaCritic         map[string]interface{}   `json:"metacritic"`
-	Categories         []map[string]interface{} `json:"categories"`
-	Genres             []map[string]interface{} `json:"genres"`
-	Screenshots        []map[string]interface{} `json:"screenshots"`
-	Recommendations    map[string]interface{}   `json:"recommendations"`
-	Achievements       map[string]interface{}   `json:"achievements"`
-	ReleaseDate        map[string]interface{}   `json:"release_date"`
-	SupportInfo        map[string]interface{}   `json:"support_info"`
-	Background         string                   `json:"background"`
-	ContentDescriptors map[string]interface{}   `json:"content_descriptors"`
+	Typ                 string                   `json:"type"`
+	Name                string                   `json:"name"`
+	Appid               int                      `json:"steam_appid"`
+	RequiredAge         interface{}              `json:"required_age"`
+	IsFree              bool                     `json:"is_free"`
+	DetailedDescription string                   `json:"detailed_description"`
+	AboutTheGame        string                   `json:"about_the_game"`
+	ShortDescription    string                   `json:"short_description"`
+	SupportLanguages    string                   `json:"supported_languages"`
+	HeaderImage         string                   `json:"header_image"`
+	Website             string                   `json:"website"`
+	PcRequirements      interface{}              `json:"pc_requirements"`
+	MacRequirements     interface{}              `json:"mac_requirements"`
+	LinuxRequirements   interface{}              `json:"linux_requirements"`
+	Developers          []string                 `json:"developers"`
+	Publishers          []string                 `json:"publishers"`
+	PriceOverview       map[string]interface{}   `json:"price_overview"`
+	Packages            []int                    `json:"packages"`
+	PackageGroups       []steamPackageGroup      `json:"package_groups"`
+	DLC                 []int                    `json:"dlc"`
+	FullGame            map[string]interface{}   `json:"fullgame"`
+	Platforms           map[string]bool          `json:"platforms"`
+	MetaCritic          map[string]interface{}   `json:"metacritic"`
+	Categories          []map[string]interface{} `json:"categories"`
+	Genres              []map[string]interface{} `json:"genres"`
+	Screenshots         []map[string]interface{} `json:"screenshots"`
+	Recommendations     map[string]interface{}   `json:"recommendations"`
+	Achievements        map[string]interface{}   `json:"achievements"`
+	ReleaseDate         map[string]interface{}   `json:"release_date"`
+	SupportInfo         map[string]interface{}   `json:"support_info"`
+	Background          string                   `json:"background"`
+	ContentDescriptors  map[string]interface{}   `json:"content_descriptors"`
+}
+
+// purchase options of appdetails, only stable typed fields are parsed
+type steamPackageGroup struct {
+	Name  string            `json:"name"`
+	Title string            `json:"title"`
+	Subs  []steamPackageSub `json:"subs"`
+}
+
+type steamPackageSub struct {
+	PackageID         int    `json:"packageid"`
+	OptionText        string `json:"option_text"`
+	PriceWithDiscount int    `json:"price_in_cents_with_discount"`
 }
 
 func newSteamSeeker(cfg SteamConfig, db store.GameStore) *SteamSeeker {
 	return &SteamSeeker{
 		config:       cfg,
 		queue:        make(chan int),
-		errc:         make(chan error),
+		errc:         make(chan error, 1),
 		store:        db,
 		workerReturn: make(chan store.GameRecord, cfg.WorkerNum),
 		workerDone:   make(chan struct{}, cfg.WorkerNum),
@@ -116,43 +162,33 @@ func startSteamSeeker(ctx context.Context, cfg SteamConfig, db store.GameStore)
 		return nil, err
 	}
 	go func() {
-		steam.errc <- steam.storeRecord(ctx)
+		steam.errc <- steam.storeRecord()
 	}()
 	for i := 0; i < cfg.WorkerNum; i++ {
 		wCtx := context.WithValue(ctx, workerIDKey, i)
 		go steam.workerThread(wCtx)
 	}
+	// records are all returned once the last worker is done
+	go func() {
+		for i := 0; i < cfg.WorkerNum; i++ {
+			<-steam.workerDone
+		}
+		close(steam.workerReturn)
+	}()
 
 	return steam, nil
 }
 
-// WaitUntilDone all steam seeker workers done their work
+// WaitUntilDone all steam seeker workers done their work and their records
+// saved, error of saving records is returned
 func (steam *SteamSeeker) WaitUntilDone(ctx context.Context) error {
-	allWorkerDone := make(chan struct{})
-	go func() {
-		c := 0
-		for {
-			select {
-			case <-steam.workerDone:
-				c++
-			}
-			if c == steam.config.WorkerNum {
-				allWorkerDone <- struct{}{}
-				return
-			}
-		}
-	}()
-	for {
+	select {
+	case err := <-steam.errc:
+		return err
+	case <-ctx.Done():
 		select {
-		case <-allWorkerDone:
-			return nil
-		case <-ctx.Done():
-			select {
-			case <-time.After(3000000000):
-				return ErrSteamQuitTimeout
-			case <-allWorkerDone:
-				return nil
-			}
+		case <-time.After(3000000000):
+			return ErrSteamQuitTimeout
 		case err := <-steam.errc:
 			return err
 		}
@@ -188,28 +224,63 @@ func (steam *SteamSeeker) processSteamAppList(resp *http.Response, err error) er
 		gameList[game.GetInt("appid")] = string(game.GetStringBytes("name"))
 	}
 
+	changed, err := steam.trackListing(gameList, time.Now())
+	if err != nil {
+		return err
+	}
 	oldList, err := steam.store.GetSavedGameList("steam")
 	if err != nil {
 		return err
 	}
-	steam.debugLog.Printf("processSteamAppList: oldGameList len: %d, newGameList len: %d", len(oldList), len(gameList))
-	diff, err := steam.createSeekerQueue(oldList, gameList)
+	// apps learned to be of unwanted types are not fetched again, unless
+	// their type is wanted since
+	skipped, err := steam.store.GetSkippedApps("steam")
+	if err != nil {
+		return err
+	}
+	for id, typ := range skipped {
+		if !steam.keepType(typ) {
+			oldList[id] = ""
+			continue
+		}
+		if err := steam.store.DeleteSkippedApp("steam", id); err != nil {
+			return err
+		}
+	}
+	// queued apps are fetched even if listed before or missing from app list,
+	// they are not added to the list saved as app index
+	queued, err := steam.store.GetQueuedApps("steam")
+	if err != nil {
+		return err
+	}
+	recheck, err := steam.recheckRecords(time.Now())
+	if err != nil {
+		return err
+	}
+	steam.debugLog.Printf("processSteamAppList: oldGameList len: %d, newGameList len: %d, queued: %d, recheck: %d",
+		len(oldList), len(gameList), len(queued), len(recheck))
+	diff, err := steam.createSeekerQueue(oldList, gameList, append(queued, recheck...))
 	if err != nil {
 		return err
 	}
-	if len(diff) > 0 {
+	if len(diff) > 0 || changed > 0 {
 		steam.store.SaveGameList("steam", gameList)
 	}
 	return nil
 }
 
-func (steam *SteamSeeker) createSeekerQueue(oldList map[int]string, newList map[int]string) (map[int]string, error) {
+// createSeekerQueue sends apps of newList missing from oldList and the queued
+// apps to workers, it returns the apps sent
+func (steam *SteamSeeker) createSeekerQueue(oldList map[int]string, newList map[int]string, queued []int) (map[int]string, error) {
 	ret := make(map[int]string)
 	for k, v := range newList {
 		if _, ok := oldList[k]; !ok {
 			ret[k] = v
 		}
 	}
+	for _, k := range queued {
+		ret[k] = newList[k]
+	}
 	go func() {
 		for k := range ret {
 			steam.queue <- k
@@ -221,30 +292,138 @@ func (steam *SteamSeeker) createSeekerQueue(oldList map[int]string, newList map[
 
 func (steam *SteamSeeker) getSteamAppDetail(ctx context.Context, appid int) error {
 	steam.debugLog.Printf("workerThread[%d] getting app detail: %d", ctx.Value(workerIDKey), appid)
+	gr, err := steam.fetchGameRecord(ctx, appid)
+	if err == ErrSteamFailReponse {
+		// records of apps store stopped serving are delisted instead of retried
+		delisted, derr := steam.delistUnavailable(ctx, appid)
+		if derr != nil {
+			return derr
+		}
+		if delisted {
+			return steam.store.DequeueApp("steam", appid)
+		}
+	}
+	if err == ErrSteamSkippedType {
+		steam.debugLog.Printf("workerThread[%d] skipped app: %d type: %s", ctx.Value(workerIDKey), appid, gr.Type)
+		if err := steam.store.SaveSkippedApp("steam", appid, gr.Type); err != nil {
+			return err
+		}
+		return steam.store.DequeueApp("steam", appid)
+	}
+	if err != nil {
+		return err
+	}
+	steam.collectPackages(ctx, gr.Packages)
+	steam.workerReturn <- gr
+	return nil
+}
+
+// fetchGameRecord gets app detail in default language and then in every
+// configured localization language, keyed by language code. A language
+// failed to fetch is left out. Apps of types not configured are returned
+// with ErrSteamSkippedType and only type set.
+func (steam *SteamSeeker) fetchGameRecord(ctx context.Context, appid int) (store.GameRecord, error) {
+	sad, err := steam.fetchSteamAppDetail(ctx, appid, "")
+	if err != nil {
+		return store.GameRecord{}, err
+	}
+	if !steam.keepType(sad.Typ) {
+		return store.GameRecord{ID: appid, Type: sad.Typ}, ErrSteamSkippedType
+	}
+	gr, err := steam.newGameRecord(sad)
+	if err != nil {
+		return store.GameRecord{}, err
+	}
+	for _, lang := range steam.config.Localizations {
+		lsad, err := steam.fetchSteamAppDetail(ctx, appid, lang)
+		if err == ErrSteamRateLimit {
+			return store.GameRecord{}, err
+		}
+		if err != nil {
+			steam.infoLog.Printf("app %d texts in %s not fetched: %v", appid, lang, err)
+			continue
+		}
+		if gr.Localized == nil {
+			gr.Localized = make(map[string]store.LocalizedText)
+		}
+		gr.Localized[steamAPILanguageCode(lang)] = store.LocalizedText{
+			Name:                lsad.Name,
+			Description:         lsad.DetailedDescription,
+			About:               lsad.AboutTheGame,
+			ShortDescription:    lsad.ShortDescription,
+			DescriptionText:     sanitize.Text(lsad.DetailedDescription),
+			DescriptionMarkdown: sanitize.Markdown(lsad.DetailedDescription),
+			AboutText:           sanitize.Text(lsad.AboutTheGame),
+			AboutMarkdown:       sanitize.Markdown(lsad.AboutTheGame),
+		}
+	}
+	return gr, nil
+}
+
+// fetchSteamAppDetail of an app, lang is steam language name passed as l parameter,
+// empty for steam default
+func (steam *SteamSeeker) fetchSteamAppDetail(ctx context.Context, appid int, lang string) (steamAppDetailData, error) {
 	req, err := http.NewRequest("GET", pathGetAppDetail, nil)
+	if err != nil {
+		return steamAppDetailData{}, err
+	}
 	q := req.URL.Query()
 	q.Add("appids", strconv.FormatInt(int64(appid), 10))
+	if lang != "" {
+		q.Add("l", lang)
+	}
 	req.URL.RawQuery = q.Encode()
-	if err != nil {
+	var sad steamAppDetailData
+	if err := httpDo(ctx, req, steam.client, func(resp *http.Response, err error) error {
+		sad, err = steam.processSteamAppDetail(resp, err)
 		return err
+	}); err != nil {
+		return steamAppDetailData{}, err
 	}
-	return httpDo(ctx, req, steam.client, steam.processSteamAppDetail)
+	sad.resolveImages()
+	return sad, nil
+}
+
+// resolveImages points {STEAM_APP_IMAGE} placeholders of texts to cdn path of app
+func (sad *steamAppDetailData) resolveImages() {
+	r := strings.NewReplacer("{STEAM_APP_IMAGE}", steamAppImages+strconv.Itoa(sad.Appid))
+	sad.DetailedDescription = r.Replace(sad.DetailedDescription)
+	sad.AboutTheGame = r.Replace(sad.AboutTheGame)
+	sad.ShortDescription = r.Replace(sad.ShortDescription)
 }
 
-func (steam *SteamSeeker) processSteamAppDetail(resp *http.Response, err error) error {
+func (steam *SteamSeeker) processSteamAppDetail(resp *http.Response, err error) (steamAppDetailData, error) {
 	if err != nil {
-		return err
+		return steamAppDetailData{}, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return steamAppDetailData{}, ErrSteamRateLimit
+	}
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return steamAppDetailData{}, err
 	}
 	res, err := steam.parseSteamAppDetail(body)
 	if err != nil {
-		return err
+		return steamAppDetailData{}, err
+	}
+	return res.Data, nil
+}
+
+func (steam *SteamSeeker) keepType(typ string) bool {
+	if len(steam.config.Types) == 0 {
+		return true
 	}
-	sad := res.Data
+	for _, t := range steam.config.Types {
+		if strings.EqualFold(t, typ) {
+			return true
+		}
+	}
+	return false
+}
+
+func (steam *SteamSeeker) newGameRecord(sad steamAppDetailData) (store.GameRecord, error) {
 	var reqAge int64
 	var err1 error
 	switch v := sad.RequiredAge.(type) {
@@ -252,26 +431,117 @@ func (steam *SteamSeeker) processSteamAppDetail(resp *http.Response, err error)
 		reqAge = int64(sad.RequiredAge.(int))
 	case string:
 		if reqAge, err1 = strconv.ParseInt(sad.RequiredAge.(string), 10, 32); err1 != nil {
-			return err1
+			return store.GameRecord{}, err1
 		}
 	case float64:
 		reqAge = int64(sad.RequiredAge.(float64))
 	default:
 		steam.infoLog.Printf("RequiredAge unknown type %T!", v)
-		return errors.New("can't parse required age")
-	}
-	gr := store.GameRecord{
-		Name:        sad.Name,
-		ID:          sad.Appid,
-		RequiredAge: int(reqAge),
-		Description: sad.DetailedDescription,
-		About:       sad.AboutTheGame,
-		Languages:   sad.SupportLanguages,
-		Developers:  sad.Developers,
-		Publishers:  sad.Publishers,
+		return store.GameRecord{}, errors.New("can't parse required age")
 	}
-	steam.workerReturn <- gr
-	return nil
+	var reqs map[string]store.Requirements
+	for platform, v := range map[string]interface{}{
+		"pc":    sad.PcRequirements,
+		"mac":   sad.MacRequirements,
+		"linux": sad.LinuxRequirements,
+	} {
+		if r := parseSteamRequirements(v); r != nil {
+			if reqs == nil {
+				reqs = make(map[string]store.Requirements)
+			}
+			reqs[platform] = *r
+		}
+	}
+	var groups []store.PackageGroup
+	for _, g := range sad.PackageGroups {
+		pg := store.PackageGroup{Name: g.Name, Title: g.Title}
+		for _, sub := range g.Subs {
+			pg.Options = append(pg.Options, store.PackageOption{
+				PackageID: sub.PackageID,
+				Text:      sub.OptionText,
+				Price:     sub.PriceWithDiscount,
+			})
+		}
+		groups = append(groups, pg)
+	}
+	var fullGame int
+	if id, ok := sad.FullGame["appid"]; ok {
+		// appid of fullgame is a string in appdetails
+		fullGame, _ = strconv.Atoi(fmt.Sprint(id))
+	}
+	var media []store.Media
+	for _, m := range sanitize.ExtractMedia(sad.DetailedDescription + sad.AboutTheGame) {
+		media = append(media, store.Media{Type: m.Type, URL: m.URL})
+	}
+	return store.GameRecord{
+		Name:                sad.Name,
+		ID:                  sad.Appid,
+		Type:                sad.Typ,
+		RequiredAge:         int(reqAge),
+		Description:         sad.DetailedDescription,
+		About:               sad.AboutTheGame,
+		ShortDescription:    sad.ShortDescription,
+		Languages:           sad.SupportLanguages,
+		LanguageSupport:     parseSteamLanguages(sad.SupportLanguages),
+		Requirements:        reqs,
+		DescriptionText:     sanitize.Text(sad.DetailedDescription),
+		DescriptionMarkdown: sanitize.Markdown(sad.DetailedDescription),
+		AboutText:           sanitize.Text(sad.AboutTheGame),
+		AboutMarkdown:       sanitize.Markdown(sad.AboutTheGame),
+		Media:               media,
+		DLC:                 sad.DLC,
+		FullGame:            fullGame,
+		Packages:            sad.Packages,
+		PackageGroups:       groups,
+		Developers:          sad.Developers,
+		Publishers:          sad.Publishers,
+		Release:             steamReleaseDate(sad.ReleaseDate),
+		Genres:              steamDescriptions(sad.Genres),
+		Categories:          steamDescriptions(sad.Categories),
+		Platforms:           steamPlatforms(sad.Platforms),
+		IsFree:              sad.IsFree,
+		Metacritic:          steamMetacritic(sad.MetaCritic),
+	}, nil
+}
+
+// steamPlatforms lists platforms supported in appdetails, in windows, mac, linux order
+func steamPlatforms(platforms map[string]bool) []string {
+	var ret []string
+	for _, p := range []string{"windows", "mac", "linux"} {
+		if platforms[p] {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// steamMetacritic is the score of appdetails metacritic, 0 if none,
+// e.g. {"score": 93, "url": "..."}
+func steamMetacritic(mc map[string]interface{}) int {
+	if score, ok := mc["score"].(float64); ok {
+		return int(score)
+	}
+	return 0
+}
+
+// steamDescriptions lists descriptions of appdetails genres or categories,
+// e.g. [{"id": "1", "description": "Action"}]
+func steamDescriptions(items []map[string]interface{}) []string {
+	var ret []string
+	for _, item := range items {
+		if d, ok := item["description"].(string); ok && d != "" {
+			ret = append(ret, d)
+		}
+	}
+	return ret
+}
+
+// steamReleaseDate parses release date of appdetails, e.g.
+// {"coming_soon": false, "date": "1 Nov, 2000"}
+func steamReleaseDate(rd map[string]interface{}) store.ReleaseDate {
+	text, _ := rd["date"].(string)
+	comingSoon, _ := rd["coming_soon"].(bool)
+	return release.Parse(text, comingSoon)
 }
 
 func (steam *SteamSeeker) parseSteamAppDetail(data []byte) (steamAppDetail, error) {
@@ -300,7 +570,10 @@ func (steam *SteamSeeker) workerThread(ctx context.Context) error {
 	id := ctx.Value(workerIDKey).(int)
 	for {
 		select {
-		case appID := <-steam.queue:
+		case appID, ok := <-steam.queue:
+			if !ok {
+				return nil
+			}
 			// Retrying get one app detail several times according to config
 			for i := 0; ; i++ {
 				if err := steam.getSteamAppDetail(ctx, appID); err == nil || (rc > 0 && i >= rc) {
@@ -327,18 +600,40 @@ func (steam *SteamSeeker) workerThread(ctx context.Context) error {
 	}
 }
 
-func (steam *SteamSeeker) storeRecord(ctx context.Context) error {
-	for {
-		select {
-		case gr := <-steam.workerReturn:
-			if err := steam.store.SaveGameRecord("steam", strconv.FormatInt(int64(gr.ID), 10), gr); err != nil {
-				steam.infoLog.Printf("failed to save game record, appid: %d", gr.ID)
-				return err
-			}
+// storeRecord saves records returned by workers until workerReturn is closed.
+// Records are drained after a failed save so workers are not blocked, the
+// first error is returned.
+func (steam *SteamSeeker) storeRecord() error {
+	var ret error
+	for gr := range steam.workerReturn {
+		if ret != nil {
+			continue
+		}
+		if err := steam.saveRecord(gr); err != nil {
+			ret = err
+		}
+	}
+	steam.infoLog.Printf("store record process done")
+	return ret
+}
 
-		case <-ctx.Done():
-			steam.infoLog.Printf("store record process signaled to quit")
-			return nil
+// saveRecord saves a fetched record and dequeues its app. State kept by
+// seeker and analysis is not part of fetched detail, it is carried over.
+func (steam *SteamSeeker) saveRecord(gr store.GameRecord) error {
+	old, err := steam.store.GetGameRecord("steam", strconv.Itoa(gr.ID))
+	if err != nil {
+		return err
+	}
+	gr.Cluster, gr.Delisted, gr.FirstSeen, gr.LastSeen = old.Cluster, old.Delisted, old.FirstSeen, old.LastSeen
+	if gr.Delisted {
+		if err := steam.relistAvailable(&gr); err != nil {
+			return err
 		}
 	}
+	gr.Fetched = time.Now()
+	if err := steam.store.SaveGameRecord("steam", strconv.FormatInt(int64(gr.ID), 10), gr); err != nil {
+		steam.infoLog.Printf("failed to save game record, appid: %d", gr.ID)
+		return err
+	}
+	return steam.store.DequeueApp("steam", gr.ID)
 }
@@ -3,10 +3,20 @@ package seeker
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ksang/gamecha/release"
 	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
 )
 
 func TestGetSteamAppList(t *testing.T) {
@@ -29,6 +39,105 @@ func TestGetSteamAppList(t *testing.T) {
 	}
 }
 
+// appListResp is a GetAppList response listing apps
+func appListResp(ids ...int) *http.Response {
+	var apps []string
+	for _, id := range ids {
+		apps = append(apps, fmt.Sprintf(`{"appid": %d, "name": "app %d"}`, id, id))
+	}
+	body := `{"applist": {"apps": [` + strings.Join(apps, ",") + `]}}`
+	return &http.Response{Body: ioutil.NopCloser(strings.NewReader(body))}
+}
+
+// drainQueue returns sorted app ids queued to workers
+func drainQueue(steam *SteamSeeker) []int {
+	var ret []int
+	for id := range steam.queue {
+		ret = append(ret, id)
+	}
+	sort.Ints(ret)
+	return ret
+}
+
+func TestProcessSteamAppListSkipped(t *testing.T) {
+	db := storetest.New(t, store.GameRecord{ID: 1, Name: "app 1", Release: release.Parse("1 Nov, 2000", false)})
+	for id, typ := range map[int]string{2: "music", 3: "dlc"} {
+		if err := db.SaveSkippedApp("steam", id, typ); err != nil {
+			t.Fatalf("SaveSkippedApp err: %v", err)
+		}
+	}
+	steam := newSteamSeeker(SteamConfig{WorkerNum: 1, Types: []string{"game", "dlc"}}, db)
+	if err := steam.processSteamAppList(appListResp(1, 2, 3, 4), nil); err != nil {
+		t.Fatalf("processSteamAppList err: %v", err)
+	}
+	// dlc is wanted since it was skipped, so it is fetched again
+	if got, expected := drainQueue(steam), []int{3, 4}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got queue: %v, expected: %v", got, expected)
+	}
+	skipped, err := db.GetSkippedApps("steam")
+	if err != nil {
+		t.Fatalf("GetSkippedApps err: %v", err)
+	}
+	if expected := map[int]string{2: "music"}; !reflect.DeepEqual(skipped, expected) {
+		t.Errorf("got skipped: %v, expected: %v", skipped, expected)
+	}
+}
+
+func TestProcessSteamAppListQueued(t *testing.T) {
+	db := storetest.New(t, store.GameRecord{ID: 1, Name: "app 1", Release: release.Parse("1 Nov, 2000", false)})
+	if err := db.QueueApps("steam", []int{1, 9}); err != nil {
+		t.Fatalf("QueueApps err: %v", err)
+	}
+	steam := newSteamSeeker(SteamConfig{WorkerNum: 1}, db)
+	if err := steam.processSteamAppList(appListResp(1, 2), nil); err != nil {
+		t.Fatalf("processSteamAppList err: %v", err)
+	}
+	if got, expected := drainQueue(steam), []int{1, 2, 9}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got queue: %v, expected: %v", got, expected)
+	}
+	// queued apps missing from app list are kept out of the saved index
+	list, err := db.GetGameList("steam")
+	if err != nil {
+		t.Fatalf("GetGameList err: %v", err)
+	}
+	if expected := map[int]string{1: "app 1", 2: "app 2"}; !reflect.DeepEqual(list, expected) {
+		t.Errorf("got list: %v, expected: %v", list, expected)
+	}
+}
+
+func TestStartSteamSeekerSavesAll(t *testing.T) {
+	ids := []int{1, 2, 3, 4, 5}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == pathGetAppList {
+			resp := appListResp(ids...)
+			io.Copy(w, resp.Body)
+			return
+		}
+		id := r.URL.Query().Get("appids")
+		w.Write([]byte(`{"` + id + `":{"success":true,"data":{"type":"game","name":"app ` + id +
+			`","steam_appid":` + id + `,"required_age":0,"release_date":{"coming_soon":false,"date":"1 Nov, 2000"}}}}`))
+	}))
+	defer ts.Close()
+	defer func(p string) { pathGetAppDetail = p }(pathGetAppDetail)
+	pathGetAppDetail = ts.URL
+
+	db := storetest.New(t)
+	ctx := context.Background()
+	steam, err := startSteamSeeker(ctx, SteamConfig{Portal: ts.URL, WorkerNum: 2}, db)
+	if err != nil {
+		t.Fatalf("startSteamSeeker err: %v", err)
+	}
+	if err := steam.WaitUntilDone(ctx); err != nil {
+		t.Fatalf("WaitUntilDone err: %v", err)
+	}
+	// records returned by the last workers are saved before returning
+	for _, id := range ids {
+		if gr, err := db.GetGameRecord("steam", strconv.Itoa(id)); err != nil || gr.ID != id {
+			t.Errorf("app %d got record: %v %v", id, gr, err)
+		}
+	}
+}
+
 func TestParseSteamAppDetail(t *testing.T) {
 	dataStr := `{
   "10": {
@@ -258,3 +367,184 @@ func TestGetSteamAppDetail(t *testing.T) {
 	}
 
 }
+
+func TestFetchGameRecordLocalized(t *testing.T) {
+	names := map[string]string{
+		"":         "Counter-Strike",
+		"schinese": "反恐精英",
+		"japanese": "カウンターストライク",
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := r.URL.Query().Get("l")
+		if l == "koreana" {
+			w.Write([]byte(`{"10": {"success": false}}`))
+			return
+		}
+		fmt.Fprintf(w, `{"10": {"success": true, "data": {"type": "game", "name": %q, "steam_appid": 10,
+			"required_age": "0", "short_description": "short %s", "detailed_description": "detail %s"}}}`, names[l], l, l)
+	}))
+	defer ts.Close()
+	defer func(p string) { pathGetAppDetail = p }(pathGetAppDetail)
+	pathGetAppDetail = ts.URL
+
+	cfg := SteamConfig{
+		WorkerNum:     1,
+		Localizations: []string{"schinese", "japanese", "koreana"},
+	}
+	steam := newSteamSeeker(cfg, nil)
+	gr, err := steam.fetchGameRecord(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("fetchGameRecord err: %v", err)
+	}
+	expected := store.GameRecord{
+		Name:                "Counter-Strike",
+		ID:                  10,
+		Type:                "game",
+		Description:         "detail ",
+		ShortDescription:    "short ",
+		DescriptionText:     "detail",
+		DescriptionMarkdown: "detail",
+		Release:             store.ReleaseDate{Precision: store.PrecisionUnknown},
+		Localized: map[string]store.LocalizedText{
+			"zh-CN": {Name: "反恐精英", Description: "detail schinese", ShortDescription: "short schinese",
+				DescriptionText: "detail schinese", DescriptionMarkdown: "detail schinese"},
+			"ja": {Name: "カウンターストライク", Description: "detail japanese", ShortDescription: "short japanese",
+				DescriptionText: "detail japanese", DescriptionMarkdown: "detail japanese"},
+		},
+	}
+	if !reflect.DeepEqual(gr, expected) {
+		t.Errorf("got: %#v, expected: %#v", gr, expected)
+	}
+}
+
+func TestResolveImages(t *testing.T) {
+	sad := steamAppDetailData{Appid: 10, DetailedDescription: `[img]{STEAM_APP_IMAGE}/extras/a.png[/img]`,
+		AboutTheGame: `<img src="{STEAM_APP_IMAGE}/extras/b.gif">`}
+	sad.resolveImages()
+	if sad.DetailedDescription != "[img]https://cdn.akamai.steamstatic.com/steam/apps/10/extras/a.png[/img]" ||
+		sad.AboutTheGame != `<img src="https://cdn.akamai.steamstatic.com/steam/apps/10/extras/b.gif">` {
+		t.Errorf("got: %q %q", sad.DetailedDescription, sad.AboutTheGame)
+	}
+}
+
+func TestFetchGameRecordSkippedType(t *testing.T) {
+	types := map[string]string{"10": "game", "2028850": "music"}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("appids")
+		fmt.Fprintf(w, `{%q: {"success": true, "data": {"type": %q, "name": "app", "steam_appid": %s, "required_age": 0}}}`, id, types[id], id)
+	}))
+	defer ts.Close()
+	defer func(p string) { pathGetAppDetail = p }(pathGetAppDetail)
+	pathGetAppDetail = ts.URL
+
+	steam := newSteamSeeker(SteamConfig{WorkerNum: 1, Types: []string{"game", "dlc"}}, nil)
+	var tests = []struct {
+		id  int
+		typ string
+		err error
+	}{
+		{10, "game", nil},
+		{2028850, "music", ErrSteamSkippedType},
+	}
+	for caseid, c := range tests {
+		gr, err := steam.fetchGameRecord(context.Background(), c.id)
+		if err != c.err || gr.Type != c.typ {
+			t.Errorf("case #%d, got: %s %v, expected: %s %v", caseid+1, gr.Type, err, c.typ, c.err)
+		}
+	}
+}
+
+func TestSteamReleaseYear(t *testing.T) {
+	var tests = []struct {
+		rd map[string]interface{}
+		y  int
+	}{
+		{map[string]interface{}{"coming_soon": false, "date": "1 Nov, 2000"}, 2000},
+		{map[string]interface{}{"coming_soon": true, "date": "Q3 2021"}, 2021},
+		{map[string]interface{}{"coming_soon": true, "date": "Coming soon"}, 0},
+		{nil, 0},
+	}
+	for caseid, c := range tests {
+		if y := release.Year(steamReleaseDate(c.rd)); y != c.y {
+			t.Errorf("case #%d, got: %d, expected: %d", caseid+1, y, c.y)
+		}
+	}
+}
+
+func TestSteamReleaseDate(t *testing.T) {
+	var tests = []struct {
+		rd         map[string]interface{}
+		str        string
+		precision  string
+		comingSoon bool
+	}{
+		{map[string]interface{}{"coming_soon": false, "date": "1 Nov, 2000"}, "1 Nov 2000", store.PrecisionDay, false},
+		{map[string]interface{}{"coming_soon": true, "date": "Q3 2021"}, "Q3 2021", store.PrecisionQuarter, true},
+		{map[string]interface{}{"coming_soon": true, "date": "Coming soon"}, "Coming soon", store.PrecisionUnknown, true},
+		{nil, "unknown", store.PrecisionUnknown, false},
+	}
+	for caseid, c := range tests {
+		d := steamReleaseDate(c.rd)
+		if d.String() != c.str || d.Precision != c.precision || d.ComingSoon != c.comingSoon {
+			t.Errorf("case #%d, got: %s %s %v, expected: %s %s %v", caseid+1, d, d.Precision, d.ComingSoon,
+				c.str, c.precision, c.comingSoon)
+		}
+	}
+}
+
+func TestSteamReleaseMonth(t *testing.T) {
+	var tests = []struct {
+		rd map[string]interface{}
+		m  int
+	}{
+		{map[string]interface{}{"coming_soon": false, "date": "1 Nov, 2000"}, 11},
+		{map[string]interface{}{"coming_soon": false, "date": "Mar 3, 2015"}, 3},
+		{map[string]interface{}{"coming_soon": true, "date": "December 2024"}, 12},
+		{map[string]interface{}{"coming_soon": true, "date": "Q3 2021"}, 0},
+		{map[string]interface{}{"coming_soon": true, "date": "Coming soon"}, 0},
+		{map[string]interface{}{"coming_soon": true, "date": "Maybe 2025"}, 0},
+		{nil, 0},
+	}
+	for caseid, c := range tests {
+		if m := release.Month(steamReleaseDate(c.rd)); m != c.m {
+			t.Errorf("case #%d, got: %d, expected: %d", caseid+1, m, c.m)
+		}
+	}
+}
+
+func TestSteamDescriptions(t *testing.T) {
+	var tests = []struct {
+		items []map[string]interface{}
+		res   []string
+	}{
+		{[]map[string]interface{}{{"id": "1", "description": "Action"}, {"id": "70", "description": "Early Access"}},
+			[]string{"Action", "Early Access"}},
+		{[]map[string]interface{}{{"id": 2, "description": "Single-player"}, {"id": 3}}, []string{"Single-player"}},
+		{nil, nil},
+	}
+	for caseid, c := range tests {
+		if res := steamDescriptions(c.items); !reflect.DeepEqual(res, c.res) {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, res, c.res)
+		}
+	}
+}
+
+func TestSteamPlatformsMetacritic(t *testing.T) {
+	var tests = []struct {
+		platforms map[string]bool
+		mc        map[string]interface{}
+		res       []string
+		score     int
+	}{
+		{map[string]bool{"linux": true, "windows": true, "mac": false}, map[string]interface{}{"score": float64(93)},
+			[]string{"windows", "linux"}, 93},
+		{map[string]bool{"windows": true}, nil, []string{"windows"}, 0},
+		{nil, map[string]interface{}{"url": "https://www.metacritic.com/"}, nil, 0},
+	}
+	for caseid, c := range tests {
+		res, score := steamPlatforms(c.platforms), steamMetacritic(c.mc)
+		if !reflect.DeepEqual(res, c.res) || score != c.score {
+			t.Errorf("case #%d, got: %v %d, expected: %v %d", caseid+1, res, score, c.res, c.score)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package seeker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/valyala/fastjson"
+)
+
+// ErrSteamEmptyWishlist indicates a wishlist responded with no items, it is
+// private or empty and the stored one is kept
+var ErrSteamEmptyWishlist = errors.New("steam wishlist empty or private")
+
+var (
+	pathGetWishlist = "/IWishlistService/GetWishlist/v1/"
+	// steam genre id of early access games
+	steamEarlyAccessGenre = "70"
+)
+
+// wishlistState of a wished app from its app detail
+type wishlistState struct {
+	name        string
+	discount    int
+	earlyAccess bool
+}
+
+// SyncWishlist fetches public wishlist of a steam user and merges it into
+// the stored one. Items gained a discount or left early access since last
+// sync are recorded as changes along with added and removed ones. An empty
+// or private wishlist is ErrSteamEmptyWishlist.
+func (steam *SteamSeeker) SyncWishlist(ctx context.Context, steamid string) (*store.Wishlist, error) {
+	var wished []store.WishlistItem
+	if err := steam.withRetry(ctx, "getWishlist", func() (err error) {
+		wished, err = steam.getSteamWishlist(ctx, steamid)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	// a private wishlist would mark every stored item removed
+	if len(wished) == 0 {
+		return nil, ErrSteamEmptyWishlist
+	}
+	ids := make([]int, len(wished))
+	for i, w := range wished {
+		ids[i] = w.ID
+	}
+	var mu sync.Mutex
+	states := make(map[int]wishlistState, len(ids))
+	steam.runPool(ctx, "getWishlistState", ids, func(ctx context.Context, appid int) error {
+		sad, err := steam.fetchSteamAppDetail(ctx, appid, "")
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		states[appid] = newWishlistState(sad)
+		mu.Unlock()
+		return nil
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	old, err := steam.store.GetWishlist("steam", steamid)
+	if err != nil {
+		return nil, err
+	}
+	wl := mergeWishlist(*old, wished, states, time.Now())
+	wl.UserID = steamid
+	steam.infoLog.Printf("synced %d wishlist items of user %s, %d changes", len(wished), steamid, len(wl.Changes))
+	if err := steam.store.SaveWishlist("steam", steamid, wl); err != nil {
+		return nil, err
+	}
+	return &wl, nil
+}
+
+func newWishlistState(sad steamAppDetailData) wishlistState {
+	ws := wishlistState{name: sad.Name}
+	if d, ok := sad.PriceOverview["discount_percent"].(float64); ok {
+		ws.discount = int(d)
+	}
+	for _, g := range sad.Genres {
+		if fmt.Sprint(g["id"]) == steamEarlyAccessGenre {
+			ws.earlyAccess = true
+		}
+	}
+	return ws
+}
+
+// mergeWishlist applies currently wished items and their states to old wishlist.
+// Items without state, e.g. failed to fetch, keep their last known state.
+func mergeWishlist(old store.Wishlist, wished []store.WishlistItem, states map[int]wishlistState, t time.Time) store.Wishlist {
+	wl := store.Wishlist{Synced: t}
+	oldItems := make(map[int]store.WishlistItem, len(old.Items))
+	for _, item := range old.Items {
+		oldItems[item.ID] = item
+	}
+	current := make(map[int]bool, len(wished))
+	for _, w := range wished {
+		current[w.ID] = true
+		item, known := oldItems[w.ID]
+		wasActive := known && item.Removed.IsZero()
+		if !wasActive {
+			// new or wished again
+			item = store.WishlistItem{ID: w.ID, Added: w.Added}
+		}
+		item.Priority = w.Priority
+		if s, ok := states[w.ID]; ok {
+			if wasActive && s.discount > 0 && item.Discount == 0 {
+				wl.Changes = append(wl.Changes, store.WishlistChange{ID: w.ID, Name: s.name, Kind: store.WishlistDiscounted})
+			}
+			if wasActive && item.EarlyAccess && !s.earlyAccess {
+				wl.Changes = append(wl.Changes, store.WishlistChange{ID: w.ID, Name: s.name, Kind: store.WishlistLeftEarlyAccess})
+			}
+			item.Name, item.Discount, item.EarlyAccess = s.name, s.discount, s.earlyAccess
+		}
+		if !wasActive && !old.Synced.IsZero() {
+			wl.Changes = append(wl.Changes, store.WishlistChange{ID: w.ID, Name: item.Name, Kind: store.WishlistAdded})
+		}
+		wl.Items = append(wl.Items, item)
+	}
+	for _, item := range old.Items {
+		if current[item.ID] {
+			continue
+		}
+		if item.Removed.IsZero() {
+			item.Removed = t
+			wl.Changes = append(wl.Changes, store.WishlistChange{ID: item.ID, Name: item.Name, Kind: store.WishlistRemoved})
+		}
+		wl.Items = append(wl.Items, item)
+	}
+	return wl
+}
+
+func (steam *SteamSeeker) getSteamWishlist(ctx context.Context, steamid string) ([]store.WishlistItem, error) {
+	req, err := http.NewRequest("GET", steam.config.Portal+pathGetWishlist, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Add("steamid", steamid)
+	if steam.config.Key != "" {
+		q.Add("key", steam.config.Key)
+	}
+	req.URL.RawQuery = q.Encode()
+	var items []store.WishlistItem
+	if err := httpDo(ctx, req, steam.client, func(resp *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests:
+			return ErrSteamRateLimit
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ErrSteamFailReponse
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		items, err = parseSteamWishlist(body)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// parseSteamWishlist parses wished apps, private wishlists respond with no items
+func parseSteamWishlist(data []byte) ([]store.WishlistItem, error) {
+	var p fastjson.Parser
+	root, err := p.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if !root.Exists("response") {
+		return nil, ErrSteamFailReponse
+	}
+	var ret []store.WishlistItem
+	for _, w := range root.GetArray("response", "items") {
+		ret = append(ret, store.WishlistItem{
+			ID:       w.GetInt("appid"),
+			Priority: w.GetInt("priority"),
+			Added:    time.Unix(w.GetInt64("date_added"), 0),
+		})
+	}
+	return ret, nil
+}
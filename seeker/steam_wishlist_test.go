@@ -0,0 +1,128 @@
+package seeker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func TestMergeWishlist(t *testing.T) {
+	t1 := time.Unix(1500000000, 0)
+	t2 := time.Unix(1600000000, 0)
+	var tests = []struct {
+		old     store.Wishlist
+		wished  []store.WishlistItem
+		states  map[int]wishlistState
+		items   []store.WishlistItem
+		changes []store.WishlistChange
+	}{
+		{
+			// first sync notices no changes
+			store.Wishlist{},
+			[]store.WishlistItem{{ID: 10, Priority: 1, Added: t1}},
+			map[int]wishlistState{10: {name: "CS", earlyAccess: true}},
+			[]store.WishlistItem{{ID: 10, Name: "CS", Priority: 1, Added: t1, EarlyAccess: true}},
+			nil,
+		},
+		{
+			// discounted, left early access, removed and added
+			store.Wishlist{Synced: t1, Items: []store.WishlistItem{
+				{ID: 10, Name: "CS", Added: t1, EarlyAccess: true},
+				{ID: 20, Name: "TFC", Added: t1},
+			}},
+			[]store.WishlistItem{{ID: 10, Added: t1}, {ID: 30, Added: t2}},
+			map[int]wishlistState{10: {name: "CS", discount: 50}, 30: {name: "DoD"}},
+			[]store.WishlistItem{
+				{ID: 10, Name: "CS", Added: t1, Discount: 50},
+				{ID: 30, Name: "DoD", Added: t2},
+				{ID: 20, Name: "TFC", Added: t1, Removed: t2},
+			},
+			[]store.WishlistChange{
+				{ID: 10, Name: "CS", Kind: store.WishlistDiscounted},
+				{ID: 10, Name: "CS", Kind: store.WishlistLeftEarlyAccess},
+				{ID: 30, Name: "DoD", Kind: store.WishlistAdded},
+				{ID: 20, Name: "TFC", Kind: store.WishlistRemoved},
+			},
+		},
+		{
+			// still discounted, state failed to fetch and wished again
+			store.Wishlist{Synced: t1, Items: []store.WishlistItem{
+				{ID: 10, Name: "CS", Added: t1, Discount: 50},
+				{ID: 20, Name: "TFC", Added: t1, Discount: 10, EarlyAccess: true},
+				{ID: 30, Name: "DoD", Added: t1, Removed: t1},
+			}},
+			[]store.WishlistItem{{ID: 10, Added: t1}, {ID: 20, Added: t1}, {ID: 30, Added: t2}},
+			map[int]wishlistState{10: {name: "CS", discount: 75}, 30: {name: "DoD"}},
+			[]store.WishlistItem{
+				{ID: 10, Name: "CS", Added: t1, Discount: 75},
+				{ID: 20, Name: "TFC", Added: t1, Discount: 10, EarlyAccess: true},
+				{ID: 30, Name: "DoD", Added: t2},
+			},
+			[]store.WishlistChange{
+				{ID: 30, Name: "DoD", Kind: store.WishlistAdded},
+			},
+		},
+	}
+	for caseid, c := range tests {
+		res := mergeWishlist(c.old, c.wished, c.states, t2)
+		if !reflect.DeepEqual(res.Items, c.items) {
+			t.Errorf("case #%d, got items: %v, expected: %v", caseid+1, res.Items, c.items)
+		}
+		if !reflect.DeepEqual(res.Changes, c.changes) {
+			t.Errorf("case #%d, got changes: %v, expected: %v", caseid+1, res.Changes, c.changes)
+		}
+	}
+}
+
+func TestSyncWishlist(t *testing.T) {
+	db := storetest.New(t)
+	private := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == pathGetWishlist && private:
+			w.Write([]byte(`{"response":{}}`))
+		case r.URL.Path == pathGetWishlist:
+			w.Write([]byte(`{"response":{"items":[{"appid":10,"priority":1,"date_added":1500000000}]}}`))
+		case r.URL.Query().Get("appids") == "10":
+			fmt.Fprint(w, `{"10":{"success":true,"data":{"type":"game","name":"Counter-Strike","steam_appid":10,
+				"price_overview":{"currency":"USD","initial":999,"final":499,"discount_percent":50},
+				"genres":[{"id":"1","description":"Action"},{"id":"70","description":"Early Access"}]}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	defer func(p string) { pathGetAppDetail = p }(pathGetAppDetail)
+	pathGetAppDetail = ts.URL
+
+	steam := newSteamSeeker(SteamConfig{Portal: ts.URL, WorkerNum: 1}, db)
+	if _, err := steam.SyncWishlist(context.Background(), "76561197960287930"); err != nil {
+		t.Fatalf("SyncWishlist err: %v", err)
+	}
+	wl, err := db.GetWishlist("steam", "76561197960287930")
+	if err != nil {
+		t.Fatalf("GetWishlist err: %v", err)
+	}
+	expected := []store.WishlistItem{
+		{ID: 10, Name: "Counter-Strike", Priority: 1, Added: time.Unix(1500000000, 0), Discount: 50, EarlyAccess: true},
+	}
+	if wl.UserID != "76561197960287930" || wl.Synced.IsZero() || !reflect.DeepEqual(wl.Items, expected) {
+		t.Errorf("got wishlist: %v, expected items: %v", wl, expected)
+	}
+
+	// private wishlist leaves stored items as they are
+	private = true
+	if _, err := steam.SyncWishlist(context.Background(), "76561197960287930"); err != ErrSteamEmptyWishlist {
+		t.Errorf("got err: %v, expected: %v", err, ErrSteamEmptyWishlist)
+	}
+	if wl, _ := db.GetWishlist("steam", "76561197960287930"); !reflect.DeepEqual(wl.Items, expected) {
+		t.Errorf("got items: %v, expected: %v", wl.Items, expected)
+	}
+}
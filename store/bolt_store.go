@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 
 	"github.com/etcd-io/bbolt"
@@ -149,3 +150,789 @@ func (bs *BoltStore) GetGameRecord(platform string, subid string) (*GameRecord,
 	}
 	return &r, nil
 }
+
+// SavePricePoints appends price points of a region to game price histories
+func (bs *BoltStore) SavePricePoints(platform string, region string, points map[int]PricePoint) error {
+	bs.debugLog.Printf("Saving %d %s price points for region: %s", len(points), platform, region)
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(platform + StorePriceBucketSuffix))
+		if err != nil {
+			return err
+		}
+		for id, p := range points {
+			key := []byte(strconv.Itoa(id))
+			history := make(map[string][]PricePoint)
+			if value := b.Get(key); len(value) > 0 {
+				if err := Decode(value, &history); err != nil {
+					return err
+				}
+			}
+			history[region] = append(history[region], p)
+			value, err := Encode(history)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetPriceHistory of a game from bolt store, keyed by region
+func (bs *BoltStore) GetPriceHistory(platform string, id int) (map[string][]PricePoint, error) {
+	var value []byte
+	key := []byte(strconv.Itoa(id))
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(platform + StorePriceBucketSuffix)); b != nil {
+			value = b.Get(key)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	history := make(map[string][]PricePoint)
+	if len(value) > 0 {
+		if err := Decode(value, &history); err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+// GetAlertState from bolt store, empty if alert was never delivered
+func (bs *BoltStore) GetAlertState(key string) (string, error) {
+	var value []byte
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(StoreAlertBucket)); b != nil {
+			value = b.Get([]byte(key))
+		}
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// SaveAlertState to bolt store, an empty state removes the key
+func (bs *BoltStore) SaveAlertState(key string, state string) error {
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(StoreAlertBucket))
+		if err != nil {
+			return err
+		}
+		if state == "" {
+			return b.Delete([]byte(key))
+		}
+		return b.Put([]byte(key), []byte(state))
+	})
+}
+
+// SaveLinks to bolt store, replacing all saved links and canonical ids
+func (bs *BoltStore) SaveLinks(links []Link, canonical map[string]string) error {
+	bs.debugLog.Printf("Saving %d links of %d records.", len(links), len(canonical))
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range []string{StoreLinkBucket, StoreCanonicalBucket} {
+			if tx.Bucket([]byte(name)) != nil {
+				if err := tx.DeleteBucket([]byte(name)); err != nil {
+					return err
+				}
+			}
+		}
+		lb, err := tx.CreateBucket([]byte(StoreLinkBucket))
+		if err != nil {
+			return err
+		}
+		for _, l := range links {
+			value, err := Encode(l)
+			if err != nil {
+				return err
+			}
+			if err := lb.Put([]byte(LinkKey(l.A, l.B)), value); err != nil {
+				return err
+			}
+		}
+		cb, err := tx.CreateBucket([]byte(StoreCanonicalBucket))
+		if err != nil {
+			return err
+		}
+		for ref, id := range canonical {
+			if err := cb.Put([]byte(ref), []byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetLinks from bolt store ordered by their keys
+func (bs *BoltStore) GetLinks() ([]Link, error) {
+	var links []Link
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(StoreLinkBucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var l Link
+			if err := Decode(v, &l); err != nil {
+				return err
+			}
+			links = append(links, l)
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// GetCanonicalIDs from bolt store, mapping platform:id of records to canonical game ids
+func (bs *BoltStore) GetCanonicalIDs() (map[string]string, error) {
+	return bs.getStrings(StoreCanonicalBucket)
+}
+
+// SaveLinkOverride of a pair of records to bolt store, an empty override removes it
+func (bs *BoltStore) SaveLinkOverride(a, b string, override string) error {
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(StoreLinkOverrideBucket))
+		if err != nil {
+			return err
+		}
+		if override == "" {
+			return bucket.Delete([]byte(LinkKey(a, b)))
+		}
+		return bucket.Put([]byte(LinkKey(a, b)), []byte(override))
+	})
+}
+
+// GetLinkOverrides from bolt store keyed by LinkKey of the pairs
+func (bs *BoltStore) GetLinkOverrides() (map[string]string, error) {
+	return bs.getStrings(StoreLinkOverrideBucket)
+}
+
+// getStrings reads all string values of a bucket
+func (bs *BoltStore) getStrings(bucket string) (map[string]string, error) {
+	ret := make(map[string]string)
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			ret[string(k)] = string(v)
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ForEachGameRecord calls fn with every game record of a platform in bolt store,
+// iteration stops at the first error returned by fn. fn must not write to the store.
+func (bs *BoltStore) ForEachGameRecord(platform string, fn func(subid string, r GameRecord) error) error {
+	return bs.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(platform))
+		if b == nil {
+			return errors.New("bolt store no bucket found:" + string(platform))
+		}
+		return b.ForEach(func(k, v []byte) error {
+			if _, err := strconv.ParseInt(string(k), 10, 32); err != nil {
+				return nil
+			}
+			var r GameRecord
+			if err := Decode(v, &r); err != nil {
+				return err
+			}
+			return fn(string(k), r)
+		})
+	})
+}
+
+// SavePackageRecord to bolt store
+func (bs *BoltStore) SavePackageRecord(platform string, r PackageRecord) error {
+	bs.debugLog.Printf("Saving PackageRecord: %s, %d - %s.", platform, r.ID, r.Name)
+	value, err := Encode(r)
+	if err != nil {
+		return err
+	}
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(platform + StorePackageBucketSuffix))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(strconv.Itoa(r.ID)), value)
+	})
+}
+
+// GetPackageRecord from bolt store, ID of returned record is 0 if not found
+func (bs *BoltStore) GetPackageRecord(platform string, id int) (*PackageRecord, error) {
+	var value []byte
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(platform + StorePackageBucketSuffix)); b != nil {
+			value = b.Get([]byte(strconv.Itoa(id)))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var r PackageRecord
+	if len(value) > 0 {
+		if err := Decode(value, &r); err != nil {
+			return nil, err
+		}
+	}
+	return &r, nil
+}
+
+// SaveSkippedApp remembers an app skipped by its type in bolt store
+func (bs *BoltStore) SaveSkippedApp(platform string, id int, typ string) error {
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(platform + StoreSkippedBucketSuffix))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(strconv.Itoa(id)), []byte(typ))
+	})
+}
+
+// GetSkippedApps from bolt store, mapping app id to its type
+func (bs *BoltStore) GetSkippedApps(platform string) (map[int]string, error) {
+	apps := make(map[int]string)
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(platform + StoreSkippedBucketSuffix))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			if id, err := strconv.Atoi(string(k)); err == nil {
+				apps[id] = string(v)
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// DeleteSkippedApp from bolt store, so the app is fetched again
+func (bs *BoltStore) DeleteSkippedApp(platform string, id int) error {
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(platform + StoreSkippedBucketSuffix))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(strconv.Itoa(id)))
+	})
+}
+
+// SaveAppsSeen to bolt store, apps not in seen are left as they are
+func (bs *BoltStore) SaveAppsSeen(platform string, seen map[int]AppSeen) error {
+	bs.debugLog.Printf("Saving %d seen apps: %s.", len(seen), platform)
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(platform + StoreSeenBucketSuffix))
+		if err != nil {
+			return err
+		}
+		for id, s := range seen {
+			value, err := Encode(s)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(strconv.Itoa(id)), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetAppsSeen from bolt store, mapping app id to when it was seen
+func (bs *BoltStore) GetAppsSeen(platform string) (map[int]AppSeen, error) {
+	seen := make(map[int]AppSeen)
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(platform + StoreSeenBucketSuffix))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			id, err := strconv.Atoi(string(k))
+			if err != nil {
+				return nil
+			}
+			var s AppSeen
+			if err := Decode(v, &s); err != nil {
+				return err
+			}
+			seen[id] = s
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return seen, nil
+}
+
+// SaveReviews of a game to bolt store, replacing saved ones
+func (bs *BoltStore) SaveReviews(platform string, id int, rs ReviewSet) error {
+	bs.debugLog.Printf("Saving %d reviews: %s, %d.", len(rs.Reviews), platform, id)
+	value, err := Encode(rs)
+	if err != nil {
+		return err
+	}
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(platform + StoreReviewBucketSuffix))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(strconv.Itoa(id)), value)
+	})
+}
+
+// GetReviews of a game from bolt store, empty set if none collected
+func (bs *BoltStore) GetReviews(platform string, id int) (*ReviewSet, error) {
+	var value []byte
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(platform + StoreReviewBucketSuffix)); b != nil {
+			value = b.Get([]byte(strconv.Itoa(id)))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var rs ReviewSet
+	if len(value) > 0 {
+		if err := Decode(value, &rs); err != nil {
+			return nil, err
+		}
+	}
+	return &rs, nil
+}
+
+// SaveAchievements of a game to bolt store
+func (bs *BoltStore) SaveAchievements(platform string, id int, as AchievementSet) error {
+	bs.debugLog.Printf("Saving %d achievements: %s, %d.", len(as.Achievements), platform, id)
+	value, err := Encode(as)
+	if err != nil {
+		return err
+	}
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(platform + StoreAchievementBucketSuffix))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(strconv.Itoa(id)), value)
+	})
+}
+
+// GetAchievements of a game from bolt store, empty set if none collected
+func (bs *BoltStore) GetAchievements(platform string, id int) (*AchievementSet, error) {
+	var value []byte
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(platform + StoreAchievementBucketSuffix)); b != nil {
+			value = b.Get([]byte(strconv.Itoa(id)))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var as AchievementSet
+	if len(value) > 0 {
+		if err := Decode(value, &as); err != nil {
+			return nil, err
+		}
+	}
+	return &as, nil
+}
+
+// SaveNews of a game to bolt store
+func (bs *BoltStore) SaveNews(platform string, id int, ns NewsSet) error {
+	bs.debugLog.Printf("Saving %d news: %s, %d.", len(ns.Items), platform, id)
+	value, err := Encode(ns)
+	if err != nil {
+		return err
+	}
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(platform + StoreNewsBucketSuffix))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(strconv.Itoa(id)), value)
+	})
+}
+
+// GetNews of a game from bolt store, empty set if none collected
+func (bs *BoltStore) GetNews(platform string, id int) (*NewsSet, error) {
+	var value []byte
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(platform + StoreNewsBucketSuffix)); b != nil {
+			value = b.Get([]byte(strconv.Itoa(id)))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var ns NewsSet
+	if len(value) > 0 {
+		if err := Decode(value, &ns); err != nil {
+			return nil, err
+		}
+	}
+	return &ns, nil
+}
+
+// QueueApps for detail fetch in bolt store
+func (bs *BoltStore) QueueApps(platform string, ids []int) error {
+	bs.debugLog.Printf("Queueing %d apps: %s.", len(ids), platform)
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(platform + StoreQueueBucketSuffix))
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := b.Put([]byte(strconv.Itoa(id)), []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetQueuedApps from bolt store in ascending order
+func (bs *BoltStore) GetQueuedApps(platform string) ([]int, error) {
+	var ids []int
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(platform + StoreQueueBucketSuffix))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			if id, err := strconv.Atoi(string(k)); err == nil {
+				ids = append(ids, id)
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// DequeueApp from bolt store, it is fine if app is not queued
+func (bs *BoltStore) DequeueApp(platform string, id int) error {
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(platform + StoreQueueBucketSuffix))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(strconv.Itoa(id)))
+	})
+}
+
+// SaveLibrary of a user to bolt store, replacing the saved one
+func (bs *BoltStore) SaveLibrary(platform string, userid string, lib Library) error {
+	bs.debugLog.Printf("Saving library of %d games: %s, %s.", len(lib.Games), platform, userid)
+	value, err := Encode(lib)
+	if err != nil {
+		return err
+	}
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(platform + StoreUserBucketSuffix))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(userid), value)
+	})
+}
+
+// GetLibrary of a user from bolt store, empty library if none imported
+func (bs *BoltStore) GetLibrary(platform string, userid string) (*Library, error) {
+	var value []byte
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(platform + StoreUserBucketSuffix)); b != nil {
+			value = b.Get([]byte(userid))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var lib Library
+	if len(value) > 0 {
+		if err := Decode(value, &lib); err != nil {
+			return nil, err
+		}
+	}
+	return &lib, nil
+}
+
+// SaveWishlist of a user to bolt store, replacing the saved one
+func (bs *BoltStore) SaveWishlist(platform string, userid string, wl Wishlist) error {
+	bs.debugLog.Printf("Saving wishlist of %d items: %s, %s.", len(wl.Items), platform, userid)
+	value, err := Encode(wl)
+	if err != nil {
+		return err
+	}
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(platform + StoreWishlistBucketSuffix))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(userid), value)
+	})
+}
+
+// GetWishlist of a user from bolt store, empty wishlist if never synced
+func (bs *BoltStore) GetWishlist(platform string, userid string) (*Wishlist, error) {
+	var value []byte
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(platform + StoreWishlistBucketSuffix)); b != nil {
+			value = b.Get([]byte(userid))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var wl Wishlist
+	if len(value) > 0 {
+		if err := Decode(value, &wl); err != nil {
+			return nil, err
+		}
+	}
+	return &wl, nil
+}
+
+// SavePlayerSamples of a game to bolt store, replacing the stored ones
+func (bs *BoltStore) SavePlayerSamples(platform string, id int, samples []PlayerSample) error {
+	bs.debugLog.Printf("Saving %d player samples: %s, %d.", len(samples), platform, id)
+	value, err := Encode(samples)
+	if err != nil {
+		return err
+	}
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(platform + StorePlayerBucketSuffix))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(strconv.Itoa(id)), value)
+	})
+}
+
+// GetPlayerSamples of a game from bolt store sorted by time, nil if none sampled
+func (bs *BoltStore) GetPlayerSamples(platform string, id int) ([]PlayerSample, error) {
+	var value []byte
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(platform + StorePlayerBucketSuffix)); b != nil {
+			value = b.Get([]byte(strconv.Itoa(id)))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var samples []PlayerSample
+	if len(value) > 0 {
+		if err := Decode(value, &samples); err != nil {
+			return nil, err
+		}
+	}
+	return samples, nil
+}
+
+// SaveSimilarityIndex to bolt store, replacing the saved vectors and postings
+func (bs *BoltStore) SaveSimilarityIndex(platform string, vectors map[int]map[string]float64, postings map[string][]Posting) error {
+	bs.infoLog.Printf("Saving similarity index of %d %s records, %d terms", len(vectors), platform, len(postings))
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range []string{platform + StoreVectorBucketSuffix, platform + StorePostingBucketSuffix} {
+			if tx.Bucket([]byte(name)) != nil {
+				if err := tx.DeleteBucket([]byte(name)); err != nil {
+					return err
+				}
+			}
+		}
+		vb, err := tx.CreateBucket([]byte(platform + StoreVectorBucketSuffix))
+		if err != nil {
+			return err
+		}
+		for id, v := range vectors {
+			value, err := Encode(v)
+			if err != nil {
+				return err
+			}
+			if err := vb.Put([]byte(strconv.Itoa(id)), value); err != nil {
+				return err
+			}
+		}
+		pb, err := tx.CreateBucket([]byte(platform + StorePostingBucketSuffix))
+		if err != nil {
+			return err
+		}
+		for term, ps := range postings {
+			value, err := Encode(ps)
+			if err != nil {
+				return err
+			}
+			if err := pb.Put([]byte(term), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetSimilarityVector of a record from bolt store, nil if it is not indexed
+func (bs *BoltStore) GetSimilarityVector(platform string, id int) (map[string]float64, error) {
+	var value []byte
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(platform + StoreVectorBucketSuffix)); b != nil {
+			value = b.Get([]byte(strconv.Itoa(id)))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var v map[string]float64
+	if len(value) > 0 {
+		if err := Decode(value, &v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// GetPostings of feature terms from bolt store, terms not indexed are left out
+func (bs *BoltStore) GetPostings(platform string, terms []string) (map[string][]Posting, error) {
+	ret := make(map[string][]Posting)
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(platform + StorePostingBucketSuffix))
+		if b == nil {
+			return nil
+		}
+		for _, term := range terms {
+			value := b.Get([]byte(term))
+			if len(value) == 0 {
+				continue
+			}
+			var ps []Posting
+			if err := Decode(value, &ps); err != nil {
+				return err
+			}
+			ret[term] = ps
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// SaveClusters to bolt store, replacing saved clusters and setting cluster
+// of every record of the platform, records not assigned are set to 0
+func (bs *BoltStore) SaveClusters(platform string, clusters []Cluster, assignments map[int]int) error {
+	bs.infoLog.Printf("Saving %d clusters of %d %s records", len(clusters), len(assignments), platform)
+	value, err := Encode(clusters)
+	if err != nil {
+		return err
+	}
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		cb, err := tx.CreateBucketIfNotExists([]byte(platform + StoreClusterBucketSuffix))
+		if err != nil {
+			return err
+		}
+		if err := cb.Put([]byte(StoreClusterKey), value); err != nil {
+			return err
+		}
+		b := tx.Bucket([]byte(platform))
+		if b == nil {
+			return errors.New("bolt store no bucket found:" + string(platform))
+		}
+		updated := make(map[string][]byte)
+		if err := b.ForEach(func(k, v []byte) error {
+			id, err := strconv.Atoi(string(k))
+			if err != nil {
+				return nil
+			}
+			var r GameRecord
+			if err := Decode(v, &r); err != nil {
+				return err
+			}
+			if r.Cluster == assignments[id] {
+				return nil
+			}
+			r.Cluster = assignments[id]
+			value, err := Encode(r)
+			if err != nil {
+				return err
+			}
+			updated[string(k)] = value
+			return nil
+		}); err != nil {
+			return err
+		}
+		for k, v := range updated {
+			if err := b.Put([]byte(k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetClusters from bolt store ordered by id, nil if never clustered
+func (bs *BoltStore) GetClusters(platform string) ([]Cluster, error) {
+	var value []byte
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(platform + StoreClusterBucketSuffix)); b != nil {
+			value = b.Get([]byte(StoreClusterKey))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var clusters []Cluster
+	if len(value) > 0 {
+		if err := Decode(value, &clusters); err != nil {
+			return nil, err
+		}
+	}
+	return clusters, nil
+}
+
+// SaveGenreModel to bolt store by name, replacing the saved one
+func (bs *BoltStore) SaveGenreModel(name string, m GenreModel) error {
+	bs.infoLog.Printf("Saving genre model %s of %d genres", name, len(m.Genres))
+	value, err := Encode(m)
+	if err != nil {
+		return err
+	}
+	return bs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(StoreModelBucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(name), value)
+	})
+}
+
+// GetGenreModel by name from bolt store, nil if never trained
+func (bs *BoltStore) GetGenreModel(name string) (*GenreModel, error) {
+	var value []byte
+	if err := bs.db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(StoreModelBucket)); b != nil {
+			value = b.Get([]byte(name))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if len(value) == 0 {
+		return nil, nil
+	}
+	var m GenreModel
+	if err := Decode(value, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
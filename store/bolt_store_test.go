@@ -1,8 +1,10 @@
 package store
 
 import (
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var storeCfg = Config{
@@ -114,3 +116,154 @@ func TestSaveGetGameRecord(t *testing.T) {
 	}
 	t.Logf("Saved game list: %v", gl)
 }
+
+func TestSaveGetPriceHistory(t *testing.T) {
+	cfg := storeCfg
+	cfg.StorePath = filepath.Join(t.TempDir(), "test.db")
+	store, err := NewBoltStore(cfg)
+	if err != nil {
+		t.Errorf("TestNewBoltStore err: %v", err)
+		return
+	}
+	defer store.db.Close()
+	now := time.Now().UTC().Truncate(time.Second)
+	var tests = []struct {
+		region string
+		points map[int]PricePoint
+	}{
+		{
+			"cn",
+			map[int]PricePoint{
+				10: {Time: now, Currency: "CNY", Initial: 3700, Final: 3700},
+				20: {Time: now, Currency: "CNY", Initial: 2200, Final: 1100, Discount: 50},
+			},
+		},
+		{
+			"us",
+			map[int]PricePoint{
+				10: {Time: now, Currency: "USD", Initial: 999, Final: 999},
+			},
+		},
+		{
+			"cn",
+			map[int]PricePoint{
+				10: {Time: now.Add(time.Hour), Currency: "CNY", Initial: 3700, Final: 1850, Discount: 50},
+			},
+		},
+	}
+
+	for caseid, c := range tests {
+		if err := store.SavePricePoints("test", c.region, c.points); err != nil {
+			t.Errorf("case #%d, SavePricePoints err: %v", caseid+1, err)
+		}
+	}
+	h, err := store.GetPriceHistory("test", 10)
+	if err != nil {
+		t.Errorf("GetPriceHistory err: %v", err)
+	}
+	expected := map[string][]PricePoint{
+		"cn": {tests[0].points[10], tests[2].points[10]},
+		"us": {tests[1].points[10]},
+	}
+	if !reflect.DeepEqual(h, expected) {
+		t.Errorf("got: %v, expected: %v", h, expected)
+	}
+	t.Logf("Result: %v", h)
+}
+
+func TestForEachGameRecord(t *testing.T) {
+	cfg := storeCfg
+	cfg.StorePath = filepath.Join(t.TempDir(), "test.db")
+	store, err := NewBoltStore(cfg)
+	if err != nil {
+		t.Errorf("TestNewBoltStore err: %v", err)
+		return
+	}
+	defer store.db.Close()
+	records := map[string]GameRecord{
+		"10": {Name: "Counter-Strike", ID: 10},
+		"20": {Name: "Team Fortress Classic", ID: 20},
+	}
+	for k, r := range records {
+		if err := store.SaveGameRecord("test", k, r); err != nil {
+			t.Errorf("SaveGameRecord err: %v", err)
+		}
+	}
+	if err := store.SaveGameList("test", map[int]string{10: "Counter-Strike"}); err != nil {
+		t.Errorf("SaveGameList err: %v", err)
+	}
+	got := make(map[string]GameRecord)
+	if err := store.ForEachGameRecord("test", func(subid string, r GameRecord) error {
+		got[subid] = r
+		return nil
+	}); err != nil {
+		t.Errorf("ForEachGameRecord err: %v", err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("got: %v, expected: %v", got, records)
+	}
+}
+
+func TestSaveGetSkippedApps(t *testing.T) {
+	cfg := storeCfg
+	cfg.StorePath = filepath.Join(t.TempDir(), "test.db")
+	store, err := NewBoltStore(cfg)
+	if err != nil {
+		t.Errorf("TestNewBoltStore err: %v", err)
+		return
+	}
+	defer store.db.Close()
+	skipped := map[int]string{
+		1: "music",
+		2: "video",
+	}
+	for id, typ := range skipped {
+		if err := store.SaveSkippedApp("test", id, typ); err != nil {
+			t.Errorf("SaveSkippedApp err: %v", err)
+		}
+	}
+	res, err := store.GetSkippedApps("test")
+	if err != nil {
+		t.Errorf("GetSkippedApps err: %v", err)
+	}
+	if !reflect.DeepEqual(res, skipped) {
+		t.Errorf("got: %v, expected: %v", res, skipped)
+	}
+	if err := store.DeleteSkippedApp("test", 1); err != nil {
+		t.Errorf("DeleteSkippedApp err: %v", err)
+	}
+	res, err = store.GetSkippedApps("test")
+	if err != nil {
+		t.Errorf("GetSkippedApps err: %v", err)
+	}
+	if expected := map[int]string{2: "video"}; !reflect.DeepEqual(res, expected) {
+		t.Errorf("got: %v, expected: %v", res, expected)
+	}
+}
+
+func TestQueueDequeueApps(t *testing.T) {
+	cfg := storeCfg
+	cfg.StorePath = filepath.Join(t.TempDir(), "test.db")
+	store, err := NewBoltStore(cfg)
+	if err != nil {
+		t.Errorf("TestNewBoltStore err: %v", err)
+		return
+	}
+	defer store.db.Close()
+	if err := store.QueueApps("test", []int{570, 10, 220}); err != nil {
+		t.Errorf("QueueApps err: %v", err)
+	}
+	if err := store.DequeueApp("test", 220); err != nil {
+		t.Errorf("DequeueApp err: %v", err)
+	}
+	if err := store.DequeueApp("test", 1); err != nil {
+		t.Errorf("DequeueApp not queued err: %v", err)
+	}
+	res, err := store.GetQueuedApps("test")
+	if err != nil {
+		t.Errorf("GetQueuedApps err: %v", err)
+	}
+	if expected := []int{10, 570}; !reflect.DeepEqual(res, expected) {
+		t.Errorf("got: %v, expected: %v", res, expected)
+	}
+}
@@ -65,3 +65,220 @@ func NewDummyStore(cfg Config) (*DummyStore, error) {
 		LogLevel: "debug",
 	}, nil
 }
+
+// SavePricePoints to dummy store
+func (ds *DummyStore) SavePricePoints(platform string, region string, points map[int]PricePoint) error {
+	fmt.Printf("Saving %d %s price points for region %s\n", len(points), platform, region)
+	return nil
+}
+
+// GetPriceHistory from dummy store, always empty
+func (ds *DummyStore) GetPriceHistory(platform string, id int) (map[string][]PricePoint, error) {
+	return map[string][]PricePoint{}, nil
+}
+
+// GetGameRecord from dummy store, always empty
+func (ds *DummyStore) GetGameRecord(platform string, subid string) (*GameRecord, error) {
+	return &GameRecord{}, nil
+}
+
+// GetAlertState from dummy store, alerts are never delivered
+func (ds *DummyStore) GetAlertState(key string) (string, error) {
+	return "", nil
+}
+
+// SaveAlertState to dummy store
+func (ds *DummyStore) SaveAlertState(key string, state string) error {
+	fmt.Printf("Saving alert state %s: %s\n", key, state)
+	return nil
+}
+
+// ForEachGameRecord of dummy store, there is none
+func (ds *DummyStore) ForEachGameRecord(platform string, fn func(subid string, r GameRecord) error) error {
+	return nil
+}
+
+// SavePackageRecord to dummy store
+func (ds *DummyStore) SavePackageRecord(platform string, r PackageRecord) error {
+	fmt.Printf("Saving package %d to %s, detail %v\n", r.ID, platform, r)
+	return nil
+}
+
+// GetPackageRecord from dummy store, always empty
+func (ds *DummyStore) GetPackageRecord(platform string, id int) (*PackageRecord, error) {
+	return &PackageRecord{}, nil
+}
+
+// SaveSkippedApp to dummy store
+func (ds *DummyStore) SaveSkippedApp(platform string, id int, typ string) error {
+	fmt.Printf("Skipping %s app %d of type %s\n", platform, id, typ)
+	return nil
+}
+
+// GetSkippedApps from dummy store, there is none
+func (ds *DummyStore) GetSkippedApps(platform string) (map[int]string, error) {
+	return map[int]string{}, nil
+}
+
+// DeleteSkippedApp from dummy store
+func (ds *DummyStore) DeleteSkippedApp(platform string, id int) error {
+	return nil
+}
+
+// SaveReviews to dummy store
+func (ds *DummyStore) SaveReviews(platform string, id int, rs ReviewSet) error {
+	fmt.Printf("Saving %d reviews of %s game %d\n", len(rs.Reviews), platform, id)
+	return nil
+}
+
+// GetReviews from dummy store, always empty
+func (ds *DummyStore) GetReviews(platform string, id int) (*ReviewSet, error) {
+	return &ReviewSet{}, nil
+}
+
+// SavePlayerSamples to dummy store
+func (ds *DummyStore) SavePlayerSamples(platform string, id int, samples []PlayerSample) error {
+	fmt.Printf("Saving %d player samples of %s game %d\n", len(samples), platform, id)
+	return nil
+}
+
+// GetPlayerSamples from dummy store, always empty
+func (ds *DummyStore) GetPlayerSamples(platform string, id int) ([]PlayerSample, error) {
+	return nil, nil
+}
+
+// SaveSimilarityIndex to dummy store
+func (ds *DummyStore) SaveSimilarityIndex(platform string, vectors map[int]map[string]float64, postings map[string][]Posting) error {
+	fmt.Printf("Saving similarity index of %d %s records, %d terms\n", len(vectors), platform, len(postings))
+	return nil
+}
+
+// GetSimilarityVector from dummy store, always empty
+func (ds *DummyStore) GetSimilarityVector(platform string, id int) (map[string]float64, error) {
+	return nil, nil
+}
+
+// GetPostings from dummy store, always empty
+func (ds *DummyStore) GetPostings(platform string, terms []string) (map[string][]Posting, error) {
+	return nil, nil
+}
+
+// SaveClusters to dummy store
+func (ds *DummyStore) SaveClusters(platform string, clusters []Cluster, assignments map[int]int) error {
+	fmt.Printf("Saving %d clusters of %d %s records\n", len(clusters), len(assignments), platform)
+	return nil
+}
+
+// GetClusters from dummy store, always empty
+func (ds *DummyStore) GetClusters(platform string) ([]Cluster, error) {
+	return nil, nil
+}
+
+// SaveGenreModel to dummy store
+func (ds *DummyStore) SaveGenreModel(name string, m GenreModel) error {
+	fmt.Printf("Saving genre model %s of %d genres\n", name, len(m.Genres))
+	return nil
+}
+
+// GetGenreModel from dummy store, always empty
+func (ds *DummyStore) GetGenreModel(name string) (*GenreModel, error) {
+	return nil, nil
+}
+
+// SaveAchievements to dummy store
+func (ds *DummyStore) SaveAchievements(platform string, id int, as AchievementSet) error {
+	fmt.Printf("Saving %d achievements of %s game %d\n", len(as.Achievements), platform, id)
+	return nil
+}
+
+// GetAchievements from dummy store, always empty
+func (ds *DummyStore) GetAchievements(platform string, id int) (*AchievementSet, error) {
+	return &AchievementSet{}, nil
+}
+
+// SaveNews to dummy store
+func (ds *DummyStore) SaveNews(platform string, id int, ns NewsSet) error {
+	fmt.Printf("Saving %d news of %s game %d\n", len(ns.Items), platform, id)
+	return nil
+}
+
+// GetNews from dummy store, always empty
+func (ds *DummyStore) GetNews(platform string, id int) (*NewsSet, error) {
+	return &NewsSet{}, nil
+}
+
+// QueueApps to dummy store
+func (ds *DummyStore) QueueApps(platform string, ids []int) error {
+	fmt.Printf("Queueing %d %s apps\n", len(ids), platform)
+	return nil
+}
+
+// GetQueuedApps from dummy store, always empty
+func (ds *DummyStore) GetQueuedApps(platform string) ([]int, error) {
+	return nil, nil
+}
+
+// DequeueApp from dummy store
+func (ds *DummyStore) DequeueApp(platform string, id int) error {
+	return nil
+}
+
+// SaveLibrary to dummy store
+func (ds *DummyStore) SaveLibrary(platform string, userid string, lib Library) error {
+	fmt.Printf("Saving %d %s games of user %s\n", len(lib.Games), platform, userid)
+	return nil
+}
+
+// GetLibrary from dummy store, always empty
+func (ds *DummyStore) GetLibrary(platform string, userid string) (*Library, error) {
+	return &Library{}, nil
+}
+
+// SaveWishlist to dummy store
+func (ds *DummyStore) SaveWishlist(platform string, userid string, wl Wishlist) error {
+	fmt.Printf("Saving %d %s wishlist items of user %s\n", len(wl.Items), platform, userid)
+	return nil
+}
+
+// GetWishlist from dummy store, always empty
+func (ds *DummyStore) GetWishlist(platform string, userid string) (*Wishlist, error) {
+	return &Wishlist{}, nil
+}
+
+// SaveAppsSeen to dummy store
+func (ds *DummyStore) SaveAppsSeen(platform string, seen map[int]AppSeen) error {
+	fmt.Printf("Saving %d seen %s apps\n", len(seen), platform)
+	return nil
+}
+
+// GetAppsSeen from dummy store, always empty
+func (ds *DummyStore) GetAppsSeen(platform string) (map[int]AppSeen, error) {
+	return make(map[int]AppSeen), nil
+}
+
+// SaveLinks to dummy store
+func (ds *DummyStore) SaveLinks(links []Link, canonical map[string]string) error {
+	fmt.Printf("Saving %d links of %d records\n", len(links), len(canonical))
+	return nil
+}
+
+// GetLinks from dummy store, always empty
+func (ds *DummyStore) GetLinks() ([]Link, error) {
+	return nil, nil
+}
+
+// GetCanonicalIDs from dummy store, always empty
+func (ds *DummyStore) GetCanonicalIDs() (map[string]string, error) {
+	return make(map[string]string), nil
+}
+
+// SaveLinkOverride to dummy store
+func (ds *DummyStore) SaveLinkOverride(a, b string, override string) error {
+	fmt.Printf("Saving link override %s: %s\n", LinkKey(a, b), override)
+	return nil
+}
+
+// GetLinkOverrides from dummy store, always empty
+func (ds *DummyStore) GetLinkOverrides() (map[string]string, error) {
+	return make(map[string]string), nil
+}
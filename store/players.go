@@ -0,0 +1,17 @@
+package store
+
+import (
+	"time"
+)
+
+// PlayerSample is concurrent player count of a game. Raw samples have zero Span,
+// rolled up samples cover Span from Time with average Count and Min, Max of it.
+// Samples is the number of raw samples averaged, zero counts as one.
+type PlayerSample struct {
+	Time    time.Time
+	Span    time.Duration
+	Count   int
+	Min     int
+	Max     int
+	Samples int
+}
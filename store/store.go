@@ -1,6 +1,13 @@
 // Package store provides database functionalities
 package store
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // GameStore represents general interfaces of store package.
 // Implementations are corresponding to different databases.
 type GameStore interface {
@@ -9,6 +16,46 @@ type GameStore interface {
 	GetGameList(platform string) (map[int]string, error)
 	GetSavedGameList(platform string) (map[int]string, error)
 	SaveGameRecord(platform string, subid string, r GameRecord) error
+	GetGameRecord(platform string, subid string) (*GameRecord, error)
+	ForEachGameRecord(platform string, fn func(subid string, r GameRecord) error) error
+	SavePricePoints(platform string, region string, points map[int]PricePoint) error
+	GetPriceHistory(platform string, id int) (map[string][]PricePoint, error)
+	SaveSkippedApp(platform string, id int, typ string) error
+	GetSkippedApps(platform string) (map[int]string, error)
+	DeleteSkippedApp(platform string, id int) error
+	SaveAppsSeen(platform string, seen map[int]AppSeen) error
+	GetAppsSeen(platform string) (map[int]AppSeen, error)
+	SavePackageRecord(platform string, r PackageRecord) error
+	GetPackageRecord(platform string, id int) (*PackageRecord, error)
+	SaveReviews(platform string, id int, rs ReviewSet) error
+	GetReviews(platform string, id int) (*ReviewSet, error)
+	SaveAchievements(platform string, id int, as AchievementSet) error
+	GetAchievements(platform string, id int) (*AchievementSet, error)
+	SaveNews(platform string, id int, ns NewsSet) error
+	GetNews(platform string, id int) (*NewsSet, error)
+	QueueApps(platform string, ids []int) error
+	GetQueuedApps(platform string) ([]int, error)
+	DequeueApp(platform string, id int) error
+	SaveLibrary(platform string, userid string, lib Library) error
+	GetLibrary(platform string, userid string) (*Library, error)
+	SaveWishlist(platform string, userid string, wl Wishlist) error
+	GetWishlist(platform string, userid string) (*Wishlist, error)
+	SavePlayerSamples(platform string, id int, samples []PlayerSample) error
+	GetPlayerSamples(platform string, id int) ([]PlayerSample, error)
+	SaveSimilarityIndex(platform string, vectors map[int]map[string]float64, postings map[string][]Posting) error
+	GetSimilarityVector(platform string, id int) (map[string]float64, error)
+	GetPostings(platform string, terms []string) (map[string][]Posting, error)
+	SaveClusters(platform string, clusters []Cluster, assignments map[int]int) error
+	GetClusters(platform string) ([]Cluster, error)
+	SaveGenreModel(name string, m GenreModel) error
+	GetGenreModel(name string) (*GenreModel, error)
+	GetAlertState(key string) (string, error)
+	SaveAlertState(key string, state string) error
+	SaveLinks(links []Link, canonical map[string]string) error
+	GetLinks() ([]Link, error)
+	GetCanonicalIDs() (map[string]string, error)
+	SaveLinkOverride(a, b string, override string) error
+	GetLinkOverrides() (map[string]string, error)
 }
 
 // Config is the configuration struct of seeker
@@ -21,6 +68,60 @@ type Config struct {
 var (
 	// StoreGameListKey is sub-key name placing full game list of a platform
 	StoreGameListKey = "index"
+	// StoreClusterKey is the key of cluster list in cluster bucket
+	StoreClusterKey = "clusters"
+	// StorePriceBucketSuffix is appended to platform name to form the bucket
+	// holding price histories of that platform
+	StorePriceBucketSuffix = "_prices"
+	// StoreSkippedBucketSuffix is appended to platform name to form the bucket
+	// holding types of apps skipped by seeker
+	StoreSkippedBucketSuffix = "_skipped"
+	// StoreSeenBucketSuffix is appended to platform name to form the bucket
+	// keeping when apps were seen in platform app list
+	StoreSeenBucketSuffix = "_seen"
+	// StorePackageBucketSuffix is appended to platform name to form the bucket
+	// holding package details of that platform
+	StorePackageBucketSuffix = "_packages"
+	// StoreReviewBucketSuffix is appended to platform name to form the bucket
+	// holding user reviews of that platform
+	StoreReviewBucketSuffix = "_reviews"
+	// StoreAchievementBucketSuffix is appended to platform name to form the bucket
+	// keeping achievements of games
+	StoreAchievementBucketSuffix = "_achievements"
+	// StoreNewsBucketSuffix is appended to platform name to form the bucket
+	// keeping news of games
+	StoreNewsBucketSuffix = "_news"
+	// StoreQueueBucketSuffix is appended to platform name to form the bucket
+	// keeping apps queued for detail fetch
+	StoreQueueBucketSuffix = "_queue"
+	// StoreUserBucketSuffix is appended to platform name to form the bucket
+	// keeping game libraries of users
+	StoreUserBucketSuffix = "_users"
+	// StoreWishlistBucketSuffix is appended to platform name to form the bucket
+	// keeping wishlists of users
+	StoreWishlistBucketSuffix = "_wishlists"
+	// StorePlayerBucketSuffix is appended to platform name to form the bucket
+	// keeping concurrent player samples
+	StorePlayerBucketSuffix = "_players"
+	// StoreVectorBucketSuffix is appended to platform name to form the bucket
+	// keeping feature vectors of the similarity index
+	StoreVectorBucketSuffix = "_vectors"
+	// StorePostingBucketSuffix is appended to platform name to form the bucket
+	// keeping postings of feature terms of the similarity index
+	StorePostingBucketSuffix = "_postings"
+	// StoreClusterBucketSuffix is appended to platform name to form the bucket
+	// keeping clusters found by clustering analysis
+	StoreClusterBucketSuffix = "_clusters"
+	// StoreAlertBucket is the bucket keeping delivered alert states
+	StoreAlertBucket = "alerts"
+	// StoreLinkBucket is the bucket keeping matches of records across platforms
+	StoreLinkBucket = "links"
+	// StoreCanonicalBucket is the bucket mapping records to canonical game ids
+	StoreCanonicalBucket = "canonical"
+	// StoreModelBucket is the bucket keeping trained models by name
+	StoreModelBucket = "models"
+	// StoreLinkOverrideBucket is the bucket keeping manually confirmed or rejected matches
+	StoreLinkOverrideBucket = "link_overrides"
 )
 
 // New creates a new GameStore according to configuration
@@ -38,10 +139,415 @@ func New(cfg *Config) (GameStore, error) {
 type GameRecord struct {
 	Name        string
 	ID          int
+	Type        string
 	RequiredAge int
 	Description string
 	About       string
 	Languages   string
 	Developers  []string
 	Publishers  []string
+	// ReleaseYear and ReleaseMonth are the release year and month of records
+	// stored before Release was parsed, 0 if unknown. They are not set anymore,
+	// release.Of falls back to them until such records are fetched again.
+	ReleaseYear  int
+	ReleaseMonth int
+	// Release is the release date as precise as the platform tells it
+	Release ReleaseDate
+	// Genres and Categories are as named on store page, e.g. Action or Single-player
+	Genres     []string
+	Categories []string
+	// Platforms the game runs on: windows, mac or linux
+	Platforms []string
+	// IsFree is set for free to play games, Metacritic is the critic score, 0 if none
+	IsFree     bool
+	Metacritic int
+	// Cluster is the id of the cluster found by clustering analysis, 0 if none
+	Cluster int
+	// ShortDescription is the one paragraph summary of the game
+	ShortDescription string
+	// Localized texts keyed by language code as in LanguageSupport, e.g. zh-CN
+	Localized map[string]LocalizedText
+	// LanguageSupport is the structured form of Languages
+	LanguageSupport []LanguageSupport
+	// Requirements keyed by platform: pc, mac or linux
+	Requirements map[string]Requirements
+	// Cleaned forms of Description and About, raw html is kept above
+	DescriptionText     string
+	DescriptionMarkdown string
+	AboutText           string
+	AboutMarkdown       string
+	// Media embedded in description and about
+	Media []Media
+	// DLC ids of a game, FullGame is the base game id of a DLC or soundtrack
+	DLC      []int
+	FullGame int
+	// Packages the game is sold in, and purchase options grouped as on store page
+	Packages      []int
+	PackageGroups []PackageGroup
+	// Delisted games are gone from platform app list or store, first and
+	// last seen are when the game was listed, set on delisting or restoring
+	Delisted  bool
+	FirstSeen time.Time
+	LastSeen  time.Time
+	// Fetched is when the record was last fetched from platform
+	Fetched time.Time
+}
+
+// Precisions of release dates
+const (
+	PrecisionUnknown = "unknown"
+	PrecisionDay     = "day"
+	PrecisionMonth   = "month"
+	PrecisionQuarter = "quarter"
+	PrecisionYear    = "year"
+)
+
+// ReleaseDate is a release date of a game. Date is the first day of the period
+// named by Precision, e.g. 1 Jul 2024 of Q3 2024, zero if unknown. Raw is the
+// date text as the platform tells it, ComingSoon is set until the game is out.
+type ReleaseDate struct {
+	Date       time.Time
+	Precision  string
+	ComingSoon bool
+	Raw        string
+}
+
+// Known reports if release date has a date of any precision
+func (d ReleaseDate) Known() bool {
+	return d.Precision != "" && d.Precision != PrecisionUnknown
+}
+
+// End is the first day after the period of release date, zero if unknown
+func (d ReleaseDate) End() time.Time {
+	switch d.Precision {
+	case PrecisionDay:
+		return d.Date.AddDate(0, 0, 1)
+	case PrecisionMonth:
+		return d.Date.AddDate(0, 1, 0)
+	case PrecisionQuarter:
+		return d.Date.AddDate(0, 3, 0)
+	case PrecisionYear:
+		return d.Date.AddDate(1, 0, 0)
+	}
+	return time.Time{}
+}
+
+// String formats release date by its precision, e.g. 1 Nov 2000 or Q3 2024
+func (d ReleaseDate) String() string {
+	switch d.Precision {
+	case PrecisionDay:
+		return d.Date.Format("2 Jan 2006")
+	case PrecisionMonth:
+		return d.Date.Format("Jan 2006")
+	case PrecisionQuarter:
+		return fmt.Sprintf("Q%d %d", (int(d.Date.Month())-1)/3+1, d.Date.Year())
+	case PrecisionYear:
+		return strconv.Itoa(d.Date.Year())
+	}
+	if d.Raw != "" {
+		return d.Raw
+	}
+	return PrecisionUnknown
+}
+
+// PackageGroup is a group of purchase options of a game
+type PackageGroup struct {
+	Name    string
+	Title   string
+	Options []PackageOption
+}
+
+// PackageOption is one purchasable package, price is discounted in cents
+type PackageOption struct {
+	PackageID int
+	Text      string
+	Price     int
+}
+
+// PackageRecord represents a package (sub) sold on a platform, a bundle of apps
+type PackageRecord struct {
+	ID    int
+	Name  string
+	Apps  []PackageApp
+	Price PricePoint
+}
+
+// PackageApp is an app included in a package
+type PackageApp struct {
+	ID   int
+	Name string
+}
+
+// Media is an image or video referenced by game texts
+type Media struct {
+	Type string
+	URL  string
+}
+
+// Requirements are minimum and recommended hardware of one platform
+type Requirements struct {
+	Minimum     HardwareSpec
+	Recommended HardwareSpec
+}
+
+// HardwareSpec is a parsed hardware requirement level. Sizes are in MB.
+// Unparsed lists fields that could not be extracted from Raw text,
+// they should not be trusted.
+type HardwareSpec struct {
+	OS       string
+	CPU      string
+	RAM      int
+	GPU      string
+	VRAM     int
+	DirectX  string
+	OpenGL   string
+	Disk     int
+	Raw      string
+	Unparsed []string
+}
+
+// LanguageSupport describes how a game supports one language.
+// Code is ISO 639-1 with optional region, e.g. en, zh-CN, empty if unknown.
+type LanguageSupport struct {
+	Code      string
+	Name      string
+	Interface bool
+	FullAudio bool
+	Subtitles bool
+}
+
+// SupportsLanguage reports if game has a language feature in language code,
+// feature is one of interface, audio or subtitles. Code without region
+// matches all its regional variants, e.g. zh matches zh-CN.
+func (r GameRecord) SupportsLanguage(code string, feature string) bool {
+	for _, l := range r.LanguageSupport {
+		if l.Code != code && !strings.HasPrefix(l.Code, code+"-") {
+			continue
+		}
+		switch feature {
+		case "interface":
+			if l.Interface {
+				return true
+			}
+		case "audio":
+			if l.FullAudio {
+				return true
+			}
+		case "subtitles":
+			if l.Subtitles {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LocalizedText holds game texts in one language
+type LocalizedText struct {
+	Name             string
+	Description      string
+	About            string
+	ShortDescription string
+	// Cleaned forms of Description and About as in GameRecord
+	DescriptionText     string
+	DescriptionMarkdown string
+	AboutText           string
+	AboutMarkdown       string
+}
+
+// PricePoint is one observation of a game price in a region.
+// Prices are in the smallest unit of the currency, e.g. cents.
+type PricePoint struct {
+	Time     time.Time
+	Currency string
+	Initial  int
+	Final    int
+	Discount int
+}
+
+// ReviewSet is the collected user reviews of a game, latest updated first
+type ReviewSet struct {
+	Summary ReviewSummary
+	Reviews []Review
+}
+
+// ReviewSummary is the overall review score of a game
+type ReviewSummary struct {
+	Score         int
+	Description   string
+	TotalPositive int
+	TotalNegative int
+	TotalReviews  int
+	Updated       time.Time
+}
+
+// Review is one user review, playtimes are in minutes
+type Review struct {
+	ID               string
+	Author           string
+	Language         string
+	Text             string
+	VotedUp          bool
+	VotesUp          int
+	VotesFunny       int
+	WeightedScore    float64
+	PlaytimeAtReview int
+	PlaytimeForever  int
+	Created          time.Time
+	Updated          time.Time
+}
+
+// AchievementSet is the achievements of a game with global unlock percentages
+type AchievementSet struct {
+	Updated      time.Time
+	Achievements []Achievement
+}
+
+// Achievement of a game, Percent is share of players unlocked it
+type Achievement struct {
+	Name        string
+	DisplayName string
+	Description string
+	Hidden      bool
+	Icon        string
+	IconGray    string
+	Percent     float64
+}
+
+// NewsSet is the collected news and patch notes of a game, newest first
+type NewsSet struct {
+	Updated time.Time
+	Items   []NewsItem
+}
+
+// NewsItem is one news of a game, Contents is cleaned into plain text
+type NewsItem struct {
+	ID        string
+	Title     string
+	URL       string
+	Author    string
+	Feed      string
+	FeedLabel string
+	Date      time.Time
+	Contents  string
+}
+
+// Library is the games owned by a user
+type Library struct {
+	UserID  string
+	Updated time.Time
+	Games   []LibraryEntry
+}
+
+// LibraryEntry is an owned game, playtimes are in minutes and recent one
+// covers last two weeks. Linked tells if game record was in store at import.
+type LibraryEntry struct {
+	ID              int
+	Name            string
+	PlaytimeForever int
+	PlaytimeRecent  int
+	LastPlayed      time.Time
+	Linked          bool
+}
+
+// Wishlist of a user, removed items are kept with Removed set.
+// Changes are the noticed changes of items at last sync.
+type Wishlist struct {
+	UserID  string
+	Synced  time.Time
+	Items   []WishlistItem
+	Changes []WishlistChange
+}
+
+// WishlistItem is a wished game with its state at last sync
+type WishlistItem struct {
+	ID          int
+	Name        string
+	Priority    int
+	Added       time.Time
+	Removed     time.Time
+	Discount    int
+	EarlyAccess bool
+}
+
+// WishlistChange kinds
+const (
+	WishlistAdded           = "added"
+	WishlistRemoved         = "removed"
+	WishlistDiscounted      = "discounted"
+	WishlistLeftEarlyAccess = "left early access"
+)
+
+// WishlistChange is a change of a wished game noticed at sync
+type WishlistChange struct {
+	ID   int
+	Name string
+	Kind string
+}
+
+// AppSeen is when an app was first and last seen in platform app list,
+// Delisted is set once it is gone from the list. Unavailable apps are
+// delisted as store stopped serving their detail, they are restored once
+// it is served again rather than when listed.
+type AppSeen struct {
+	FirstSeen   time.Time
+	LastSeen    time.Time
+	Delisted    bool
+	Unavailable bool
+}
+
+// Link overrides
+const (
+	LinkConfirmed = "confirmed"
+	LinkRejected  = "rejected"
+)
+
+// Link is a match of two records of the same game on different platforms,
+// records are referred as platform:id. Override is set if a user decided it.
+type Link struct {
+	A        string
+	B        string
+	Score    float64
+	Override string
+}
+
+// Posting is the weight of a feature term in the vector of a record
+type Posting struct {
+	ID     int
+	Weight float64
+}
+
+// Cluster is a group of similar games found by clustering analysis,
+// labeled by terms telling it apart from other games
+type Cluster struct {
+	ID    int
+	Label string
+	Terms []string
+	Size  int
+}
+
+// GenreModel is a naive Bayes genre classifier, counting documents having
+// each word in all records and in records of each genre
+type GenreModel struct {
+	Trained time.Time
+	Docs    int
+	// Words counts documents having each word, Total is the sum of Words
+	Words  map[string]int
+	Total  int
+	Genres map[string]GenreCounts
+}
+
+// GenreCounts is the word counts of documents of a genre
+type GenreCounts struct {
+	Docs  int
+	Words map[string]int
+	Total int
+}
+
+// LinkKey is the order independent key of a pair of records
+func LinkKey(a, b string) string {
+	if b < a {
+		a, b = b, a
+	}
+	return a + "|" + b
 }
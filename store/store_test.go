@@ -0,0 +1,58 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSupportsLanguage(t *testing.T) {
+	r := GameRecord{
+		LanguageSupport: []LanguageSupport{
+			{Code: "en", Name: "English", Interface: true, FullAudio: true, Subtitles: true},
+			{Code: "ja", Name: "Japanese", Interface: true, Subtitles: true},
+			{Code: "zh-CN", Name: "Simplified Chinese", Interface: true, FullAudio: true, Subtitles: true},
+		},
+	}
+	var tests = []struct {
+		code    string
+		feature string
+		res     bool
+	}{
+		{"en", "audio", true},
+		{"ja", "audio", false},
+		{"ja", "subtitles", true},
+		{"zh", "audio", true},
+		{"zh-TW", "interface", false},
+		{"z", "interface", false},
+		{"de", "interface", false},
+		{"en", "unknown", false},
+	}
+
+	for caseid, c := range tests {
+		if res := r.SupportsLanguage(c.code, c.feature); res != c.res {
+			t.Errorf("case #%d, got: %v, expected: %v", caseid+1, res, c.res)
+		}
+	}
+}
+
+func TestReleaseDate(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	var tests = []struct {
+		d   ReleaseDate
+		end time.Time
+		str string
+	}{
+		{ReleaseDate{Date: date(2000, 11, 1), Precision: PrecisionDay}, date(2000, 11, 2), "1 Nov 2000"},
+		{ReleaseDate{Date: date(2000, 12, 1), Precision: PrecisionMonth}, date(2001, 1, 1), "Dec 2000"},
+		{ReleaseDate{Date: date(2024, 7, 1), Precision: PrecisionQuarter}, date(2024, 10, 1), "Q3 2024"},
+		{ReleaseDate{Date: date(2024, 1, 1), Precision: PrecisionYear}, date(2025, 1, 1), "2024"},
+		{ReleaseDate{Precision: PrecisionUnknown, Raw: "Coming soon"}, time.Time{}, "Coming soon"},
+		{ReleaseDate{}, time.Time{}, "unknown"},
+	}
+
+	for caseid, c := range tests {
+		if end, str := c.d.End(), c.d.String(); !end.Equal(c.end) || str != c.str {
+			t.Errorf("case #%d, got: %v %q, expected: %v %q", caseid+1, end, str, c.end, c.str)
+		}
+	}
+}
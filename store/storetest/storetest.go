@@ -0,0 +1,40 @@
+// Package storetest provides stores for tests of packages using store
+package storetest
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+)
+
+// Platforms are buckets of stores created by New
+var Platforms = []string{"steam", "gog"}
+
+// New creates a bolt store in a temporary directory of the test and saves
+// records to steam, the store is closed when the test finishes
+func New(t *testing.T, records ...store.GameRecord) store.GameStore {
+	t.Helper()
+	db, err := store.NewBoltStore(store.Config{
+		Database:  "bolt",
+		StorePath: filepath.Join(t.TempDir(), "test.db"),
+		Buckets:   Platforms,
+	})
+	if err != nil {
+		t.Fatalf("NewBoltStore err: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	Save(t, db, "steam", records...)
+	return db
+}
+
+// Save saves records of platform to db, keyed by their ids
+func Save(t *testing.T, db store.GameStore, platform string, records ...store.GameRecord) {
+	t.Helper()
+	for _, r := range records {
+		if err := db.SaveGameRecord(platform, strconv.Itoa(r.ID), r); err != nil {
+			t.Fatalf("SaveGameRecord err: %v", err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package studio
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// node ids of studios and games in exported graphs
+func studioNode(key string) string { return "s:" + key }
+func gameNode(id int) string       { return "g:" + strconv.Itoa(id) }
+
+func (g *Graph) sortedStudios() []*Studio {
+	ret := make([]*Studio, 0, len(g.Studios))
+	for _, s := range g.Studios {
+		ret = append(ret, s)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Key < ret[j].Key })
+	return ret
+}
+
+func (g *Graph) sortedGames() []*Game {
+	ret := make([]*Game, 0, len(g.Games))
+	for _, game := range g.Games {
+		ret = append(ret, game)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
+	return ret
+}
+
+// edge is a link of an exported graph, studios link to their partners by
+// games together, or with games to games they developed or published
+type edge struct {
+	source, target, role string
+	weight               int
+}
+
+func (g *Graph) exportEdges(games bool) []edge {
+	var ret []edge
+	if games {
+		for _, game := range g.sortedGames() {
+			for _, d := range game.Developers {
+				ret = append(ret, edge{studioNode(d), gameNode(game.ID), "developer", 1})
+			}
+			for _, p := range game.Publishers {
+				ret = append(ret, edge{studioNode(p), gameNode(game.ID), "publisher", 1})
+			}
+		}
+		return ret
+	}
+	for _, s := range g.sortedStudios() {
+		// edges are keyed both ways, each is exported from the developer side
+		for _, p := range g.Publishers(s) {
+			ret = append(ret, edge{studioNode(s.Key), studioNode(p.Studio.Key), "publisher", p.Games})
+		}
+	}
+	return ret
+}
+
+// WriteDOT writes the graph in Graphviz DOT. Studios are linked to their
+// publishers by games together, or to their games if games is set.
+func (g *Graph) WriteDOT(w io.Writer, games bool) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph studios {")
+	for _, s := range g.sortedStudios() {
+		fmt.Fprintf(bw, "\t%s [label=%s, shape=box, developed=%d, published=%d];\n",
+			dotQuote(studioNode(s.Key)), dotQuote(s.Name), len(s.Developed), len(s.Published))
+	}
+	if games {
+		for _, game := range g.sortedGames() {
+			fmt.Fprintf(bw, "\t%s [label=%s, shape=ellipse];\n", dotQuote(gameNode(game.ID)), dotQuote(game.Name))
+		}
+	}
+	for _, e := range g.exportEdges(games) {
+		fmt.Fprintf(bw, "\t%s -> %s [label=%s, weight=%d];\n", dotQuote(e.source), dotQuote(e.target), dotQuote(e.role), e.weight)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// dotQuote quotes a DOT id, escaping quotes, backslashes and newlines
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+// WriteGraphML writes the graph in GraphML, linked as in WriteDOT
+func (g *Graph) WriteGraphML(w io.Writer, games bool) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, xml.Header+`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprintln(bw, `  <key id="label" for="node" attr.name="label" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <key id="kind" for="node" attr.name="kind" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <key id="developed" for="node" attr.name="developed" attr.type="int"/>`)
+	fmt.Fprintln(bw, `  <key id="published" for="node" attr.name="published" attr.type="int"/>`)
+	fmt.Fprintln(bw, `  <key id="role" for="edge" attr.name="role" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <key id="weight" for="edge" attr.name="weight" attr.type="int"/>`)
+	fmt.Fprintln(bw, `  <graph id="studios" edgedefault="directed">`)
+	for _, s := range g.sortedStudios() {
+		fmt.Fprintf(bw, "    <node id=\"%s\">\n", xmlEscape(studioNode(s.Key)))
+		fmt.Fprintf(bw, "      <data key=\"label\">%s</data>\n", xmlEscape(s.Name))
+		fmt.Fprintln(bw, `      <data key="kind">studio</data>`)
+		fmt.Fprintf(bw, "      <data key=\"developed\">%d</data>\n", len(s.Developed))
+		fmt.Fprintf(bw, "      <data key=\"published\">%d</data>\n", len(s.Published))
+		fmt.Fprintln(bw, "    </node>")
+	}
+	if games {
+		for _, game := range g.sortedGames() {
+			fmt.Fprintf(bw, "    <node id=\"%s\">\n", xmlEscape(gameNode(game.ID)))
+			fmt.Fprintf(bw, "      <data key=\"label\">%s</data>\n", xmlEscape(game.Name))
+			fmt.Fprintln(bw, `      <data key="kind">game</data>`)
+			fmt.Fprintln(bw, "    </node>")
+		}
+	}
+	for _, e := range g.exportEdges(games) {
+		fmt.Fprintf(bw, "    <edge source=\"%s\" target=\"%s\">\n", xmlEscape(e.source), xmlEscape(e.target))
+		fmt.Fprintf(bw, "      <data key=\"role\">%s</data>\n", e.role)
+		fmt.Fprintf(bw, "      <data key=\"weight\">%d</data>\n", e.weight)
+		fmt.Fprintln(bw, "    </edge>")
+	}
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</graphml>")
+	return bw.Flush()
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
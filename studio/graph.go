@@ -0,0 +1,255 @@
+package studio
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ksang/gamecha/store"
+)
+
+const (
+	// damping of PageRank, the chance a walk follows an edge
+	damping = 0.85
+	// rankIterations caps PageRank power iterations
+	rankIterations = 100
+	// rankTolerance stops PageRank once ranks change less in total
+	rankTolerance = 1e-9
+)
+
+// Studio is a node of the graph, a developer, a publisher or both
+type Studio struct {
+	Key  string
+	Name string
+	// Developed and Published are ids of games, ascending
+	Developed []int
+	Published []int
+	// names counts spellings of the studio, the most used one is its Name
+	names map[string]int
+}
+
+// Game is a node of the graph with keys of its studios
+type Game struct {
+	ID         int
+	Name       string
+	Developers []string
+	Publishers []string
+}
+
+// Partner is a studio worked with and the number of games together
+type Partner struct {
+	Studio *Studio
+	Games  int
+}
+
+// Centrality of a studio in the developer and publisher graph. Degree is the
+// number of partners, PageRank weighs partners by their own centrality.
+type Centrality struct {
+	Studio   *Studio
+	Degree   int
+	Games    int
+	PageRank float64
+}
+
+// Graph links developers and publishers through games they made together
+type Graph struct {
+	Studios map[string]*Studio
+	Games   map[int]*Game
+	// edges counts games of developer and publisher pairs, keyed both ways
+	edges map[string]map[string]int
+}
+
+// Build creates the graph of records of a platform kept by keep, all records
+// if keep is nil. A studio both developing and publishing a game does not
+// link to itself.
+func Build(db store.GameStore, platform string, n *Normalizer, keep func(r store.GameRecord) bool) (*Graph, error) {
+	g := &Graph{
+		Studios: make(map[string]*Studio),
+		Games:   make(map[int]*Game),
+		edges:   make(map[string]map[string]int),
+	}
+	if err := db.ForEachGameRecord(platform, func(subid string, r store.GameRecord) error {
+		if keep != nil && !keep(r) {
+			return nil
+		}
+		game := &Game{ID: r.ID, Name: r.Name}
+		game.Developers = g.add(n, r.Developers, r.ID, true)
+		game.Publishers = g.add(n, r.Publishers, r.ID, false)
+		if len(game.Developers)+len(game.Publishers) == 0 {
+			return nil
+		}
+		g.Games[r.ID] = game
+		for _, d := range game.Developers {
+			for _, p := range game.Publishers {
+				if d != p {
+					g.link(d, p)
+					g.link(p, d)
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for _, s := range g.Studios {
+		sort.Ints(s.Developed)
+		sort.Ints(s.Published)
+		best := 0
+		for name, c := range s.names {
+			if c > best || (c == best && name < s.Name) {
+				s.Name, best = name, c
+			}
+		}
+	}
+	return g, nil
+}
+
+// add studios of names to the graph with a game they developed or published,
+// and returns their unique keys
+func (g *Graph) add(n *Normalizer, names []string, id int, developed bool) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		k := n.Key(name)
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		keys = append(keys, k)
+		s, ok := g.Studios[k]
+		if !ok {
+			s = &Studio{Key: k, names: make(map[string]int)}
+			g.Studios[k] = s
+		}
+		s.names[strings.TrimSpace(name)]++
+		if developed {
+			s.Developed = append(s.Developed, id)
+		} else {
+			s.Published = append(s.Published, id)
+		}
+	}
+	return keys
+}
+
+func (g *Graph) link(a, b string) {
+	if g.edges[a] == nil {
+		g.edges[a] = make(map[string]int)
+	}
+	g.edges[a][b]++
+}
+
+// Find a studio by any spelling of its name, nil if not in graph
+func (g *Graph) Find(n *Normalizer, name string) *Studio {
+	return g.Studios[n.Key(name)]
+}
+
+// Publishers that published games of a developer, by games together descending
+func (g *Graph) Publishers(developer *Studio) []Partner {
+	return g.partners(developer, func(s *Studio) []int { return s.Published }, developer.Developed)
+}
+
+// Developers whose games a publisher published, by games together descending
+func (g *Graph) Developers(publisher *Studio) []Partner {
+	return g.partners(publisher, func(s *Studio) []int { return s.Developed }, publisher.Published)
+}
+
+// partners counts games of s shared with studios in role
+func (g *Graph) partners(s *Studio, role func(*Studio) []int, games []int) []Partner {
+	mine := make(map[int]bool, len(games))
+	for _, id := range games {
+		mine[id] = true
+	}
+	var ret []Partner
+	for k := range g.edges[s.Key] {
+		other := g.Studios[k]
+		n := 0
+		for _, id := range role(other) {
+			if mine[id] {
+				n++
+			}
+		}
+		if n > 0 {
+			ret = append(ret, Partner{Studio: other, Games: n})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Games != ret[j].Games {
+			return ret[i].Games > ret[j].Games
+		}
+		return ret[i].Studio.Key < ret[j].Studio.Key
+	})
+	return ret
+}
+
+// Central ranks studios by PageRank over developer and publisher links
+// weighted by games together, n most central are returned, all if n is
+// negative
+func (g *Graph) Central(n int) []Centrality {
+	keys := make([]string, 0, len(g.Studios))
+	for k := range g.Studios {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	rank := make(map[string]float64, len(keys))
+	for _, k := range keys {
+		rank[k] = 1 / float64(len(keys))
+	}
+	weight := make(map[string]int, len(keys))
+	for _, k := range keys {
+		for _, w := range g.edges[k] {
+			weight[k] += w
+		}
+	}
+	for it := 0; it < rankIterations; it++ {
+		next := make(map[string]float64, len(keys))
+		// rank of studios without links is spread over all studios
+		dangling := 0.0
+		for _, k := range keys {
+			if weight[k] == 0 {
+				dangling += rank[k]
+			}
+		}
+		for _, k := range keys {
+			next[k] = (1-damping)/float64(len(keys)) + damping*dangling/float64(len(keys))
+		}
+		for _, k := range keys {
+			for other, w := range g.edges[k] {
+				next[other] += damping * rank[k] * float64(w) / float64(weight[k])
+			}
+		}
+		diff := 0.0
+		for _, k := range keys {
+			diff += math.Abs(next[k] - rank[k])
+		}
+		rank = next
+		if diff < rankTolerance {
+			break
+		}
+	}
+	ret := make([]Centrality, 0, len(keys))
+	for _, k := range keys {
+		s := g.Studios[k]
+		ret = append(ret, Centrality{
+			Studio:   s,
+			Degree:   len(g.edges[k]),
+			Games:    len(union(s.Developed, s.Published)),
+			PageRank: rank[k],
+		})
+	}
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].PageRank > ret[j].PageRank })
+	if n >= 0 && len(ret) > n {
+		ret = ret[:n]
+	}
+	return ret
+}
+
+func union(a, b []int) map[int]bool {
+	ret := make(map[int]bool, len(a)+len(b))
+	for _, id := range a {
+		ret[id] = true
+	}
+	for _, id := range b {
+		ret[id] = true
+	}
+	return ret
+}
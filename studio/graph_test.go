@@ -0,0 +1,140 @@
+package studio
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ksang/gamecha/store"
+	"github.com/ksang/gamecha/store/storetest"
+)
+
+func testGraph(t *testing.T) *Graph {
+	records := []store.GameRecord{
+		{ID: 1, Name: "Witcher", Type: "game", Developers: []string{"CD PROJEKT RED"}, Publishers: []string{"CD PROJEKT RED"}},
+		{ID: 2, Name: "Witcher 2", Type: "game", Developers: []string{"CD PROJEKT RED"}, Publishers: []string{"CD PROJEKT RED", "Namco Bandai Partners"}},
+		{ID: 3, Name: "Dark Souls", Type: "game", Developers: []string{"FromSoftware, Inc."}, Publishers: []string{"BANDAI NAMCO Entertainment"}},
+		{ID: 4, Name: "Dark Souls III", Type: "game", Developers: []string{"FromSoftware"}, Publishers: []string{"FromSoftware", "BANDAI NAMCO Entertainment"}},
+		{ID: 5, Name: "Tekken 7", Type: "game", Developers: []string{"BANDAI NAMCO Studios Inc."}, Publishers: []string{"BANDAI NAMCO Entertainment"}},
+		{ID: 6, Name: "Tekken 7 - Season Pass", Type: "dlc", Developers: []string{"BANDAI NAMCO Studios Inc."}, Publishers: []string{"BANDAI NAMCO Entertainment"}},
+		{ID: 7, Name: "Mac Port", Type: "game", Developers: []string{"Feral Interactive (Mac)"}, Publishers: []string{"Feral Interactive"}},
+		{ID: 8, Name: "Unknown", Type: "game"},
+	}
+	db := storetest.New(t, records...)
+	n := NewNormalizer(Config{Aliases: []Alias{
+		{Name: "Bandai Namco Entertainment", AKA: []string{"Namco Bandai Partners"}},
+	}})
+	g, err := Build(db, "steam", n, func(r store.GameRecord) bool { return r.Type == "game" })
+	if err != nil {
+		t.Fatalf("Build err: %v", err)
+	}
+	return g
+}
+
+func TestBuild(t *testing.T) {
+	g := testGraph(t)
+	if len(g.Games) != 6 {
+		t.Errorf("got %d games, expected: 6", len(g.Games))
+	}
+	n := NewNormalizer(Config{})
+	var tests = []struct {
+		name       string
+		key        string
+		label      string
+		publishers string
+		developers string
+	}{
+		{"FromSoftware", "fromsoftware", "FromSoftware", "bandai namco entertainment:2", ""},
+		{"bandai namco entertainment", "bandai namco entertainment", "BANDAI NAMCO Entertainment", "",
+			"fromsoftware:2,bandai namco studios:1,cd projekt red:1"},
+		{"CD Projekt Red", "cd projekt red", "CD PROJEKT RED", "bandai namco entertainment:1", ""},
+		{"Feral Interactive", "feral interactive", "Feral Interactive", "", ""},
+	}
+	for caseid, c := range tests {
+		s := g.Find(n, c.name)
+		if s == nil {
+			t.Errorf("case #%d, %q not found", caseid+1, c.name)
+			continue
+		}
+		if s.Key != c.key || s.Name != c.label {
+			t.Errorf("case #%d, got: %q %q, expected: %q %q", caseid+1, s.Key, s.Name, c.key, c.label)
+		}
+		if p := partnerString(g.Publishers(s)); p != c.publishers {
+			t.Errorf("case #%d, got publishers: %q, expected: %q", caseid+1, p, c.publishers)
+		}
+		if d := partnerString(g.Developers(s)); d != c.developers {
+			t.Errorf("case #%d, got developers: %q, expected: %q", caseid+1, d, c.developers)
+		}
+	}
+}
+
+func partnerString(ps []Partner) string {
+	var ret []string
+	for _, p := range ps {
+		ret = append(ret, p.Studio.Key+":"+strconv.Itoa(p.Games))
+	}
+	return strings.Join(ret, ",")
+}
+
+func TestCentral(t *testing.T) {
+	g := testGraph(t)
+	c := g.Central(2)
+	if len(c) != 2 {
+		t.Fatalf("got %d studios, expected: 2", len(c))
+	}
+	if c[0].Studio.Key != "bandai namco entertainment" || c[0].Degree != 3 || c[0].Games != 4 {
+		t.Errorf("got: %s %d %d, expected: bandai namco entertainment 3 4", c[0].Studio.Key, c[0].Degree, c[0].Games)
+	}
+	if c := g.Central(-1); len(c) != len(g.Studios) {
+		t.Errorf("got %d studios, expected: %d", len(c), len(g.Studios))
+	}
+	total := 0.0
+	for _, s := range g.Central(len(g.Studios)) {
+		total += s.PageRank
+	}
+	if total < 0.999 || total > 1.001 {
+		t.Errorf("got PageRank total: %v, expected: 1", total)
+	}
+}
+
+func TestExport(t *testing.T) {
+	g := testGraph(t)
+	var tests = []struct {
+		games bool
+		nodes int
+		edges int
+	}{
+		{false, 5, 3},
+		{true, 11, 14},
+	}
+	for caseid, c := range tests {
+		var buf bytes.Buffer
+		if err := g.WriteGraphML(&buf, c.games); err != nil {
+			t.Fatalf("case #%d, WriteGraphML err: %v", caseid+1, err)
+		}
+		var doc struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"graph>node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+			} `xml:"graph>edge"`
+		}
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("case #%d, bad GraphML: %v", caseid+1, err)
+		}
+		if len(doc.Nodes) != c.nodes || len(doc.Edges) != c.edges {
+			t.Errorf("case #%d, got: %d nodes %d edges, expected: %d nodes %d edges",
+				caseid+1, len(doc.Nodes), len(doc.Edges), c.nodes, c.edges)
+		}
+		buf.Reset()
+		if err := g.WriteDOT(&buf, c.games); err != nil {
+			t.Fatalf("case #%d, WriteDOT err: %v", caseid+1, err)
+		}
+		if e := strings.Count(buf.String(), " -> "); e != c.edges {
+			t.Errorf("case #%d, got: %d DOT edges, expected: %d", caseid+1, e, c.edges)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package studio builds the graph of developers, publishers and their games,
+// with studio names normalized so spellings of a studio meet in one node
+package studio
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// legalForms are company forms left out of studio keys when trailing
+var legalForms = map[string]bool{
+	"inc": true, "incorporated": true, "ltd": true, "limited": true, "llc": true, "llp": true,
+	"co": true, "corp": true, "corporation": true, "plc": true, "pty": true, "gmbh": true,
+	"mbh": true, "ag": true, "sa": true, "sas": true, "sarl": true, "srl": true, "spa": true,
+	"ab": true, "as": true, "oy": true, "oyj": true, "bv": true, "nv": true, "kk": true,
+	"sro": true, "sp": true, "zoo": true, "ltda": true,
+}
+
+// portRe matches platform notes of port credits, e.g. Feral Interactive (Mac)
+var portRe = regexp.MustCompile(`(?i)\s*\((?:mac|linux|macos|os x|mac/linux|linux/mac)\)`)
+
+// Key normalizes a studio name: lowercased without punctuation, platform
+// notes and trailing legal forms, e.g. CD PROJEKT S.A. is cd projekt.
+// Dotted letters are joined, so S.A. and SA are the same form.
+func Key(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(portRe.ReplaceAllString(name, "")), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	var joined []string
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		for len([]rune(w)) == 1 && i+1 < len(words) && len([]rune(words[i+1])) == 1 {
+			i++
+			w += words[i]
+		}
+		joined = append(joined, w)
+	}
+	for len(joined) > 1 && legalForms[joined[len(joined)-1]] {
+		joined = joined[:len(joined)-1]
+	}
+	return strings.Join(joined, " ")
+}
+
+// Alias names a studio known by other names, e.g. a renamed studio
+type Alias struct {
+	Name string
+	AKA  []string
+}
+
+// Config is the configuration struct of studio graph
+type Config struct {
+	Aliases []Alias
+}
+
+// Normalizer keys studio names with aliases applied
+type Normalizer struct {
+	aliases map[string]string
+}
+
+// NewNormalizer creates a normalizer of aliases
+func NewNormalizer(cfg Config) *Normalizer {
+	n := &Normalizer{aliases: make(map[string]string)}
+	for _, a := range cfg.Aliases {
+		for _, aka := range a.AKA {
+			n.aliases[Key(aka)] = Key(a.Name)
+		}
+	}
+	return n
+}
+
+// Key of a studio name, an alias is keyed as the studio it names
+func (n *Normalizer) Key(name string) string {
+	k := Key(name)
+	if a, ok := n.aliases[k]; ok {
+		return a
+	}
+	return k
+}
@@ -0,0 +1,48 @@
+package studio
+
+import "testing"
+
+func TestKey(t *testing.T) {
+	var tests = []struct {
+		name string
+		key  string
+	}{
+		{"Valve", "valve"},
+		{"Valve Corporation", "valve"},
+		{"CD PROJEKT RED", "cd projekt red"},
+		{"CD PROJEKT S.A.", "cd projekt"},
+		{"Feral Interactive (Mac)", "feral interactive"},
+		{"Feral Interactive (Linux)", "feral interactive"},
+		{"Ubisoft Entertainment SA", "ubisoft entertainment"},
+		{"THQ Nordic GmbH", "thq nordic"},
+		{"Square Enix Co., Ltd.", "square enix"},
+		{"Corp", "corp"},
+		{"11 bit studios", "11 bit studios"},
+		{"  ", ""},
+	}
+	for caseid, c := range tests {
+		if k := Key(c.name); k != c.key {
+			t.Errorf("case #%d, got: %q, expected: %q", caseid+1, k, c.key)
+		}
+	}
+}
+
+func TestNormalizer(t *testing.T) {
+	n := NewNormalizer(Config{Aliases: []Alias{
+		{Name: "Bandai Namco Entertainment", AKA: []string{"NAMCO BANDAI Games", "Namco Bandai Partners"}},
+	}})
+	var tests = []struct {
+		name string
+		key  string
+	}{
+		{"BANDAI NAMCO Entertainment Inc.", "bandai namco entertainment"},
+		{"NAMCO BANDAI Games Inc.", "bandai namco entertainment"},
+		{"Namco Bandai Partners", "bandai namco entertainment"},
+		{"Namco", "namco"},
+	}
+	for caseid, c := range tests {
+		if k := n.Key(c.name); k != c.key {
+			t.Errorf("case #%d, got: %q, expected: %q", caseid+1, k, c.key)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package title normalizes game names into comparable keys, separating
+// edition qualifiers such as GOTY or Definitive Edition from the base title
+package title
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Title is a normalized game name
+type Title struct {
+	// Key is the comparable base title, lowercase words of letters and digits
+	// with roman numerals as arabic ones, leading article and editions removed
+	Key string
+	// Editions are qualifiers of the name in canonical form, e.g. goty, definitive,
+	// years in parentheses are kept as qualifiers too
+	Editions []string
+}
+
+// replacer drops trademark signs and unifies quotes, dashes and accented letters
+var replacer = strings.NewReplacer(
+	"™", "", "®", "", "©", "", "℠", "",
+	"’", "'", "‘", "'", "`", "'", "´", "'",
+	"–", "-", "—", "-", "‐", "-",
+	"&", " and ",
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "æ", "ae",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "œ", "oe",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y", "ß", "ss",
+)
+
+var (
+	bracketRe = regexp.MustCompile(`[(\[]([^)\]]*)[)\]]`)
+	acronymRe = regexp.MustCompile(`\b(?:[a-z]\.){2,}`)
+	groupRe   = regexp.MustCompile(`(\d),(\d{3})\b`)
+	yearRe    = regexp.MustCompile(`^(19|20)\d\d$`)
+	ordinalRe = regexp.MustCompile(`^\d+(st|nd|rd|th)$`)
+)
+
+// editions maps word sequences of edition qualifiers to their canonical form
+var editions = []struct {
+	words []string
+	name  string
+}{
+	{[]string{"game", "of", "the", "year"}, "goty"},
+	{[]string{"goty"}, "goty"},
+	{[]string{"directors", "cut"}, "directors cut"},
+	{[]string{"final", "cut"}, "final cut"},
+	{[]string{"digital", "deluxe"}, "deluxe"},
+	{[]string{"super", "deluxe"}, "deluxe"},
+	{[]string{"ultra", "deluxe"}, "deluxe"},
+	{[]string{"deluxe"}, "deluxe"},
+	{[]string{"definitive"}, "definitive"},
+	{[]string{"complete"}, "complete"},
+	{[]string{"ultimate"}, "ultimate"},
+	{[]string{"gold"}, "gold"},
+	{[]string{"platinum"}, "platinum"},
+	{[]string{"enhanced"}, "enhanced"},
+	{[]string{"remastered"}, "remastered"},
+	{[]string{"remaster"}, "remastered"},
+	{[]string{"anniversary"}, "anniversary"},
+	{[]string{"collectors"}, "collectors"},
+	{[]string{"special"}, "special"},
+	{[]string{"premium"}, "premium"},
+	{[]string{"legendary"}, "legendary"},
+	{[]string{"standard"}, "standard"},
+	{[]string{"limited"}, "limited"},
+	{[]string{"extended"}, "extended"},
+	{[]string{"royal"}, "royal"},
+	{[]string{"redux"}, "redux"},
+	{[]string{"hd"}, "hd"},
+}
+
+// numbered words are followed by a number, single i after them is one
+var numbered = map[string]bool{
+	"part": true, "episode": true, "chapter": true, "act": true, "book": true, "vol": true, "volume": true,
+}
+
+// editionSuffixes may follow an edition qualifier
+var editionSuffixes = map[string]bool{"edition": true, "version": true}
+
+// Normalize a game name
+func Normalize(name string) Title {
+	var t Title
+	name = replacer.Replace(strings.ToLower(name))
+	// s.t.a.l.k.e.r. and 40,000 are one word
+	name = acronymRe.ReplaceAllStringFunc(name, func(s string) string {
+		return strings.Replace(s, ".", "", -1) + " "
+	})
+	name = groupRe.ReplaceAllString(name, "$1$2")
+	// bracketed qualifiers are taken out, other bracketed words are kept
+	name = bracketRe.ReplaceAllStringFunc(name, func(s string) string {
+		inner := words(s)
+		if len(inner) == 1 && yearRe.MatchString(inner[0]) {
+			t.Editions = append(t.Editions, inner[0])
+			return " "
+		}
+		if rest, eds := trimEditions(inner); len(rest) == 0 && len(eds) > 0 {
+			t.Editions = append(t.Editions, eds...)
+			return " "
+		}
+		return " " + strings.Join(inner, " ") + " "
+	})
+	ws, eds := trimEditions(words(name))
+	t.Editions = append(t.Editions, eds...)
+	for i, w := range ws {
+		if n, ok := roman(w); ok && (len(w) > 1 || i > 0) {
+			ws[i] = strconv.Itoa(n)
+		} else if w == "i" && i > 0 && numbered[ws[i-1]] {
+			ws[i] = "1"
+		}
+	}
+	if len(ws) > 1 && ws[0] == "the" {
+		ws = ws[1:]
+	}
+	t.Key = strings.Join(ws, " ")
+	return t
+}
+
+// Key is the comparable base title of a game name
+func Key(name string) string {
+	return Normalize(name).Key
+}
+
+// words splits lowercase text into words of letters and digits,
+// apostrophes are dropped so possessives stay one word
+func words(s string) []string {
+	s = strings.Replace(s, "'", "", -1)
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// trimEditions removes edition qualifiers from the end of words, they are
+// returned in order of appearance. At least one word of title is kept.
+func trimEditions(ws []string) ([]string, []string) {
+	var eds []string
+	for {
+		n := len(ws)
+		if n > 0 && editionSuffixes[ws[n-1]] {
+			n--
+		}
+		name, l := "", 0
+		for _, e := range editions {
+			if len(e.words) <= n && equal(ws[n-len(e.words):n], e.words) {
+				name, l = e.name, len(e.words)
+				break
+			}
+		}
+		if l == 0 {
+			return ws, eds
+		}
+		start := n - l
+		// e.g. 20th anniversary
+		if start > 0 && name == "anniversary" && ordinalRe.MatchString(ws[start-1]) {
+			start--
+		}
+		// the whole name is qualifier words, e.g. Gold
+		if start == 0 {
+			return ws, eds
+		}
+		// e.g. the final cut
+		if start > 1 && ws[start-1] == "the" {
+			start--
+		}
+		eds = append([]string{name}, eds...)
+		ws = ws[:start]
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+var romanValues = map[rune]int{'i': 1, 'v': 5, 'x': 10}
+
+// roman parses a roman numeral word from 2 to 39, single i is left
+// to the caller as it is more often a word than a number
+func roman(w string) (int, bool) {
+	if w == "i" || len(w) > 6 {
+		return 0, false
+	}
+	total, prev := 0, 0
+	for i := len(w) - 1; i >= 0; i-- {
+		v, ok := romanValues[rune(w[i])]
+		if !ok {
+			return 0, false
+		}
+		if v < prev {
+			total -= v
+		} else {
+			total += v
+			prev = v
+		}
+	}
+	if total < 2 || total > 39 || toRoman(total) != w {
+		return 0, false
+	}
+	return total, true
+}
+
+func toRoman(n int) string {
+	var b strings.Builder
+	for _, p := range []struct {
+		v int
+		s string
+	}{{10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"}} {
+		for n >= p.v {
+			b.WriteString(p.s)
+			n -= p.v
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package title
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeFixtures(t *testing.T) {
+	f, err := os.Open("testdata/titles.txt")
+	if err != nil {
+		t.Fatalf("open fixtures err: %v", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	line, n := 0, 0
+	for sc.Scan() {
+		line++
+		if sc.Text() == "" || strings.HasPrefix(sc.Text(), "#") {
+			continue
+		}
+		parts := strings.Split(sc.Text(), " | ")
+		if len(parts) != 3 {
+			parts = strings.Split(strings.TrimSuffix(sc.Text(), " |"), " | ")
+			parts = append(parts, "")
+		}
+		if len(parts) != 3 {
+			t.Fatalf("line %d, bad fixture: %s", line, sc.Text())
+		}
+		var editions []string
+		if parts[2] != "" {
+			editions = strings.Split(parts[2], ",")
+		}
+		res := Normalize(parts[0])
+		if res.Key != parts[1] || !reflect.DeepEqual(res.Editions, editions) {
+			t.Errorf("line %d, %q got: %q %v, expected: %q %v", line, parts[0], res.Key, res.Editions, parts[1], editions)
+		}
+		n++
+	}
+	if n < 400 {
+		t.Errorf("got %d fixtures, expected at least 400", n)
+	}
+}
+
+func TestKeyEquivalence(t *testing.T) {
+	var tests = [][]string{
+		{"The Witcher® 3: Wild Hunt", "The Witcher 3 - Wild Hunt - Game of the Year Edition", "THE WITCHER 3: WILD HUNT GOTY"},
+		{"Assassin's Creed® II", "Assassin’s Creed 2", "assassins creed ii deluxe edition"},
+		{"Heroes of Might & Magic III", "Heroes of Might and Magic 3 - HD Edition"},
+		{"S.T.A.L.K.E.R.: Shadow of Chernobyl", "STALKER: Shadow of Chernobyl"},
+		{"Warhammer 40,000: Dawn of War", "Warhammer 40000 - Dawn of War (2004)"},
+		{"Pokémon Legends: Arceus", "Pokemon Legends Arceus"},
+	}
+	for caseid, c := range tests {
+		key := Key(c[0])
+		for _, name := range c[1:] {
+			if k := Key(name); k != key {
+				t.Errorf("case #%d, %q got: %q, expected: %q", caseid+1, name, k, key)
+			}
+		}
+	}
+}
+
+func TestRoman(t *testing.T) {
+	var tests = []struct {
+		s  string
+		n  int
+		ok bool
+	}{
+		{"ii", 2, true},
+		{"iv", 4, true},
+		{"xiv", 14, true},
+		{"xxxix", 39, true},
+		{"i", 0, false},
+		{"iiii", 0, false},
+		{"vx", 0, false},
+		{"civ", 0, false},
+		{"mix", 0, false},
+	}
+	for caseid, c := range tests {
+		n, ok := roman(c.s)
+		if n != c.n || ok != c.ok {
+			t.Errorf("case #%d, got: %d %v, expected: %d %v", caseid+1, n, ok, c.n, c.ok)
+		}
+	}
+}